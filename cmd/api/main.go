@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shopally-ai/internal/adapter/handler"
+	"github.com/shopally-ai/internal/adapter/http/router"
+	"github.com/shopally-ai/internal/adapter/repository"
 
 	"github.com/shopally-ai/internal/adapter/gateway"
 
 	"github.com/shopally-ai/internal/config"
 	"github.com/shopally-ai/internal/platform"
+	"github.com/shopally-ai/pkg/domain"
 	"github.com/shopally-ai/pkg/usecase"
 )
 
@@ -52,22 +56,167 @@ func main() {
 	}
 
 	// Initialize router
-	router := gin.Default()
+	engine := gin.Default()
 
 	// Construct mock gateways and use case for mocked search flow
 	ag := gateway.NewMockAlibabaGateway()
 	lg := gateway.NewMockLLMGateway()
-	uc := usecase.NewSearchProductsUseCase(ag, lg, nil)
+
+	// Cache port and FX client; without Redis the FX provider is called directly
+	var cache usecase.ICachePort
+	if rdb != nil {
+		cache = gateway.NewRedisCache(rdb.Client, cfg.Redis.KeyPrefix)
+	}
+	fxTTL := time.Duration(cfg.FX.CacheTTLSeconds) * time.Second
+	fx := gateway.NewCachedFXClient(gateway.NewFXHTTPGateway(cfg.FX.APIURL, cfg.FX.APIKEY, nil), cache, fxTTL)
+
+	// Views feed the worker's price tracking and need Redis
+	viewWindow := time.Duration(cfg.Redis.ViewTrackingTTL) * time.Second
+	var views usecase.ViewTracker
+	if rdb != nil {
+		views = gateway.NewRedisViewTracker(rdb.Client, cfg.Redis.KeyPrefix, viewWindow)
+	}
+
+	alertRepo := repository.NewMongoAlertRepository(db, cfg.Mongo.AlertCollection)
+	if err := alertRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("alert indexes: %v", err)
+	}
+	historyRepo := repository.NewMongoPriceHistoryRepository(db, cfg.Mongo.PriceHistoryCollection)
+	deals := usecase.NewDealAnalyzer(historyRepo, time.Duration(cfg.Deals.WindowDays)*24*time.Hour)
+
+	uc := usecase.NewSearchProductsUseCase(ag, lg, nil, deals)
+	productTTL := time.Duration(cfg.Product.CacheTTLSeconds) * time.Second
+	// The job queue needs Redis; domain events are bridged onto it when a
+	// topic prefix is set
+	var queue usecase.JobQueue
+	if rdb != nil {
+		queue = platform.NewStreamQueue(rdb, platform.NewQueueOptions(cfg.Queue.VisibilityTimeoutSeconds, cfg.Queue.MaxAttempts, cfg.Queue.BaseBackoffSeconds, cfg.Queue.MaxBackoffSeconds))
+	}
+	events := usecase.NewEventBus(func(_ usecase.Event, err error) { log.Printf("events: %v", err) })
+	if queue != nil && cfg.Events.TopicPrefix != "" {
+		events.SubscribeAsync(usecase.AllEvents, usecase.QueueBridge(queue, cfg.Events.TopicPrefix))
+	}
+
+	tracker := usecase.NewPriceTracker(ag, fx, historyRepo, alertRepo, views, viewWindow, events)
+	alerts := usecase.NewAlertManager(alertRepo, ag, fx, cfg.Alerts.MaxActivePerUser, events)
+	fxHistoryRepo := repository.NewMongoFXHistoryRepository(db, cfg.Mongo.FXHistoryCollection)
+	predictor := usecase.NewPricePredictor(historyRepo, fxHistoryRepo, fx, time.Duration(cfg.Prediction.LookbackDays)*24*time.Hour)
+	productUC := usecase.NewGetProductUseCase(ag, lg, fx, cache, views, deals, predictor, productTTL)
+
+	deviceRepo := repository.NewMongoDeviceRepository(db, cfg.Mongo.DeviceCollection)
+	if err := deviceRepo.EnsureIndexes(ctx); err != nil {
+		log.Printf("device indexes: %v", err)
+	}
+	devices := usecase.NewDeviceManager(deviceRepo, time.Duration(cfg.Devices.StaleAfterDays)*24*time.Hour)
+
+	prefsRepo := repository.NewMongoPreferencesRepository(db, cfg.Mongo.PreferencesCollection)
+
+	telegramLinks := repository.NewMongoTelegramLinkRepository(db, cfg.Mongo.TelegramCollection)
+	if err := telegramLinks.EnsureIndexes(ctx); err != nil {
+		log.Printf("telegram indexes: %v", err)
+	}
+	telegramAPI := gateway.NewTelegramBotGateway(cfg.Telegram.BotToken, nil)
+	if cfg.Telegram.APIURL != "" {
+		telegramAPI.BaseURL = cfg.Telegram.APIURL
+	}
+	bot := usecase.NewTelegramBot(telegramLinks, telegramAPI, uc, cfg.Telegram.BotUsername)
+
+	// SMS search needs a provider and Redis for sessions and short links
+	var smsHandler *handler.SMSHandler
+	var shortLinkHandler *handler.ShortLinkHandler
+	if rdb != nil && cfg.SMS.APIURL != "" {
+		var links usecase.LinkShortener
+		if cfg.SMS.ShortLinkBaseURL != "" {
+			shortener := gateway.NewRedisLinkShortener(rdb.Client, cfg.Redis.KeyPrefix, cfg.SMS.ShortLinkBaseURL, 0)
+			links = shortener
+			shortLinkHandler = handler.NewShortLinkHandler(shortener)
+		}
+		smsGateway := gateway.NewHTTPSMSGateway(cfg.SMS.APIURL, cfg.SMS.APIKey, cfg.SMS.SenderID, nil)
+		sessions := gateway.NewRedisSMSSessionStore(rdb.Client, cfg.Redis.KeyPrefix, 0)
+		assistant := usecase.NewSMSAssistant(uc, alerts, smsGateway, sessions, links)
+		smsHandler = handler.NewSMSHandler(assistant, cfg.SMS.WebhookSecret)
+	}
+
+	savedSearches := repository.NewMongoSavedSearchRepository(db, cfg.Mongo.SavedSearchCollection)
+	if err := savedSearches.EnsureIndexes(ctx); err != nil {
+		log.Printf("saved search indexes: %v", err)
+	}
+
+	// Webhook replays go through the job queue
+	var webhookHandler *handler.WebhookHandler
+	if queue != nil {
+		webhookDeliveries := repository.NewMongoWebhookDeliveryRepository(db, cfg.Mongo.WebhookDeliveryCollection)
+		webhookRepo := repository.NewMongoWebhookRepository(db, cfg.Mongo.WebhookCollection)
+		if err := webhookRepo.EnsureIndexes(ctx); err != nil {
+			log.Printf("webhook indexes: %v", err)
+		}
+		webhookHandler = handler.NewWebhookHandler(usecase.NewWebhookManager(webhookRepo, webhookDeliveries, queue))
+	}
+
+	cart := usecase.NewCartEstimator(ag, fx, customsRules(cfg))
 
 	// Initialize handlers
 	searchHandler := handler.NewSearchHandler(uc)
+	api := router.Build(router.Deps{
+		FX:           handler.NewFXHandler(fx),
+		Products:     handler.NewProductHandler(productUC),
+		PriceHistory: handler.NewPriceHistoryHandler(tracker),
+		Alerts:       handler.NewAlertHandler(alerts),
+		Devices:      handler.NewDeviceHandler(devices),
+		Preferences:  handler.NewPreferencesHandler(usecase.NewPreferencesManager(prefsRepo)),
+		Telegram:     handler.NewTelegramHandler(bot, cfg.Telegram.WebhookSecret),
+		SMS:          smsHandler,
+		ShortLinks:   shortLinkHandler,
+		Searches:     handler.NewSavedSearchHandler(usecase.NewSavedSearchManager(savedSearches, uc)),
+		Webhooks:     webhookHandler,
+		Watchlist:    handler.NewWatchlistHandler(usecase.NewWatchlistManager(repository.NewMongoWatchlistRepository(db, cfg.Mongo.WatchlistCollection), ag, fx, alerts)),
+		Cart:         handler.NewCartHandler(cart),
+		Resolve:      handler.NewResolveHandler(usecase.NewProductURLResolver(gateway.NewHTTPURLExpander(nil), productUC, cart)),
+	}, router.Options{Middlewares: []func(http.Handler) http.Handler{handler.Identify}})
 
 	// Register routes
-	searchHandler.RegisterRoutes(router)
+	searchHandler.RegisterRoutes(engine)
+	engine.Any("/fx", gin.WrapH(api))
+	engine.Any("/products/*path", gin.WrapH(api))
+	engine.Any("/alerts", gin.WrapH(api))
+	engine.Any("/alerts/*path", gin.WrapH(api))
+	engine.Any("/devices", gin.WrapH(api))
+	engine.Any("/devices/*path", gin.WrapH(api))
+	engine.Any("/me/*path", gin.WrapH(api))
+	engine.Any("/telegram/*path", gin.WrapH(api))
+	engine.Any("/sms/*path", gin.WrapH(api))
+	engine.Any("/s/*path", gin.WrapH(api))
+	engine.Any("/webhooks", gin.WrapH(api))
+	engine.Any("/cart/*path", gin.WrapH(api))
+	engine.Any("/webhooks/*path", gin.WrapH(api))
 
 	// Start the server
 	log.Println("Starting server on port", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
+	if err := engine.Run(":" + cfg.Server.Port); err != nil {
 		log.Fatalf("could not start server: %v", err)
 	}
 }
+
+// customsRules returns the default landed-cost rules with the configured overrides.
+func customsRules(cfg *config.Config) domain.CustomsRules {
+	rules := domain.DefaultCustomsRules()
+	c := cfg.Customs
+	for _, o := range []struct {
+		v   *float64
+		dst *float64
+	}{
+		{c.DutyFreeUSD, &rules.DutyFreeUSD},
+		{c.DutyRate, &rules.DutyRate},
+		{c.ExciseRate, &rules.ExciseRate},
+		{c.VATRate, &rules.VATRate},
+		{c.SurtaxRate, &rules.SurtaxRate},
+		{c.WithholdingRate, &rules.WithholdingRate},
+		{c.CommercialThresholdUSD, &rules.CommercialThresholdUSD},
+		{c.ClearanceFeeUSD, &rules.ClearanceFeeUSD},
+	} {
+		if o.v != nil {
+			*o.dst = *o.v
+		}
+	}
+	return rules
+}
@@ -2,12 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/shopally-ai/internal/adapter/gateway"
+	"github.com/shopally-ai/internal/adapter/handler"
+	"github.com/shopally-ai/internal/adapter/http/router"
+	"github.com/shopally-ai/internal/adapter/repository"
 	"github.com/shopally-ai/internal/config"
 	"github.com/shopally-ai/internal/platform"
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
 )
 
 func main() {
@@ -22,25 +32,333 @@ func main() {
 	}
 	cache := gateway.NewRedisCache(rc.Client, cfg.Redis.KeyPrefix)
 
+	mc, err := platform.Connect(cfg.Mongo.URI)
+	if err != nil {
+		log.Fatalf("mongo connect: %v", err)
+	}
+	defer func() {
+		if err := platform.Disconnect(mc); err != nil {
+			log.Printf("mongo disconnect: %v", err)
+		}
+	}()
+	db := mc.Database(cfg.Mongo.Database)
+
 	fxHTTP := gateway.NewFXHTTPGateway(cfg.FX.APIURL, cfg.FX.APIKEY, nil)
 	ttl := time.Duration(cfg.FX.CacheTTLSeconds) * time.Second
 	fx := gateway.NewCachedFXClient(fxHTTP, cache, ttl)
 
-	// Optional: pre-warm a common FX pair periodically
-	warm := func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if rate, err := fx.GetRate(ctx, "USD", "ETB"); err != nil {
-			log.Printf("worker warm fx error: %v", err)
-		} else {
-			log.Printf("worker warm fx USD->ETB: %.6f", rate)
+	ag := gateway.NewMockAlibabaGateway()
+	alertRepo := repository.NewMongoAlertRepository(db, cfg.Mongo.AlertCollection)
+	if err := alertRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("alert indexes: %v", err)
+	}
+	historyRepo := repository.NewMongoPriceHistoryRepository(db, cfg.Mongo.PriceHistoryCollection)
+	if err := historyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("price history indexes: %v", err)
+	}
+	fxHistoryRepo := repository.NewMongoFXHistoryRepository(db, cfg.Mongo.FXHistoryCollection)
+	if err := fxHistoryRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("fx history indexes: %v", err)
+	}
+	queue := platform.NewStreamQueue(rc, platform.NewQueueOptions(cfg.Queue.VisibilityTimeoutSeconds, cfg.Queue.MaxAttempts, cfg.Queue.BaseBackoffSeconds, cfg.Queue.MaxBackoffSeconds))
+	events := newEventBus(cfg, queue)
+	fxTracker := usecase.NewFXTracker(fx, fxHistoryRepo, events)
+	viewWindow := time.Duration(cfg.Redis.ViewTrackingTTL) * time.Second
+	views := gateway.NewRedisViewTracker(rc.Client, cfg.Redis.KeyPrefix, viewWindow)
+	tracker := usecase.NewPriceTracker(ag, fx, historyRepo, alertRepo, views, viewWindow, events)
+	evaluator := usecase.NewAlertEvaluator(alertRepo, ag, fx, cfg.Alerts.EvaluationConcurrency, events)
+
+	outbox := repository.NewMongoNotificationOutbox(db, cfg.Mongo.NotificationCollection)
+	if err := outbox.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("notification outbox indexes: %v", err)
+	}
+	deviceRepo := repository.NewMongoDeviceRepository(db, cfg.Mongo.DeviceCollection)
+	if err := deviceRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("device indexes: %v", err)
+	}
+	devices := usecase.NewDeviceManager(deviceRepo, time.Duration(cfg.Devices.StaleAfterDays)*24*time.Hour)
+
+	prefsRepo := repository.NewMongoPreferencesRepository(db, cfg.Mongo.PreferencesCollection)
+	channels := []usecase.NotificationChannel{
+		pushChannel(cfg, deviceRepo),
+		emailChannel(cfg, prefsRepo),
+		telegramChannel(cfg, repository.NewMongoTelegramLinkRepository(db, cfg.Mongo.TelegramCollection)),
+		smsChannel(cfg),
+	}
+	kinds := make([]domain.ChannelKind, 0, len(channels))
+	for _, c := range channels {
+		kinds = append(kinds, c.Kind())
+	}
+	notifier := usecase.NewNotificationService(outbox, kinds...)
+	dispatcher := usecase.NewNotificationDispatcher(outbox, prefsRepo, channels, cfg.Notifications.MaxAttempts,
+		time.Duration(cfg.Notifications.BaseBackoffSeconds)*time.Second)
+
+	digest := usecase.NewWeeklyDigest(alertRepo, historyRepo, fxHistoryRepo, ag, notifier)
+	search := usecase.NewSearchProductsUseCase(ag, gateway.NewMockLLMGateway(), nil, nil)
+	searchWatcher := usecase.NewSavedSearchWatcher(repository.NewMongoSavedSearchRepository(db, cfg.Mongo.SavedSearchCollection), search, fx, notifier)
+
+	// Triggered alerts of API clients become webhook deliveries on the queue
+	webhookDeliveries := repository.NewMongoWebhookDeliveryRepository(db, cfg.Mongo.WebhookDeliveryCollection)
+	if err := webhookDeliveries.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("webhook delivery indexes: %v", err)
+	}
+	webhooks := usecase.NewWebhookDispatcher(repository.NewMongoWebhookRepository(db, cfg.Mongo.WebhookCollection),
+		webhookDeliveries, gateway.NewHTTPWebhookSender(nil), queue, cfg.Queue.MaxAttempts)
+	events.Subscribe(usecase.EventAlertTriggered, webhooks.HandleEvent)
+
+	snapshotEvery := time.Duration(cfg.PriceHistory.SnapshotIntervalMinutes) * time.Minute
+	if snapshotEvery <= 0 {
+		snapshotEvery = time.Hour
+	}
+
+	jobs := []platform.Job{
+		{
+			// Pre-warm a common FX pair and record it as FX history
+			Name:       "fx_warmup",
+			Spec:       "@every 30m",
+			Timeout:    10 * time.Second,
+			RunOnStart: true,
+			Run: func(ctx context.Context) error {
+				rate, err := fxTracker.Record(ctx, "USD", "ETB")
+				if err == nil {
+					log.Printf("worker warm fx USD->ETB: %.6f", rate)
+				}
+				return err
+			},
+		},
+		{
+			// Record prices of alerted and recently viewed products
+			Name:       "price_snapshots",
+			Spec:       "@every " + snapshotEvery.String(),
+			Timeout:    5 * time.Minute,
+			RunOnStart: true,
+			Run: func(ctx context.Context) error {
+				changed, err := tracker.RecordSnapshots(ctx)
+				log.Printf("worker price snapshots: %d changed", changed)
+				return err
+			},
+		},
+		{
+			Name:    "alert_evaluation",
+			Spec:    "*/15 * * * *",
+			Timeout: 5 * time.Minute,
+			Jitter:  30 * time.Second,
+			Run: func(ctx context.Context) error {
+				triggers, stats, err := evaluator.EvaluateCycle(ctx)
+				log.Printf("worker alert evaluation: %d products fetched, %d alerts evaluated, %d triggered, %d expired, %d errors in %s",
+					stats.ProductsFetched, stats.AlertsEvaluated, stats.AlertsTriggered, stats.AlertsExpired, stats.Errors, stats.Duration.Round(time.Millisecond))
+				for _, t := range triggers {
+					created, nerr := notifier.NotifyAlertTriggered(ctx, t)
+					if nerr != nil {
+						err = errors.Join(err, nerr)
+						continue
+					}
+					if created {
+						currency := t.Alert.TargetCurrency()
+						log.Printf("worker alert %s triggered: product %s at %.2f %s (target %.2f %s)",
+							t.Alert.ID, t.Alert.ProductID, t.Price.Amount(currency), currency, t.Alert.Threshold(), currency)
+					}
+				}
+				return err
+			},
+		},
+		{
+			Name:    "notification_dispatch",
+			Spec:    "@every 1m",
+			Timeout: 5 * time.Minute,
+			Overlap: platform.OverlapQueue,
+			Run: func(ctx context.Context) error {
+				sent, err := dispatcher.DispatchDue(ctx)
+				if sent > 0 {
+					log.Printf("worker notifications: %d delivered", sent)
+				}
+				return err
+			},
+		},
+		{
+			// Re-run saved searches and report new matching products
+			Name:    "saved_searches",
+			Spec:    "@every 1h",
+			Timeout: 10 * time.Minute,
+			Jitter:  time.Minute,
+			Run: func(ctx context.Context) error {
+				queued, err := searchWatcher.Run(ctx)
+				if queued > 0 {
+					log.Printf("worker saved searches: %d notifications queued", queued)
+				}
+				return err
+			},
+		},
+		{
+			// Queue weekly summaries; the dispatcher sends each at the user's digest time
+			Name:    "weekly_digest",
+			Spec:    "0 4 * * 1",
+			Timeout: 10 * time.Minute,
+			Run: func(ctx context.Context) error {
+				queued, err := digest.Send(ctx, time.Now().UTC())
+				log.Printf("worker weekly digest: %d queued", queued)
+				return err
+			},
+		},
+		{
+			// Drop push tokens of devices that stopped checking in
+			Name:    "device_prune",
+			Spec:    "0 3 * * *",
+			Timeout: time.Minute,
+			Run: func(ctx context.Context) error {
+				pruned, err := devices.PruneStale(ctx)
+				if pruned > 0 {
+					log.Printf("worker pruned %d stale devices", pruned)
+				}
+				return err
+			},
+		},
+	}
+
+	scheduler := platform.NewScheduler()
+	for _, job := range jobs {
+		job, enabled, err := applyJobConfig(job, cfg.Worker.Jobs[job.Name])
+		if err != nil {
+			log.Fatalf("worker job %s: %v", job.Name, err)
+		}
+		if !enabled {
+			log.Printf("worker job %s disabled", job.Name)
+			continue
 		}
+		if err := scheduler.Register(job); err != nil {
+			log.Fatalf("worker: %v", err)
+		}
+	}
+
+	statusAddr := cfg.Worker.StatusAddr
+	if statusAddr == "" {
+		statusAddr = ":8081"
+	}
+	status := &http.Server{
+		Addr:    statusAddr,
+		Handler: router.Build(router.Deps{Jobs: handler.NewJobStatusHandler(scheduler)}),
+	}
+	go func() {
+		if err := status.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("worker status server: %v", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Only the elected replica runs jobs; the others take over if it dies
+	leaderTTL := time.Duration(cfg.Worker.LeaderTTLSeconds) * time.Second
+	elector := platform.NewElector(platform.NewLocker(rc, cfg.Redis.KeyPrefix+"lock:"), "worker-scheduler", leaderTTL)
+	if elector.Campaign(ctx) {
+		log.Printf("worker elected leader")
+	} else {
+		log.Printf("worker on standby; another replica is leader")
+	}
+	go elector.Run(ctx)
+
+	// Every replica delivers webhooks; the consumer group spreads the jobs
+	go platform.ConsumeUntilDone(ctx, queue, usecase.WebhookDeliveryTopic, func(ctx context.Context, msg *platform.Message) error {
+		return webhooks.Deliver(ctx, string(msg.Payload))
+	}, func(err error) { log.Printf("worker webhook deliveries: %v; restarting", err) })
+	scheduler.SetLeader(elector)
+
+	log.Printf("worker running %d jobs, status on %s", len(scheduler.Statuses()), statusAddr)
+	scheduler.Start(ctx)
+	events.Wait()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = status.Shutdown(shutdownCtx)
+}
+
+// newEventBus returns the domain event bus. With a topic prefix configured,
+// events are also bridged onto the queue for other consumers.
+func newEventBus(cfg *config.Config, queue usecase.JobQueue) *usecase.EventBus {
+	events := usecase.NewEventBus(func(_ usecase.Event, err error) { log.Printf("worker events: %v", err) })
+	if cfg.Events.TopicPrefix != "" {
+		events.SubscribeAsync(usecase.AllEvents, usecase.QueueBridge(queue, cfg.Events.TopicPrefix))
+	}
+	return events
+}
+
+// pushChannel returns the FCM channel, or a logging stand-in when FCM is not configured.
+func pushChannel(cfg *config.Config, devices usecase.DeviceRepository) usecase.NotificationChannel {
+	if cfg.FCM.ProjectID == "" || cfg.FCM.CredentialsFile == "" {
+		return gateway.NewLogNotificationChannel(domain.ChannelPush)
 	}
+	creds, err := os.ReadFile(cfg.FCM.CredentialsFile)
+	if err != nil {
+		log.Fatalf("fcm credentials: %v", err)
+	}
+	tokens, err := gateway.NewGoogleTokenSource(creds, gateway.FCMScope, nil)
+	if err != nil {
+		log.Fatalf("fcm credentials: %v", err)
+	}
+	return gateway.NewFCMPushChannel(cfg.FCM.ProjectID, tokens, devices, nil)
+}
 
-	warm()
-	ticker := time.NewTicker(30 * time.Minute)
-	defer ticker.Stop()
-	for range ticker.C {
-		warm()
+// emailChannel returns the SMTP channel, or a logging stand-in when no SMTP host is set.
+func emailChannel(cfg *config.Config, prefs usecase.PreferencesRepository) usecase.NotificationChannel {
+	if cfg.SMTP.Host == "" {
+		return gateway.NewLogNotificationChannel(domain.ChannelEmail)
+	}
+	renderer, err := gateway.NewEmailRenderer()
+	if err != nil {
+		log.Fatalf("email templates: %v", err)
+	}
+	ch, err := gateway.NewSMTPEmailChannel(gateway.SMTPConfig{
+		Host:     cfg.SMTP.Host,
+		Port:     cfg.SMTP.Port,
+		Username: cfg.SMTP.Username,
+		Password: cfg.SMTP.Password,
+		From:     cfg.SMTP.From,
+		StartTLS: cfg.SMTP.StartTLS,
+	}, prefs, renderer)
+	if err != nil {
+		log.Fatalf("smtp: %v", err)
+	}
+	return ch
+}
+
+// smsChannel returns the SMS channel, or a logging stand-in when no SMS provider is set.
+func smsChannel(cfg *config.Config) usecase.NotificationChannel {
+	if cfg.SMS.APIURL == "" {
+		return gateway.NewLogNotificationChannel(domain.ChannelSMS)
+	}
+	return gateway.NewSMSChannel(gateway.NewHTTPSMSGateway(cfg.SMS.APIURL, cfg.SMS.APIKey, cfg.SMS.SenderID, nil))
+}
+
+// telegramChannel returns the Telegram channel, or a logging stand-in when no bot token is set.
+func telegramChannel(cfg *config.Config, links usecase.TelegramLinkRepository) usecase.NotificationChannel {
+	if cfg.Telegram.BotToken == "" {
+		return gateway.NewLogNotificationChannel(domain.ChannelTelegram)
+	}
+	bot := gateway.NewTelegramBotGateway(cfg.Telegram.BotToken, nil)
+	if cfg.Telegram.APIURL != "" {
+		bot.BaseURL = cfg.Telegram.APIURL
+	}
+	return gateway.NewTelegramChannel(bot, links)
+}
+
+// applyJobConfig overlays the configured overrides on a job's defaults and
+// reports whether the job is enabled.
+func applyJobConfig(job platform.Job, jc config.JobConfig) (platform.Job, bool, error) {
+	if jc.Schedule != "" {
+		job.Spec = jc.Schedule
+	}
+	if jc.TimeoutSeconds > 0 {
+		job.Timeout = time.Duration(jc.TimeoutSeconds) * time.Second
+	}
+	if jc.JitterSeconds > 0 {
+		job.Jitter = time.Duration(jc.JitterSeconds) * time.Second
+	}
+	if jc.Overlap != "" {
+		policy, err := platform.ParseOverlapPolicy(jc.Overlap)
+		if err != nil {
+			return job, false, err
+		}
+		job.Overlap = policy
 	}
+	return job, !jc.Disabled, nil
 }
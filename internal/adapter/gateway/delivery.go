@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// defaultDestination is the ISO country code products are shipped to.
+const defaultDestination = "ET"
+
+// applyDeliveryWindow parses p.DeliveryEstimate into p.Delivery, keeping the
+// original text untouched. Products whose estimate cannot be parsed are left
+// without a structured window.
+func applyDeliveryWindow(p *domain.Product, method string, now time.Time) {
+	w, ok := domain.ParseDeliveryEstimate(p.DeliveryEstimate, now)
+	if !ok {
+		return
+	}
+	w.ShippingMethod = method
+	w.Destination = defaultDestination
+	p.Delivery = &w
+}
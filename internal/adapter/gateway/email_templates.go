@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+//go:embed templates/email/*.tmpl templates/email/locales/*.json
+var emailTemplateFS embed.FS
+
+// RenderedEmail is a notification rendered for one locale.
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailRenderer renders notifications with the embedded email templates.
+// Each notification kind has an HTML and a plain-text template; kinds
+// without templates use the generic ones built from title and body.
+type EmailRenderer struct {
+	html     *htmltemplate.Template
+	text     *texttemplate.Template
+	catalogs map[string]map[string]string
+}
+
+// emailData is what the templates see.
+type emailData struct {
+	Locale  string
+	Subject string
+	Title   string
+	Body    string
+	Data    map[string]string
+	Items   []domain.NotificationItem
+}
+
+type emailButton struct {
+	URL   string
+	Label string
+}
+
+// NewEmailRenderer parses the embedded templates and locale catalogs.
+func NewEmailRenderer() (*EmailRenderer, error) {
+	r := &EmailRenderer{catalogs: map[string]map[string]string{}}
+	locales, err := emailTemplateFS.ReadDir("templates/email/locales")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range locales {
+		raw, err := emailTemplateFS.ReadFile("templates/email/locales/" + f.Name())
+		if err != nil {
+			return nil, err
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(raw, &catalog); err != nil {
+			return nil, fmt.Errorf("email locale %s: %w", f.Name(), err)
+		}
+		r.catalogs[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = catalog
+	}
+	if _, ok := r.catalogs[domain.DefaultLocale]; !ok {
+		return nil, fmt.Errorf("email locale %s missing", domain.DefaultLocale)
+	}
+
+	// T is rebound per locale when rendering
+	funcs := map[string]interface{}{
+		"T":      func(key string, args ...interface{}) string { return key },
+		"etb":    func(v float64) string { return fmt.Sprintf("%.2f", v) },
+		"button": func(url, label string) emailButton { return emailButton{URL: url, Label: label} },
+	}
+	if r.html, err = htmltemplate.New("email").Funcs(funcs).ParseFS(emailTemplateFS, "templates/email/*.html.tmpl"); err != nil {
+		return nil, err
+	}
+	if r.text, err = texttemplate.New("email").Funcs(funcs).ParseFS(emailTemplateFS, "templates/email/*.txt.tmpl"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Render renders n in the locale, falling back to the default locale for
+// unknown locales and missing translations.
+func (r *EmailRenderer) Render(n *domain.Notification, locale string) (*RenderedEmail, error) {
+	if _, ok := r.catalogs[locale]; !ok {
+		locale = domain.DefaultLocale
+	}
+	t := r.translator(locale)
+
+	name := string(n.Kind)
+	if r.html.Lookup(name+".html") == nil || r.text.Lookup(name+".txt") == nil {
+		name = "generic"
+	}
+	data := emailData{Locale: locale, Title: n.Title, Body: n.Body, Data: n.Data, Items: n.Items}
+	data.Subject = n.Title
+	if name != "generic" {
+		data.Subject = t(name + ".subject")
+	}
+
+	html, err := r.html.Clone()
+	if err != nil {
+		return nil, err
+	}
+	var htmlOut, textOut bytes.Buffer
+	if err := html.Funcs(map[string]interface{}{"T": t}).ExecuteTemplate(&htmlOut, name+".html", data); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+	text, err := r.text.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := text.Funcs(map[string]interface{}{"T": t}).ExecuteTemplate(&textOut, name+".txt", data); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+	return &RenderedEmail{Subject: data.Subject, Text: strings.TrimSpace(textOut.String()) + "\n", HTML: htmlOut.String()}, nil
+}
+
+func (r *EmailRenderer) translator(locale string) func(key string, args ...interface{}) string {
+	catalog, fallback := r.catalogs[locale], r.catalogs[domain.DefaultLocale]
+	return func(key string, args ...interface{}) string {
+		msg, ok := catalog[key]
+		if !ok {
+			if msg, ok = fallback[key]; !ok {
+				return key
+			}
+		}
+		if len(args) == 0 {
+			return msg
+		}
+		return fmt.Sprintf(msg, args...)
+	}
+}
@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// FCMScope is the OAuth2 scope needed to send FCM messages.
+const FCMScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// AccessTokenSource supplies OAuth2 bearer tokens.
+type AccessTokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// FCMPushChannel implements usecase.NotificationChannel with the FCM HTTP v1
+// API. It sends to every registered device of the user and prunes tokens
+// that FCM reports as unregistered or invalid.
+type FCMPushChannel struct {
+	ProjectID  string
+	BaseURL    string
+	tokens     AccessTokenSource
+	devices    usecase.DeviceRepository
+	HTTPClient *http.Client
+}
+
+var _ usecase.NotificationChannel = (*FCMPushChannel)(nil)
+
+// NewFCMPushChannel creates a new channel. If httpClient is nil, a default client is used.
+func NewFCMPushChannel(projectID string, tokens AccessTokenSource, devices usecase.DeviceRepository, httpClient *http.Client) *FCMPushChannel {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &FCMPushChannel{
+		ProjectID:  projectID,
+		BaseURL:    "https://fcm.googleapis.com",
+		tokens:     tokens,
+		devices:    devices,
+		HTTPClient: httpClient,
+	}
+}
+
+func (c *FCMPushChannel) Kind() domain.ChannelKind { return domain.ChannelPush }
+
+// Send delivers the notification to all of the user's devices. It succeeds if
+// any device accepted it; FCM has no idempotency key, so the key is used as
+// the collapse key and a re-sent message replaces the earlier one on device.
+func (c *FCMPushChannel) Send(ctx context.Context, n *domain.Notification, idempotencyKey string) (string, error) {
+	devices, err := c.devices.ListDevices(ctx, n.UserID)
+	if err != nil {
+		return "", err
+	}
+	if len(devices) == 0 {
+		return "", domain.ErrNoRecipient
+	}
+
+	bearer, err := c.tokens.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("fcm auth: %w", err)
+	}
+
+	var sent, stale []string
+	var errs []error
+	for _, d := range devices {
+		name, err := c.sendOne(ctx, bearer, d.Token, n, idempotencyKey)
+		var fe *fcmError
+		switch {
+		case err == nil:
+			sent = append(sent, name)
+		case errors.As(err, &fe) && fe.tokenInvalid():
+			stale = append(stale, d.Token)
+		default:
+			errs = append(errs, err)
+		}
+	}
+	if len(stale) > 0 {
+		if err := c.devices.DeleteTokens(ctx, stale); err != nil {
+			errs = append(errs, fmt.Errorf("prune tokens: %w", err))
+		}
+	}
+
+	switch {
+	case len(sent) > 0:
+		return strings.Join(sent, ","), nil
+	case len(errs) > 0:
+		return "", errors.Join(errs...)
+	default:
+		// Every token was rejected and pruned
+		return "", domain.ErrNoRecipient
+	}
+}
+
+func (c *FCMPushChannel) sendOne(ctx context.Context, bearer, token string, n *domain.Notification, key string) (string, error) {
+	data := map[string]string{"notificationId": n.ID, "kind": string(n.Kind)}
+	for k, v := range n.Data {
+		data[k] = v
+	}
+	collapse := key
+	if len(collapse) > 64 {
+		collapse = collapse[:64]
+	}
+	msg := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": n.Title, "body": n.Body},
+			"data":         data,
+			"android":      map[string]string{"collapse_key": collapse},
+			"apns":         map[string]interface{}{"headers": map[string]string{"apns-collapse-id": collapse}},
+		},
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/%s/messages:send", strings.TrimRight(c.BaseURL, "/"), c.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		var ok struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(respBody, &ok)
+		return ok.Name, nil
+	}
+	fe := &fcmError{HTTPStatus: resp.StatusCode}
+	_ = json.Unmarshal(respBody, fe)
+	return "", fe
+}
+
+// fcmError is the FCM v1 error body.
+type fcmError struct {
+	HTTPStatus int `json:"-"`
+	Err        struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (e *fcmError) Error() string {
+	return fmt.Sprintf("fcm: %d %s: %s", e.HTTPStatus, e.code(), e.Err.Message)
+}
+
+func (e *fcmError) code() string {
+	for _, d := range e.Err.Details {
+		if d.ErrorCode != "" {
+			return d.ErrorCode
+		}
+	}
+	return e.Err.Status
+}
+
+// tokenInvalid reports whether FCM rejected the registration token itself.
+func (e *fcmError) tokenInvalid() bool {
+	switch e.code() {
+	case "UNREGISTERED", "SENDER_ID_MISMATCH":
+		return true
+	case "INVALID_ARGUMENT":
+		return strings.Contains(strings.ToLower(e.Err.Message), "registration token")
+	}
+	return false
+}
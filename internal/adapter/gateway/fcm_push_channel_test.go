@@ -0,0 +1,187 @@
+package gateway
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) { return string(t), nil }
+
+type fakeDevices struct {
+	devices []*domain.Device
+	deleted []string
+}
+
+func (f *fakeDevices) UpsertDevice(context.Context, *domain.Device) error { return nil }
+func (f *fakeDevices) DeleteDevice(context.Context, string, string) error { return nil }
+func (f *fakeDevices) ListDevices(_ context.Context, userID string) ([]*domain.Device, error) {
+	var out []*domain.Device
+	for _, d := range f.devices {
+		if d.UserID == userID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+func (f *fakeDevices) DeleteTokens(_ context.Context, tokens []string) error {
+	f.deleted = append(f.deleted, tokens...)
+	return nil
+}
+func (f *fakeDevices) DeleteStale(context.Context, time.Time) (int64, error) { return 0, nil }
+
+type FCMPushChannelSuite struct {
+	suite.Suite
+	ctx     context.Context
+	devices *fakeDevices
+	n       *domain.Notification
+}
+
+func (s *FCMPushChannelSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.devices = &fakeDevices{devices: []*domain.Device{
+		{Token: "good", UserID: "U1"},
+		{Token: "gone", UserID: "U1"},
+		{Token: "flaky", UserID: "U1"},
+	}}
+	s.n = &domain.Notification{ID: "N1", UserID: "U1", Kind: domain.NotificationPriceAlert, Title: "Drop", Body: "Cheaper now", Data: map[string]string{"productId": "P1"}}
+}
+
+// newChannel serves FCM responses keyed by device token.
+func (s *FCMPushChannelSuite) newChannel(responses map[string]func(w http.ResponseWriter)) (*FCMPushChannel, *httptest.Server) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("/v1/projects/shopally/messages:send", r.URL.Path)
+		s.Equal("Bearer access-token", r.Header.Get("Authorization"))
+		var body struct {
+			Message struct {
+				Token   string            `json:"token"`
+				Data    map[string]string `json:"data"`
+				Android struct {
+					CollapseKey string `json:"collapse_key"`
+				} `json:"android"`
+			} `json:"message"`
+		}
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&body))
+		s.Equal("P1", body.Message.Data["productId"])
+		s.Equal("key:push", body.Message.Android.CollapseKey)
+		responses[body.Message.Token](w)
+	}))
+	ch := NewFCMPushChannel("shopally", staticToken("access-token"), s.devices, srv.Client())
+	ch.BaseURL = srv.URL
+	return ch, srv
+}
+
+func ok(name string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) { _, _ = io.WriteString(w, `{"name":"`+name+`"}`) }
+}
+
+func fcmFail(status int, body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+const unregistered = `{"error":{"code":404,"status":"NOT_FOUND","message":"Requested entity was not found.","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`
+
+func (s *FCMPushChannelSuite) TestSendsAndPrunesRejectedTokens() {
+	ch, srv := s.newChannel(map[string]func(http.ResponseWriter){
+		"good":  ok("projects/shopally/messages/1"),
+		"gone":  fcmFail(404, unregistered),
+		"flaky": fcmFail(503, `{"error":{"code":503,"status":"UNAVAILABLE","message":"try later"}}`),
+	})
+	defer srv.Close()
+
+	id, err := ch.Send(s.ctx, s.n, "key:push")
+	s.Require().NoError(err)
+	s.Equal("projects/shopally/messages/1", id)
+	s.Equal([]string{"gone"}, s.devices.deleted)
+}
+
+func (s *FCMPushChannelSuite) TestTransientFailureIsRetryable() {
+	s.devices.devices = s.devices.devices[2:]
+	ch, srv := s.newChannel(map[string]func(http.ResponseWriter){
+		"flaky": fcmFail(503, `{"error":{"code":503,"status":"UNAVAILABLE","message":"try later"}}`),
+	})
+	defer srv.Close()
+
+	_, err := ch.Send(s.ctx, s.n, "key:push")
+	s.Require().Error(err)
+	s.NotErrorIs(err, domain.ErrNoRecipient)
+	s.Contains(err.Error(), "UNAVAILABLE")
+}
+
+func (s *FCMPushChannelSuite) TestAllTokensRejectedMeansNoRecipient() {
+	s.devices.devices = s.devices.devices[1:2]
+	ch, srv := s.newChannel(map[string]func(http.ResponseWriter){"gone": fcmFail(404, unregistered)})
+	defer srv.Close()
+
+	_, err := ch.Send(s.ctx, s.n, "key:push")
+	s.ErrorIs(err, domain.ErrNoRecipient)
+	s.Equal([]string{"gone"}, s.devices.deleted)
+}
+
+func (s *FCMPushChannelSuite) TestNoDevices() {
+	s.devices.devices = nil
+	ch := NewFCMPushChannel("shopally", staticToken("t"), s.devices, nil)
+	_, err := ch.Send(s.ctx, s.n, "key:push")
+	s.ErrorIs(err, domain.ErrNoRecipient)
+}
+
+func (s *FCMPushChannelSuite) TestGoogleTokenSourceSignsAndCaches() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	s.Require().NoError(err)
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		s.Require().NoError(r.ParseForm())
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		s.Require().Len(parts, 3)
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		s.NoError(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig))
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		s.Contains(string(claims), `"iss":"sender@shopally.iam.gserviceaccount.com"`)
+		_, _ = io.WriteString(w, `{"access_token":"ya29.token","expires_in":3600}`)
+	}))
+	defer srv.Close()
+
+	creds, _ := json.Marshal(map[string]string{
+		"client_email": "sender@shopally.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    srv.URL,
+	})
+	ts, err := NewGoogleTokenSource(creds, FCMScope, srv.Client())
+	s.Require().NoError(err)
+
+	for i := 0; i < 2; i++ {
+		tok, err := ts.Token(s.ctx)
+		s.Require().NoError(err)
+		s.Equal("ya29.token", tok)
+	}
+	s.Equal(1, calls)
+}
+
+func TestFCMPushChannelSuite(t *testing.T) {
+	suite.Run(t, new(FCMPushChannelSuite))
+}
@@ -0,0 +1,119 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// HTTPSMSGateway sends SMS through a provider's JSON HTTP API:
+// POST {BaseURL}/messages with {"to","from","message"} and a bearer API key,
+// answered with {"id"}.
+type HTTPSMSGateway struct {
+	BaseURL    string
+	apiKey     string
+	senderID   string
+	HTTPClient *http.Client
+}
+
+var _ usecase.SMSSender = (*HTTPSMSGateway)(nil)
+
+// NewHTTPSMSGateway creates a new gateway. If httpClient is nil, a default client is used.
+func NewHTTPSMSGateway(baseURL, apiKey, senderID string, httpClient *http.Client) *HTTPSMSGateway {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &HTTPSMSGateway{BaseURL: baseURL, apiKey: apiKey, senderID: senderID, HTTPClient: httpClient}
+}
+
+func (g *HTTPSMSGateway) SendSMS(ctx context.Context, to, text string) (string, error) {
+	body, err := json.Marshal(map[string]string{"to": to, "from": g.senderID, "message": text})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(g.BaseURL, "/")+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", &SMSProviderError{Status: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return "", fmt.Errorf("sms provider: decode response: %w", err)
+	}
+	return out.ID, nil
+}
+
+// SMSProviderError is a non-2xx response of the SMS provider.
+type SMSProviderError struct {
+	Status int
+	Body   string
+}
+
+func (e *SMSProviderError) Error() string {
+	return fmt.Sprintf("sms provider: status %d: %s", e.Status, e.Body)
+}
+
+// permanent reports whether resending the same message cannot succeed.
+func (e *SMSProviderError) permanent() bool {
+	return e.Status >= 400 && e.Status < 500 && e.Status != http.StatusTooManyRequests && e.Status != http.StatusRequestTimeout
+}
+
+// SMSChannel implements usecase.NotificationChannel for users known by
+// their phone number.
+type SMSChannel struct {
+	sender usecase.SMSSender
+}
+
+var _ usecase.NotificationChannel = (*SMSChannel)(nil)
+
+// NewSMSChannel creates a new SMSChannel.
+func NewSMSChannel(sender usecase.SMSSender) *SMSChannel {
+	return &SMSChannel{sender: sender}
+}
+
+func (c *SMSChannel) Kind() domain.ChannelKind { return domain.ChannelSMS }
+
+// Send texts the notification body. SMS providers have no idempotency key,
+// so a retry after a lost response can deliver it twice.
+func (c *SMSChannel) Send(ctx context.Context, n *domain.Notification, idempotencyKey string) (string, error) {
+	phone, ok := domain.PhoneFromUserID(n.UserID)
+	if !ok {
+		return "", domain.ErrNoRecipient
+	}
+	text := n.Body
+	if text == "" {
+		text = n.Title
+	}
+	id, err := c.sender.SendSMS(ctx, phone, "ShopAlly: "+text)
+	var pe *SMSProviderError
+	if errors.As(err, &pe) && pe.permanent() {
+		return "", fmt.Errorf("%w: %v", domain.ErrDeliveryRejected, pe)
+	}
+	return id, err
+}
@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type HTTPSMSGatewaySuite struct {
+	suite.Suite
+	ctx    context.Context
+	sent   []map[string]string
+	status int
+}
+
+func (s *HTTPSMSGatewaySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.sent, s.status = nil, http.StatusCreated
+}
+
+// newGateway stands in for the SMS provider's HTTP API.
+func (s *HTTPSMSGatewaySuite) newGateway() *HTTPSMSGateway {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("/v1/messages", r.URL.Path)
+		s.Equal("Bearer key", r.Header.Get("Authorization"))
+		var body map[string]string
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&body))
+		s.sent = append(s.sent, body)
+		w.WriteHeader(s.status)
+		if s.status == http.StatusCreated {
+			_, _ = w.Write([]byte(`{"id":"SM-1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"error":"invalid phone number"}`))
+	}))
+	s.T().Cleanup(srv.Close)
+	return NewHTTPSMSGateway(srv.URL+"/v1", "key", "ShopAlly", srv.Client())
+}
+
+func (s *HTTPSMSGatewaySuite) TestSendSMS() {
+	id, err := s.newGateway().SendSMS(s.ctx, "+251911000000", "hello")
+	s.Require().NoError(err)
+	s.Equal("SM-1", id)
+	s.Equal([]map[string]string{{"to": "+251911000000", "from": "ShopAlly", "message": "hello"}}, s.sent)
+}
+
+func (s *HTTPSMSGatewaySuite) TestChannel() {
+	ch := NewSMSChannel(s.newGateway())
+
+	id, err := ch.Send(s.ctx, &domain.Notification{UserID: domain.SMSUserID("+251911000000"), Body: "Now 1400 ETB"}, "k")
+	s.Require().NoError(err)
+	s.Equal("SM-1", id)
+	s.Equal("ShopAlly: Now 1400 ETB", s.sent[0]["message"])
+
+	_, err = ch.Send(s.ctx, &domain.Notification{UserID: "app-user"}, "k")
+	s.ErrorIs(err, domain.ErrNoRecipient)
+
+	s.status = http.StatusBadRequest
+	_, err = ch.Send(s.ctx, &domain.Notification{UserID: domain.SMSUserID("+1")}, "k")
+	s.ErrorIs(err, domain.ErrDeliveryRejected)
+
+	s.status = http.StatusServiceUnavailable
+	_, err = ch.Send(s.ctx, &domain.Notification{UserID: domain.SMSUserID("+1")}, "k")
+	s.Error(err)
+	s.NotErrorIs(err, domain.ErrDeliveryRejected)
+}
+
+func TestHTTPSMSGatewaySuite(t *testing.T) {
+	suite.Run(t, new(HTTPSMSGatewaySuite))
+}
+
+type RedisSMSStoreSuite struct {
+	suite.Suite
+	ctx    context.Context
+	mr     *miniredis.Miniredis
+	client *redis.Client
+}
+
+func (s *RedisSMSStoreSuite) SetupTest() {
+	s.ctx = context.Background()
+	mr, err := miniredis.Run()
+	s.Require().NoError(err)
+	s.mr = mr
+	s.client = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func (s *RedisSMSStoreSuite) TearDownTest() {
+	_ = s.client.Close()
+	s.mr.Close()
+}
+
+func (s *RedisSMSStoreSuite) TestSessionsExpire() {
+	store := NewRedisSMSSessionStore(s.client, "sa:", time.Hour)
+	missing, err := store.LoadSession(s.ctx, "+251")
+	s.Require().NoError(err)
+	s.Nil(missing)
+
+	s.Require().NoError(store.SaveSession(s.ctx, &domain.SMSSession{Phone: "+251", Results: []domain.SMSResult{{ProductID: "P1", PriceETB: 10}}}))
+	got, err := store.LoadSession(s.ctx, "+251")
+	s.Require().NoError(err)
+	s.Equal("P1", got.Results[0].ProductID)
+
+	s.mr.FastForward(2 * time.Hour)
+	got, err = store.LoadSession(s.ctx, "+251")
+	s.Require().NoError(err)
+	s.Nil(got)
+}
+
+func (s *RedisSMSStoreSuite) TestShortLinks() {
+	links := NewRedisLinkShortener(s.client, "sa:", "https://sa.et/s/", 0)
+
+	short, err := links.Shorten(s.ctx, "https://www.aliexpress.com/item/1.html")
+	s.Require().NoError(err)
+	again, err := links.Shorten(s.ctx, "https://www.aliexpress.com/item/1.html")
+	s.Require().NoError(err)
+	s.Equal(short, again)
+	s.Len(short, len("https://sa.et/s/")+shortCodeLen)
+
+	target, err := links.Resolve(s.ctx, short[len("https://sa.et/s/"):])
+	s.Require().NoError(err)
+	s.Equal("https://www.aliexpress.com/item/1.html", target)
+
+	// A code taken by another URL makes the next link use a longer code
+	code := base62(sha256.Sum256([]byte("https://www.aliexpress.com/item/2.html")))
+	s.Require().NoError(s.client.Set(s.ctx, "sa:link:"+code[:shortCodeLen], "https://elsewhere", 0).Err())
+	other, err := links.Shorten(s.ctx, "https://www.aliexpress.com/item/2.html")
+	s.Require().NoError(err)
+	s.Equal("https://sa.et/s/"+code[:shortCodeLen+1], other)
+
+	unknown, err := links.Resolve(s.ctx, "nope")
+	s.Require().NoError(err)
+	s.Empty(unknown)
+}
+
+func TestRedisSMSStoreSuite(t *testing.T) {
+	suite.Run(t, new(RedisSMSStoreSuite))
+}
@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// maxRedirectHops bounds how many redirects a short link may take.
+const maxRedirectHops = 8
+
+// productLinkInPage finds a product URL in pages that redirect by script,
+// as AliExpress short links on mobile sometimes do.
+var productLinkInPage = regexp.MustCompile(`https?:(?:\\?/){2}[a-z.]*aliexpress\.(?:com|us|ru)(?:\\?/)(?:item|i)(?:\\?/)\d{6,20}\.html`)
+
+// errForbiddenHop rejects redirects that leave the marketplaces.
+var errForbiddenHop = errors.New("expand link: redirect leaves AliExpress and Alibaba")
+
+// HTTPURLExpander follows short links' redirects one hop at a time and stops
+// at the first URL that names a product, so product pages are not fetched.
+// Links are user input: every hop must be https on an AliExpress, Alibaba or
+// short-link host, and the default client refuses to dial private addresses.
+type HTTPURLExpander struct {
+	HTTPClient *http.Client
+}
+
+var _ usecase.URLExpander = (*HTTPURLExpander)(nil)
+
+// NewHTTPURLExpander creates a new expander. If httpClient is nil, a client
+// with a short timeout that only dials public addresses is used.
+func NewHTTPURLExpander(httpClient *http.Client) *HTTPURLExpander {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second, Transport: publicTransport()}
+	}
+	return &HTTPURLExpander{HTTPClient: httpClient}
+}
+
+func (e *HTTPURLExpander) Expand(ctx context.Context, shortURL string) (string, error) {
+	current := strings.TrimSpace(shortURL)
+	if !strings.Contains(current, "://") {
+		current = "https://" + current
+	}
+	client := *e.HTTPClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	for hop := 0; hop <= maxRedirectHops; hop++ {
+		if _, ok := domain.ParseProductURL(current); ok {
+			return current, nil
+		}
+		if !allowedHop(current) {
+			return "", fmt.Errorf("%w: %s", errForbiddenHop, current)
+		}
+		next, done, err := e.follow(ctx, &client, current)
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return next, nil
+		}
+		current = next
+	}
+	return "", errors.New("expand link: too many redirects")
+}
+
+// allowedHop reports whether raw may be requested: https on the default
+// port of a marketplace or short-link host.
+func allowedHop(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return false
+	}
+	return domain.IsProductLinkHost(u.Hostname())
+}
+
+// follow requests url once. It returns the redirect target, or the final
+// URL with done set when the response is not a redirect.
+func (e *HTTPURLExpander) follow(ctx context.Context, client *http.Client, url string) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	// Short link services answer mobile browsers with plain redirects
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 13) AppleWebKit/537.36 (KHTML, like Gecko) Mobile Safari/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		loc, err := resp.Location()
+		if err != nil {
+			return "", false, fmt.Errorf("expand link: redirect without location: %w", err)
+		}
+		return loc.String(), false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", false, fmt.Errorf("expand link: status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256<<10))
+	if err != nil {
+		return "", false, err
+	}
+	if link := productLinkInPage.Find(body); link != nil {
+		return strings.ReplaceAll(string(link), `\/`, "/"), true, nil
+	}
+	return url, true, nil
+}
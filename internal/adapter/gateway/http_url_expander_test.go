@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// routeToServer sends every request to the test server whatever its host,
+// recording the URLs that were requested.
+type routeToServer struct {
+	target    *url.URL
+	requested []string
+}
+
+func (rt *routeToServer) RoundTrip(r *http.Request) (*http.Response, error) {
+	rt.requested = append(rt.requested, r.URL.String())
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+type HTTPURLExpanderSuite struct {
+	suite.Suite
+	ctx    context.Context
+	routes *routeToServer
+	client *http.Client
+}
+
+func (s *HTTPURLExpanderSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+// serve starts the test server and routes the expander's requests to it.
+func (s *HTTPURLExpanderSuite) serve(h http.HandlerFunc) {
+	srv := httptest.NewServer(h)
+	s.T().Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	s.routes = &routeToServer{target: target}
+	s.client = &http.Client{Transport: s.routes}
+}
+
+func (s *HTTPURLExpanderSuite) TestExpandFollowsRedirectsToTheProduct() {
+	s.serve(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_mK8abcd":
+			http.Redirect(w, r, "https://s.click.aliexpress.com/e/_DlXyz", http.StatusFound)
+		case "/e/_DlXyz":
+			http.Redirect(w, r, "https://www.aliexpress.com/item/1005004567890123.html?aff_fcid=x", http.StatusMovedPermanently)
+		default:
+			s.Failf("unexpected request", "path %s", r.URL.Path)
+		}
+	})
+
+	got, err := NewHTTPURLExpander(s.client).Expand(s.ctx, "https://a.aliexpress.com/_mK8abcd")
+	s.Require().NoError(err)
+	s.Equal("https://www.aliexpress.com/item/1005004567890123.html?aff_fcid=x", got)
+	// The product page itself is never requested
+	s.Equal([]string{"https://a.aliexpress.com/_mK8abcd", "https://s.click.aliexpress.com/e/_DlXyz"}, s.routes.requested)
+}
+
+func (s *HTTPURLExpanderSuite) TestExpandRefusesRedirectsOffTheMarketplaces() {
+	s.serve(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		case "/internal":
+			http.Redirect(w, r, "https://internal.example.com/admin", http.StatusFound)
+		case "/plain":
+			http.Redirect(w, r, "http://a.aliexpress.com/_next", http.StatusFound)
+		case "/port":
+			http.Redirect(w, r, "https://a.aliexpress.com:8443/_next", http.StatusFound)
+		default:
+			s.Failf("unexpected request", "path %s", r.URL.Path)
+		}
+	})
+
+	for _, path := range []string{"/metadata", "/internal", "/plain", "/port"} {
+		_, err := NewHTTPURLExpander(s.client).Expand(s.ctx, "https://ali.ski"+path)
+		s.ErrorIs(err, errForbiddenHop, path)
+	}
+	_, err := NewHTTPURLExpander(s.client).Expand(s.ctx, "https://127.0.0.1/_mK8abcd")
+	s.ErrorIs(err, errForbiddenHop)
+	s.Len(s.routes.requested, 4, "only the short links may be requested")
+}
+
+func (s *HTTPURLExpanderSuite) TestExpandFindsScriptRedirects() {
+	s.serve(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><script>window.location.href = "https:\/\/m.aliexpress.com\/item\/1005004567890123.html?sourceType=1";</script></html>`)
+	})
+
+	got, err := NewHTTPURLExpander(s.client).Expand(s.ctx, "https://a.aliexpress.com/_mK8abcd")
+	s.Require().NoError(err)
+	s.Equal("https://m.aliexpress.com/item/1005004567890123.html", got)
+}
+
+func (s *HTTPURLExpanderSuite) TestExpandReturnsTheFinalURL() {
+	s.serve(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>not found</html>")
+	})
+
+	got, err := NewHTTPURLExpander(s.client).Expand(s.ctx, "a.aliexpress.com/gone")
+	s.Require().NoError(err)
+	s.Equal("https://a.aliexpress.com/gone", got)
+}
+
+func (s *HTTPURLExpanderSuite) TestExpandFailsOnRedirectLoopsAndErrors() {
+	s.serve(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/loop" {
+			http.Redirect(w, r, "/loop", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := NewHTTPURLExpander(s.client).Expand(s.ctx, "https://a.aliexpress.com/loop")
+	s.Error(err)
+	_, err = NewHTTPURLExpander(s.client).Expand(s.ctx, "https://a.aliexpress.com/down")
+	s.Error(err)
+}
+
+func (s *HTTPURLExpanderSuite) TestDefaultClientRefusesPrivateAddresses() {
+	for _, addr := range []string{"127.0.0.1:443", "10.0.0.8:443", "169.254.169.254:80", "100.64.1.1:443", "[::1]:443", "[fd00::1]:443", "0.0.0.0:443"} {
+		s.Error(refusePrivateAddress("tcp", addr, nil), addr)
+	}
+	s.NoError(refusePrivateAddress("tcp", "47.246.136.1:443", nil))
+	s.NoError(refusePrivateAddress("tcp6", "[2a00:1450:4001:82a::200e]:443", nil))
+}
+
+func TestHTTPURLExpanderSuite(t *testing.T) {
+	suite.Run(t, new(HTTPURLExpanderSuite))
+}
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// HTTPWebhookSender posts webhook deliveries to API clients' endpoints.
+// Redirects are not followed: a delivery goes to the registered URL only.
+// The default client refuses to dial private addresses, so a registered URL
+// cannot reach services inside the network.
+type HTTPWebhookSender struct {
+	HTTPClient *http.Client
+}
+
+var _ usecase.WebhookSender = (*HTTPWebhookSender)(nil)
+
+// NewHTTPWebhookSender creates a new sender. If httpClient is nil, a client
+// with a short timeout that only dials public addresses is used.
+func NewHTTPWebhookSender(httpClient *http.Client) *HTTPWebhookSender {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second, Transport: publicTransport()}
+	}
+	return &HTTPWebhookSender{HTTPClient: httpClient}
+}
+
+func (s *HTTPWebhookSender) Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("User-Agent", "ShopAlly-Webhooks/1.0")
+
+	client := *s.HTTPClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
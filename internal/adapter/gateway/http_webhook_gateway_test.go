@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HTTPWebhookSenderSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func (s *HTTPWebhookSenderSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *HTTPWebhookSenderSuite) TestPostSendsHeadersAndBody() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("sha256=abc", r.Header.Get("X-ShopAlly-Signature"))
+		s.Equal("application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		s.Equal(`{"id":"D1"}`, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	s.T().Cleanup(srv.Close)
+
+	status, err := NewHTTPWebhookSender(srv.Client()).Post(s.ctx, srv.URL+"/hooks",
+		map[string]string{"X-ShopAlly-Signature": "sha256=abc", "Content-Type": "application/json"}, []byte(`{"id":"D1"}`))
+	s.Require().NoError(err)
+	s.Equal(http.StatusNoContent, status)
+}
+
+func (s *HTTPWebhookSenderSuite) TestPostDoesNotFollowRedirects() {
+	followed := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { followed = true }))
+	s.T().Cleanup(target.Close)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	s.T().Cleanup(srv.Close)
+
+	status, err := NewHTTPWebhookSender(srv.Client()).Post(s.ctx, srv.URL, nil, []byte(`{}`))
+	s.Require().NoError(err)
+	s.Equal(http.StatusTemporaryRedirect, status)
+	s.False(followed)
+}
+
+func (s *HTTPWebhookSenderSuite) TestDefaultClientRefusesPrivateAddresses() {
+	reached := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true }))
+	s.T().Cleanup(srv.Close)
+
+	_, err := NewHTTPWebhookSender(nil).Post(s.ctx, srv.URL, nil, []byte(`{}`))
+	s.Require().Error(err)
+	s.Contains(err.Error(), "non-public address")
+	s.False(reached)
+}
+
+func TestHTTPWebhookSenderSuite(t *testing.T) {
+	suite.Run(t, new(HTTPWebhookSenderSuite))
+}
@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"context"
+	"log"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// LogNotificationChannel implements usecase.NotificationChannel by logging the
+// notification. It stands in for a channel whose provider is not configured.
+type LogNotificationChannel struct {
+	kind domain.ChannelKind
+}
+
+func NewLogNotificationChannel(kind domain.ChannelKind) *LogNotificationChannel {
+	return &LogNotificationChannel{kind: kind}
+}
+
+func (c *LogNotificationChannel) Kind() domain.ChannelKind { return c.kind }
+
+func (c *LogNotificationChannel) Send(ctx context.Context, n *domain.Notification, idempotencyKey string) (string, error) {
+	log.Printf("notification [%s] to user %s (%s): %s - %s", c.kind, n.UserID, idempotencyKey, n.Title, n.Body)
+	return idempotencyKey, nil
+}
+
+var _ usecase.NotificationChannel = (*LogNotificationChannel)(nil)
@@ -16,6 +16,38 @@ func NewMockAlibabaGateway() usecase.AlibabaGateway {
 }
 
 func (m *MockAlibabaGateway) FetchProducts(ctx context.Context, query string, filters map[string]interface{}) ([]*domain.Product, error) {
+	return mockProducts(), nil
+}
+
+// GetProduct returns one of the hardcoded products with mocked seller and shipping details.
+func (m *MockAlibabaGateway) GetProduct(ctx context.Context, id string) (*domain.Product, error) {
+	for _, p := range mockProducts() {
+		if p.ID != id {
+			continue
+		}
+		p.Seller = &domain.Seller{
+			ID:               "SELLER-" + p.ID,
+			Name:             "Mock Official Store",
+			StoreURL:         "#",
+			Country:          "CN",
+			PositiveFeedback: 97.5,
+			Followers:        12000,
+			YearsActive:      5,
+		}
+		express := domain.NewDeliveryWindow(5, 10, time.Now().UTC())
+		express.ShippingMethod = "DHL Express"
+		express.Destination = defaultDestination
+		p.Shipping = []domain.ShippingOption{
+			{Method: "AliExpress Standard Shipping", Cost: domain.Price{USD: 0}, Delivery: p.Delivery, Tracked: true},
+			{Method: "DHL Express", Cost: domain.Price{USD: 18.50}, Delivery: &express, Tracked: true},
+		}
+		return p, nil
+	}
+	return nil, domain.ErrProductNotFound
+}
+
+// mockProducts builds a fresh copy of the hardcoded catalog.
+func mockProducts() []*domain.Product {
 	fxTs, _ := time.Parse(time.RFC3339, "2025-08-22T10:00:00Z")
 
 	products := []*domain.Product{
@@ -25,11 +57,13 @@ func (m *MockAlibabaGateway) FetchProducts(ctx context.Context, query string, fi
 			ImageURL:          "https://via.placeholder.com/150",
 			AIMatchPercentage: 92,
 			Price:             domain.Price{ETB: 4999.00, USD: 45.45, FXTimestamp: fxTs},
+			OriginalPrice:     &domain.Price{ETB: 9999.00, USD: 90.90, FXTimestamp: fxTs},
 			ProductRating:     4.6,
 			SellerScore:       95,
 			DeliveryEstimate:  "15-30 days",
 			SummaryBullets:    []string{"This is a mock summary bullet."},
 			DeeplinkURL:       "#",
+			Stock:             units(120),
 		},
 		{
 			ID:                "MOCK-124",
@@ -42,6 +76,7 @@ func (m *MockAlibabaGateway) FetchProducts(ctx context.Context, query string, fi
 			DeliveryEstimate:  "12-25 days",
 			SummaryBullets:    []string{"Good battery life"},
 			DeeplinkURL:       "#",
+			Stock:             units(340),
 		},
 		{
 			ID:                "MOCK-125",
@@ -49,11 +84,13 @@ func (m *MockAlibabaGateway) FetchProducts(ctx context.Context, query string, fi
 			ImageURL:          "https://via.placeholder.com/150",
 			AIMatchPercentage: 90,
 			Price:             domain.Price{ETB: 5499.00, USD: 50.00, FXTimestamp: fxTs},
+			OriginalPrice:     &domain.Price{ETB: 6599.00, USD: 60.00, FXTimestamp: fxTs},
 			ProductRating:     4.7,
 			SellerScore:       93,
 			DeliveryEstimate:  "10-20 days",
 			SummaryBullets:    []string{"Fast charging"},
 			DeeplinkURL:       "#",
+			Stock:             units(85),
 		},
 		{
 			ID:                "MOCK-126",
@@ -66,6 +103,7 @@ func (m *MockAlibabaGateway) FetchProducts(ctx context.Context, query string, fi
 			DeliveryEstimate:  "7-15 days",
 			SummaryBullets:    []string{"High refresh rate display"},
 			DeeplinkURL:       "#",
+			Stock:             units(12),
 		},
 		{
 			ID:                "MOCK-127",
@@ -78,8 +116,19 @@ func (m *MockAlibabaGateway) FetchProducts(ctx context.Context, query string, fi
 			DeliveryEstimate:  "10-18 days",
 			SummaryBullets:    []string{"Budget friendly"},
 			DeeplinkURL:       "#",
+			Stock:             units(0),
 		},
 	}
 
-	return products, nil
+	now := time.Now().UTC()
+	for _, p := range products {
+		applyDeliveryWindow(p, "AliExpress Standard Shipping", now)
+	}
+
+	return products
+}
+
+// units returns a stock level for a mock product.
+func units(n int) *int {
+	return &n
 }
@@ -2,7 +2,9 @@ package gateway
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/shopally-ai/pkg/domain"
 	"github.com/shopally-ai/pkg/usecase"
 )
 
@@ -20,3 +22,15 @@ func (m *MockLLMGateway) ParseIntent(ctx context.Context, query string) (map[str
 		"price_max_ETB": 5000,
 	}, nil
 }
+
+func (m *MockLLMGateway) SummarizeProduct(ctx context.Context, product *domain.Product) ([]string, error) {
+	// Very simple mocked summary built from the structured fields
+	bullets := []string{fmt.Sprintf("Rated %.1f/5 by buyers", product.ProductRating)}
+	if product.Seller != nil {
+		bullets = append(bullets, fmt.Sprintf("Sold by %s (%.1f%% positive feedback)", product.Seller.Name, product.Seller.PositiveFeedback))
+	}
+	if product.DeliveryEstimate != "" {
+		bullets = append(bullets, "Delivers in "+product.DeliveryEstimate)
+	}
+	return bullets, nil
+}
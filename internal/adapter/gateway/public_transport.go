@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// publicTransport returns a transport for requests to user-supplied URLs. It
+// only dials public addresses and uses no proxy, so the dial check sees the
+// real destination.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: refusePrivateAddress}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     30 * time.Second,
+	}
+}
+
+// refusePrivateAddress is a dialer control that refuses loopback, private,
+// link-local and other non-public addresses, which a hostile URL, redirect or
+// DNS record could otherwise point a request at.
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", address, err)
+	}
+	if !domain.IsPublicAddress(ap.Addr()) {
+		return fmt.Errorf("refusing to dial non-public address %s", ap.Addr().Unmap())
+	}
+	return nil
+}
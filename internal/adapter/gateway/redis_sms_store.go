@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// smsSeenTTL is how long incoming message IDs are remembered; providers give
+// up redelivering well within it.
+const smsSeenTTL = 24 * time.Hour
+
+// RedisSMSSessionStore keeps SMS search sessions as JSON values that expire
+// after the TTL.
+type RedisSMSSessionStore struct {
+	client     *redis.Client
+	prefix     string
+	seenPrefix string
+	ttl        time.Duration
+}
+
+var _ usecase.SMSSessionStore = (*RedisSMSSessionStore)(nil)
+
+func NewRedisSMSSessionStore(client *redis.Client, prefix string, ttl time.Duration) *RedisSMSSessionStore {
+	if prefix == "" {
+		prefix = "sa:" //default namespace
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &RedisSMSSessionStore{client: client, prefix: prefix + "sms:session:", seenPrefix: prefix + "sms:seen:", ttl: ttl}
+}
+
+func (s *RedisSMSSessionStore) SaveSession(ctx context.Context, session *domain.SMSSession) error {
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+session.Phone, raw, s.ttl).Err()
+}
+
+func (s *RedisSMSSessionStore) LoadSession(ctx context.Context, phone string) (*domain.SMSSession, error) {
+	raw, err := s.client.Get(ctx, s.prefix+phone).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session domain.SMSSession
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *RedisSMSSessionStore) MarkReceived(ctx context.Context, messageID string) (bool, error) {
+	return s.client.SetNX(ctx, s.seenPrefix+messageID, 1, smsSeenTTL).Result()
+}
+
+// shortCodeLen is the length of short link codes; 62^7 codes make
+// collisions between live links unlikely, and a collision takes a longer code.
+const shortCodeLen = 7
+
+// RedisLinkShortener stores short link codes in Redis. Codes are derived
+// from the URL, so shortening the same URL again reuses its code.
+type RedisLinkShortener struct {
+	client  *redis.Client
+	prefix  string
+	baseURL string
+	ttl     time.Duration
+}
+
+var _ usecase.LinkShortener = (*RedisLinkShortener)(nil)
+
+// NewRedisLinkShortener creates a shortener whose links are baseURL + code,
+// e.g. "https://sa.et/s/" + "Ab3xY9q".
+func NewRedisLinkShortener(client *redis.Client, prefix, baseURL string, ttl time.Duration) *RedisLinkShortener {
+	if prefix == "" {
+		prefix = "sa:" //default namespace
+	}
+	if ttl <= 0 {
+		ttl = 30 * 24 * time.Hour
+	}
+	return &RedisLinkShortener{client: client, prefix: prefix + "link:", baseURL: baseURL, ttl: ttl}
+}
+
+func (s *RedisLinkShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	code := base62(sha256.Sum256([]byte(longURL)))
+	for n := shortCodeLen; n <= len(code); n++ {
+		key := s.prefix + code[:n]
+		created, err := s.client.SetNX(ctx, key, longURL, s.ttl).Result()
+		if err != nil {
+			return "", err
+		}
+		if created {
+			return s.baseURL + code[:n], nil
+		}
+		stored, err := s.client.Get(ctx, key).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return "", err
+		}
+		if stored == longURL {
+			// Already ours: keep it alive as long as it is being shared
+			if err := s.client.Expire(ctx, key, s.ttl).Err(); err != nil {
+				return "", err
+			}
+			return s.baseURL + code[:n], nil
+		}
+	}
+	return "", errors.New("short link: no free code")
+}
+
+// Resolve returns the URL behind a code, or "" when it is unknown or expired.
+func (s *RedisLinkShortener) Resolve(ctx context.Context, code string) (string, error) {
+	u, err := s.client.Get(ctx, s.prefix+code).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return u, err
+}
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+func base62(sum [32]byte) string {
+	n := new(big.Int).SetBytes(sum[:])
+	base, mod := big.NewInt(62), new(big.Int)
+	var sb strings.Builder
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		sb.WriteByte(base62Alphabet[mod.Int64()])
+	}
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// RedisViewTracker records product views in a sorted set scored by view time.
+// Entries older than the TTL are trimmed on every write.
+type RedisViewTracker struct {
+	client *redis.Client
+	key    string
+	ttl    time.Duration
+}
+
+func NewRedisViewTracker(client *redis.Client, prefix string, ttl time.Duration) *RedisViewTracker {
+	if prefix == "" {
+		prefix = "sa:" //default namespace
+	}
+	return &RedisViewTracker{
+		client: client,
+		key:    prefix + "views:products",
+		ttl:    ttl,
+	}
+}
+
+func (t *RedisViewTracker) TrackView(ctx context.Context, productID string) error {
+	now := time.Now()
+	pipe := t.client.TxPipeline()
+	pipe.ZAdd(ctx, t.key, redis.Z{Score: float64(now.Unix()), Member: productID})
+	if t.ttl > 0 {
+		pipe.ZRemRangeByScore(ctx, t.key, "-inf", "("+strconv.FormatInt(now.Add(-t.ttl).Unix(), 10))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RecentlyViewed returns product IDs viewed at or after since.
+func (t *RedisViewTracker) RecentlyViewed(ctx context.Context, since time.Time) ([]string, error) {
+	return t.client.ZRangeByScore(ctx, t.key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+}
+
+// Ensure interface compliance at compile time
+var _ usecase.ViewTracker = (*RedisViewTracker)(nil)
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisViewTrackerSuite struct {
+	suite.Suite
+	ctx     context.Context
+	mr      *miniredis.Miniredis
+	client  *redis.Client
+	tracker *RedisViewTracker
+}
+
+func (s *RedisViewTrackerSuite) SetupTest() {
+	s.ctx = context.Background()
+	mr, err := miniredis.Run()
+	s.Require().NoError(err)
+	s.mr = mr
+	s.client = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	s.tracker = NewRedisViewTracker(s.client, "sa:", time.Hour)
+}
+
+func (s *RedisViewTrackerSuite) TearDownTest() {
+	_ = s.client.Close()
+	s.mr.Close()
+}
+
+func (s *RedisViewTrackerSuite) TestTrackAndList() {
+	s.Require().NoError(s.tracker.TrackView(s.ctx, "MOCK-1"))
+	s.Require().NoError(s.tracker.TrackView(s.ctx, "MOCK-2"))
+	s.Require().NoError(s.tracker.TrackView(s.ctx, "MOCK-1"))
+
+	ids, err := s.tracker.RecentlyViewed(s.ctx, time.Now().Add(-time.Minute))
+	s.Require().NoError(err)
+	s.ElementsMatch([]string{"MOCK-1", "MOCK-2"}, ids)
+}
+
+func (s *RedisViewTrackerSuite) TestOldViewsAreTrimmed() {
+	stale := float64(time.Now().Add(-2 * time.Hour).Unix())
+	_, err := s.client.ZAdd(s.ctx, "sa:views:products", redis.Z{Score: stale, Member: "OLD"}).Result()
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.tracker.TrackView(s.ctx, "NEW"))
+
+	members, err := s.client.ZRange(s.ctx, "sa:views:products", 0, -1).Result()
+	s.Require().NoError(err)
+	s.Equal([]string{"NEW"}, members)
+}
+
+func TestRedisViewTrackerSuite(t *testing.T) { suite.Run(t, new(RedisViewTrackerSuite)) }
@@ -0,0 +1,209 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// SMTPConfig holds the SMTP server settings of the email channel.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address, optionally with a name: "ShopAlly <alerts@shopally.et>".
+	From string
+	// StartTLS upgrades the connection before authenticating and fails if
+	// the server does not offer it.
+	StartTLS bool
+	// TLSConfig overrides the TLS settings used for STARTTLS.
+	TLSConfig *tls.Config
+	Timeout   time.Duration
+}
+
+// SMTPEmailChannel implements usecase.NotificationChannel by sending
+// multipart HTML and plain-text email to the address in the user's
+// notification preferences, in the user's language.
+type SMTPEmailChannel struct {
+	cfg      SMTPConfig
+	from     *mail.Address
+	prefs    usecase.PreferencesRepository
+	renderer *EmailRenderer
+}
+
+var _ usecase.NotificationChannel = (*SMTPEmailChannel)(nil)
+
+// NewSMTPEmailChannel creates a new SMTPEmailChannel.
+func NewSMTPEmailChannel(cfg SMTPConfig, prefs usecase.PreferencesRepository, renderer *EmailRenderer) (*SMTPEmailChannel, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("smtp from address: %w", err)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPEmailChannel{cfg: cfg, from: from, prefs: prefs, renderer: renderer}, nil
+}
+
+func (c *SMTPEmailChannel) Kind() domain.ChannelKind { return domain.ChannelEmail }
+
+// Send renders and sends the notification. The Message-ID is derived from
+// the idempotency key so receiving servers and clients can spot a duplicate
+// left by a retry after a lost reply.
+func (c *SMTPEmailChannel) Send(ctx context.Context, n *domain.Notification, idempotencyKey string) (string, error) {
+	prefs, err := c.prefs.GetPreferences(ctx, n.UserID)
+	if err != nil {
+		return "", err
+	}
+	if prefs == nil || prefs.Email == "" {
+		return "", domain.ErrNoRecipient
+	}
+	rendered, err := c.renderer.Render(n, prefs.Locale)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrDeliveryRejected, err)
+	}
+
+	messageID := c.messageID(idempotencyKey)
+	msg, err := buildMultipartEmail(c.from, &mail.Address{Address: prefs.Email}, messageID, rendered)
+	if err != nil {
+		return "", err
+	}
+	if err := c.deliver(ctx, prefs.Email, msg); err != nil {
+		var te *textproto.Error
+		if errors.As(err, &te) && te.Code >= 500 {
+			return "", fmt.Errorf("%w: smtp: %v", domain.ErrDeliveryRejected, te)
+		}
+		return "", fmt.Errorf("smtp: %w", err)
+	}
+	return messageID, nil
+}
+
+func (c *SMTPEmailChannel) messageID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	host := c.from.Address[strings.LastIndex(c.from.Address, "@")+1:]
+	return "<" + hex.EncodeToString(sum[:16]) + "@" + host + ">"
+}
+
+// deliver runs one SMTP transaction.
+func (c *SMTPEmailChannel) deliver(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	dialer := net.Dialer{Timeout: c.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(c.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.cfg.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		tlsConfig := c.cfg.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: c.cfg.Host, MinVersion: tls.VersionTLS12}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMultipartEmail builds a multipart/alternative message with the plain
+// text part first, as clients show the last part they support.
+func buildMultipartEmail(from, to *mail.Address, messageID string, e *RenderedEmail) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", e.Text},
+		{"text/html; charset=UTF-8", e.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("UTF-8", e.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,297 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type receivedMail struct {
+	auth string
+	from string
+	to   []string
+	data []byte
+}
+
+// smtpStandIn is a minimal in-process SMTP server that records messages.
+type smtpStandIn struct {
+	ln net.Listener
+	// rcptReplies answers RCPT for specific addresses, e.g. "550 5.1.1 no such user".
+	rcptReplies map[string]string
+
+	mu       sync.Mutex
+	received []receivedMail
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{ln: ln, rcptReplies: map[string]string{}}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) port() int { return s.ln.Addr().(*net.TCPAddr).Port }
+
+func (s *smtpStandIn) messages() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.received...)
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	var cur receivedMail
+	_ = tp.PrintfLine("220 stand-in ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-stand-in\r\n250-8BITMIME\r\n250 AUTH PLAIN")
+		case "AUTH":
+			_, resp, _ := strings.Cut(arg, " ")
+			raw, _ := base64.StdEncoding.DecodeString(resp)
+			cur.auth = string(raw)
+			_ = tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			cur.from = addrOf(arg)
+			_ = tp.PrintfLine("250 2.1.0 OK")
+		case "RCPT":
+			to := addrOf(arg)
+			if reply, ok := s.rcptReplies[to]; ok {
+				_ = tp.PrintfLine("%s", reply)
+				continue
+			}
+			cur.to = append(cur.to, to)
+			_ = tp.PrintfLine("250 2.1.5 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			cur.data = data
+			s.mu.Lock()
+			s.received = append(s.received, cur)
+			s.mu.Unlock()
+			cur = receivedMail{auth: cur.auth}
+			_ = tp.PrintfLine("250 2.0.0 queued")
+		case "RSET", "NOOP":
+			_ = tp.PrintfLine("250 2.0.0 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 2.0.0 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 5.5.2 command not recognized")
+		}
+	}
+}
+
+func addrOf(arg string) string {
+	if i, j := strings.Index(arg, "<"), strings.Index(arg, ">"); i >= 0 && j > i {
+		return arg[i+1 : j]
+	}
+	return arg
+}
+
+type fakePreferences struct {
+	prefs map[string]*domain.NotificationPreferences
+}
+
+func (f *fakePreferences) GetPreferences(_ context.Context, userID string) (*domain.NotificationPreferences, error) {
+	return f.prefs[userID], nil
+}
+func (f *fakePreferences) SavePreferences(_ context.Context, p *domain.NotificationPreferences) error {
+	f.prefs[p.UserID] = p
+	return nil
+}
+
+type SMTPEmailChannelSuite struct {
+	suite.Suite
+	ctx      context.Context
+	server   *smtpStandIn
+	prefs    *fakePreferences
+	renderer *EmailRenderer
+	alert    *domain.Notification
+}
+
+func (s *SMTPEmailChannelSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.server = newSMTPStandIn(s.T())
+	s.prefs = &fakePreferences{prefs: map[string]*domain.NotificationPreferences{
+		"U1": {UserID: "U1", Email: "abebe@example.et", Locale: "am"},
+		"U2": {UserID: "U2", Email: "sara@example.com", Locale: "en"},
+	}}
+	var err error
+	s.renderer, err = NewEmailRenderer()
+	s.Require().NoError(err)
+	s.alert = &domain.Notification{
+		UserID: "U1",
+		Kind:   domain.NotificationPriceAlert,
+		Title:  "Price drop alert",
+		Body:   "A product you are watching is now 1400.00 ETB",
+		Data: map[string]string{
+			"productTitle": "Earbuds <Pro>",
+			"priceEtb":     "1400.00",
+			"targetEtb":    "1500.00",
+			"url":          "https://shopally.et/p/P1",
+		},
+	}
+}
+
+func (s *SMTPEmailChannelSuite) newChannel(cfg SMTPConfig) *SMTPEmailChannel {
+	cfg.Host, cfg.Port, cfg.From = "127.0.0.1", s.server.port(), "ShopAlly <alerts@shopally.et>"
+	ch, err := NewSMTPEmailChannel(cfg, s.prefs, s.renderer)
+	s.Require().NoError(err)
+	return ch
+}
+
+// parts parses a received message into its headers and decoded MIME parts.
+func (s *SMTPEmailChannelSuite) parts(raw []byte) (mail.Header, map[string]string) {
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(raw))))
+	s.Require().NoError(err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	s.Require().NoError(err)
+	s.Equal("multipart/alternative", mediaType)
+
+	out := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		s.Require().NoError(err)
+		body, err := io.ReadAll(p)
+		s.Require().NoError(err)
+		ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		out[ct] = string(body)
+	}
+	return msg.Header, out
+}
+
+func (s *SMTPEmailChannelSuite) TestSendsLocalizedMultipart() {
+	ch := s.newChannel(SMTPConfig{Username: "mailer", Password: "pw"})
+
+	id, err := ch.Send(s.ctx, s.alert, "alert:A1:email")
+	s.Require().NoError(err)
+
+	msgs := s.server.messages()
+	s.Require().Len(msgs, 1)
+	s.Equal("\x00mailer\x00pw", msgs[0].auth)
+	s.Equal("alerts@shopally.et", msgs[0].from)
+	s.Equal([]string{"abebe@example.et"}, msgs[0].to)
+
+	header, parts := s.parts(msgs[0].data)
+	s.Equal(id, header.Get("Message-ID"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	s.Require().NoError(err)
+	s.Equal("የሚከታተሉት ዕቃ ዋጋ ቀንሷል", subject)
+
+	s.Contains(parts["text/plain"], "Earbuds <Pro> አሁን 1400.00 ብር")
+	s.Contains(parts["text/plain"], "https://shopally.et/p/P1")
+	s.Contains(parts["text/html"], `<html lang="am">`)
+	s.Contains(parts["text/html"], "Earbuds &lt;Pro&gt;")
+	s.Contains(parts["text/html"], `href="https://shopally.et/p/P1"`)
+
+	// The same idempotency key yields the same Message-ID
+	again, err := ch.Send(s.ctx, s.alert, "alert:A1:email")
+	s.Require().NoError(err)
+	s.Equal(id, again)
+}
+
+func (s *SMTPEmailChannelSuite) TestErrorClassification() {
+	ch := s.newChannel(SMTPConfig{})
+
+	_, err := ch.Send(s.ctx, &domain.Notification{UserID: "nobody"}, "k")
+	s.ErrorIs(err, domain.ErrNoRecipient)
+
+	s.server.rcptReplies["abebe@example.et"] = "550 5.1.1 no such user"
+	_, err = ch.Send(s.ctx, s.alert, "k")
+	s.ErrorIs(err, domain.ErrDeliveryRejected)
+
+	s.server.rcptReplies["abebe@example.et"] = "451 4.3.0 try again later"
+	_, err = ch.Send(s.ctx, s.alert, "k")
+	s.Error(err)
+	s.NotErrorIs(err, domain.ErrDeliveryRejected)
+
+	// STARTTLS is required but the stand-in does not offer it
+	_, err = s.newChannel(SMTPConfig{StartTLS: true}).Send(s.ctx, &domain.Notification{UserID: "U2"}, "k")
+	s.ErrorContains(err, "STARTTLS")
+	s.Empty(s.server.messages())
+}
+
+func (s *SMTPEmailChannelSuite) TestRenderDigestAndFallbacks() {
+	digest := &domain.Notification{Kind: domain.NotificationDigest, Data: map[string]string{"fxStart": "120.00", "fxEnd": "121.20", "fxChangePct": "+1.0"}, Items: []domain.NotificationItem{
+		{Title: "Phone case", PriceETB: 250, PreviousETB: 300, URL: "https://shopally.et/p/P2"},
+		{Title: "Cable", PriceETB: 120},
+	}}
+	r, err := s.renderer.Render(digest, "en")
+	s.Require().NoError(err)
+	s.Equal("Your weekly ShopAlly price digest", r.Subject)
+	s.Contains(r.Text, "- Phone case: 250.00 ETB (was 300.00 ETB)")
+	s.Contains(r.Text, "- Cable: 120.00 ETB\n")
+	s.Contains(r.HTML, `href="https://shopally.et/p/P2"`)
+	s.Contains(r.Text, "USD/ETB moved from 120.00 to 121.20 this week (+1.0%).")
+
+	// Unknown locales fall back to English
+	r, err = s.renderer.Render(s.alert, "xx")
+	s.Require().NoError(err)
+	s.Equal("Price drop on a product you are watching", r.Subject)
+
+	// USD alerts name their currency and explain the drop
+	usd := &domain.Notification{Kind: domain.NotificationPriceAlert, Data: map[string]string{
+		"productTitle": "Cable", "currency": "USD", "price": "9.50", "target": "10.00", "dropCause": "seller",
+	}}
+	r, err = s.renderer.Render(usd, "en")
+	s.Require().NoError(err)
+	s.Contains(r.Text, "Cable is now 9.50 USD, at or below your target of 10.00 USD.\nThe seller lowered the price.")
+
+	// Saved-search matches list the new products
+	matches := &domain.Notification{Kind: domain.NotificationSavedSearch, Data: map[string]string{"query": "phone 8GB"}, Items: []domain.NotificationItem{
+		{Title: "Phone 8GB Pro", PriceETB: 5800},
+	}}
+	r, err = s.renderer.Render(matches, "en")
+	s.Require().NoError(err)
+	s.Equal("New products match your saved search", r.Subject)
+	s.Contains(r.Text, "These new products match \"phone 8GB\":\n\n- Phone 8GB Pro: 5800.00 ETB")
+
+	// Kinds without templates use the title and body
+	r, err = s.renderer.Render(&domain.Notification{Kind: "welcome", Title: "Welcome", Body: "Hello & thanks"}, "en")
+	s.Require().NoError(err)
+	s.Equal("Welcome", r.Subject)
+	s.Contains(r.HTML, "Hello &amp; thanks")
+	s.Contains(r.Text, "Hello & thanks")
+}
+
+func TestSMTPEmailChannelSuite(t *testing.T) {
+	suite.Run(t, new(SMTPEmailChannelSuite))
+}
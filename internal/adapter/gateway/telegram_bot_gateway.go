@@ -0,0 +1,183 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// telegramCaptionLimit is the longest caption Telegram accepts on a photo.
+const telegramCaptionLimit = 1024
+
+// TelegramBotGateway sends messages through the Telegram Bot API.
+type TelegramBotGateway struct {
+	BaseURL    string
+	token      string
+	HTTPClient *http.Client
+}
+
+var _ usecase.TelegramSender = (*TelegramBotGateway)(nil)
+
+// NewTelegramBotGateway creates a new gateway for the bot token. If
+// httpClient is nil, a default client is used.
+func NewTelegramBotGateway(token string, httpClient *http.Client) *TelegramBotGateway {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &TelegramBotGateway{BaseURL: "https://api.telegram.org", token: token, HTTPClient: httpClient}
+}
+
+// SendMessage sends msg to the chat, as a photo with a caption when it has a
+// photo URL and the text fits in a caption.
+func (g *TelegramBotGateway) SendMessage(ctx context.Context, chatID int64, msg domain.TelegramMessage) (int64, error) {
+	params := map[string]interface{}{"chat_id": chatID, "parse_mode": "HTML"}
+	method := "sendMessage"
+	if msg.PhotoURL != "" && len([]rune(msg.Text)) <= telegramCaptionLimit {
+		method = "sendPhoto"
+		params["photo"] = msg.PhotoURL
+		params["caption"] = msg.Text
+	} else {
+		params["text"] = msg.Text
+	}
+	if len(msg.Buttons) > 0 {
+		rows := make([][]map[string]string, 0, len(msg.Buttons))
+		for _, b := range msg.Buttons {
+			rows = append(rows, []map[string]string{{"text": b.Text, "url": b.URL}})
+		}
+		params["reply_markup"] = map[string]interface{}{"inline_keyboard": rows}
+	}
+
+	var result struct {
+		MessageID int64 `json:"message_id"`
+	}
+	if err := g.call(ctx, method, params, &result); err != nil {
+		return 0, err
+	}
+	return result.MessageID, nil
+}
+
+// call invokes a Bot API method and decodes its result.
+func (g *TelegramBotGateway) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(g.BaseURL, "/"), g.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		// The URL holds the bot token; keep it out of logs and stored errors
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("telegram %s: status %d: %w", method, resp.StatusCode, err)
+	}
+	if !envelope.OK {
+		code := envelope.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return &TelegramError{Method: method, Code: code, Description: envelope.Description, RetryAfter: envelope.Parameters.RetryAfter}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
+
+// TelegramError is an error response of the Bot API.
+type TelegramError struct {
+	Method      string
+	Code        int
+	Description string
+	// RetryAfter is the wait in seconds Telegram asks for when rate limiting.
+	RetryAfter int
+}
+
+func (e *TelegramError) Error() string {
+	msg := "telegram " + e.Method + ": " + strconv.Itoa(e.Code) + " " + e.Description
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %ds)", e.RetryAfter)
+	}
+	return msg
+}
+
+// TelegramChannel implements usecase.NotificationChannel by messaging the
+// user's linked Telegram chat. Chats that blocked the bot are unlinked.
+type TelegramChannel struct {
+	sender usecase.TelegramSender
+	links  usecase.TelegramLinkRepository
+}
+
+var _ usecase.NotificationChannel = (*TelegramChannel)(nil)
+
+// NewTelegramChannel creates a new TelegramChannel.
+func NewTelegramChannel(sender usecase.TelegramSender, links usecase.TelegramLinkRepository) *TelegramChannel {
+	return &TelegramChannel{sender: sender, links: links}
+}
+
+func (c *TelegramChannel) Kind() domain.ChannelKind { return domain.ChannelTelegram }
+
+// Send messages the linked chat. The Bot API has no idempotency key, so a
+// retry after a lost response can deliver the message twice.
+func (c *TelegramChannel) Send(ctx context.Context, n *domain.Notification, idempotencyKey string) (string, error) {
+	link, err := c.links.GetLinkByUser(ctx, n.UserID)
+	if err != nil {
+		return "", err
+	}
+	if link == nil {
+		return "", domain.ErrNoRecipient
+	}
+
+	text := "<b>" + html.EscapeString(n.Title) + "</b>\n" + html.EscapeString(n.Body)
+	msg := domain.TelegramMessage{Text: text}
+	if u := n.Data["url"]; strings.HasPrefix(u, "https://") {
+		msg.Buttons = []domain.TelegramButton{{Text: "Open", URL: u}}
+	}
+	id, err := c.sender.SendMessage(ctx, link.ChatID, msg)
+	var te *TelegramError
+	switch {
+	case err == nil:
+		return strconv.FormatInt(id, 10), nil
+	case errors.As(err, &te) && te.Code == http.StatusForbidden:
+		// The user blocked the bot or deleted the chat
+		if err := c.links.DeleteLinkByChat(ctx, link.ChatID); err != nil {
+			return "", fmt.Errorf("unlink chat: %w", err)
+		}
+		return "", fmt.Errorf("%w: %v", domain.ErrNoRecipient, te)
+	case errors.As(err, &te) && te.Code == http.StatusBadRequest:
+		return "", fmt.Errorf("%w: %v", domain.ErrDeliveryRejected, te)
+	default:
+		return "", err
+	}
+}
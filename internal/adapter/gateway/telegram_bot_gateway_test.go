@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeTelegramLinks struct {
+	links map[string]*domain.TelegramLink
+}
+
+func (f *fakeTelegramLinks) CreateLinkCode(context.Context, *domain.TelegramLinkCode) error {
+	return nil
+}
+func (f *fakeTelegramLinks) ConsumeLinkCode(context.Context, string, time.Time) (*domain.TelegramLinkCode, error) {
+	return nil, domain.ErrLinkCodeInvalid
+}
+func (f *fakeTelegramLinks) SaveLink(_ context.Context, l *domain.TelegramLink) error {
+	f.links[l.UserID] = l
+	return nil
+}
+func (f *fakeTelegramLinks) GetLinkByUser(_ context.Context, userID string) (*domain.TelegramLink, error) {
+	return f.links[userID], nil
+}
+func (f *fakeTelegramLinks) GetLinkByChat(context.Context, int64) (*domain.TelegramLink, error) {
+	return nil, nil
+}
+func (f *fakeTelegramLinks) DeleteLinkByChat(_ context.Context, chatID int64) error {
+	for user, l := range f.links {
+		if l.ChatID == chatID {
+			delete(f.links, user)
+		}
+	}
+	return nil
+}
+
+func (f *fakeTelegramLinks) RecordLinkFailure(context.Context, int64, time.Time, time.Duration) (int, error) {
+	return 1, nil
+}
+func (f *fakeTelegramLinks) LinkFailures(context.Context, int64, time.Time) (int, error) {
+	return 0, nil
+}
+
+type TelegramBotGatewaySuite struct {
+	suite.Suite
+	ctx      context.Context
+	links    *fakeTelegramLinks
+	requests []map[string]interface{}
+	methods  []string
+}
+
+func (s *TelegramBotGatewaySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.links = &fakeTelegramLinks{links: map[string]*domain.TelegramLink{"U1": {UserID: "U1", ChatID: 42}}}
+	s.requests, s.methods = nil, nil
+}
+
+// newGateway stands in for api.telegram.org, answering with the given
+// status and body and recording the calls.
+func (s *TelegramBotGatewaySuite) newGateway(status int, body string) *TelegramBotGateway {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]interface{}
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&params))
+		s.requests = append(s.requests, params)
+		s.methods = append(s.methods, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	s.T().Cleanup(srv.Close)
+	g := NewTelegramBotGateway("123:secret", srv.Client())
+	g.BaseURL = srv.URL
+	return g
+}
+
+func (s *TelegramBotGatewaySuite) TestSendPhotoWithButtons() {
+	g := s.newGateway(http.StatusOK, `{"ok":true,"result":{"message_id":77}}`)
+
+	id, err := g.SendMessage(s.ctx, 42, domain.TelegramMessage{
+		Text:     "<b>Earbuds</b>",
+		PhotoURL: "https://img/1.jpg",
+		Buttons:  []domain.TelegramButton{{Text: "View", URL: "https://s.click/1"}},
+	})
+	s.Require().NoError(err)
+	s.Equal(int64(77), id)
+	s.Equal([]string{"/bot123:secret/sendPhoto"}, s.methods)
+	p := s.requests[0]
+	s.Equal(float64(42), p["chat_id"])
+	s.Equal("HTML", p["parse_mode"])
+	s.Equal("<b>Earbuds</b>", p["caption"])
+	s.Equal("https://img/1.jpg", p["photo"])
+	keyboard := p["reply_markup"].(map[string]interface{})["inline_keyboard"].([]interface{})
+	s.Equal("https://s.click/1", keyboard[0].([]interface{})[0].(map[string]interface{})["url"])
+}
+
+func (s *TelegramBotGatewaySuite) TestChannelSendsToLinkedChat() {
+	ch := NewTelegramChannel(s.newGateway(http.StatusOK, `{"ok":true,"result":{"message_id":5}}`), s.links)
+
+	id, err := ch.Send(s.ctx, &domain.Notification{UserID: "U1", Title: "Price <drop>", Body: "Now 1400 ETB"}, "alert:A1:telegram")
+	s.Require().NoError(err)
+	s.Equal("5", id)
+	s.Equal([]string{"/bot123:secret/sendMessage"}, s.methods)
+	s.Equal("<b>Price &lt;drop&gt;</b>\nNow 1400 ETB", s.requests[0]["text"])
+
+	_, err = ch.Send(s.ctx, &domain.Notification{UserID: "U2"}, "k")
+	s.ErrorIs(err, domain.ErrNoRecipient)
+}
+
+func (s *TelegramBotGatewaySuite) TestChannelUnlinksBlockedChat() {
+	ch := NewTelegramChannel(s.newGateway(http.StatusForbidden, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`), s.links)
+
+	_, err := ch.Send(s.ctx, &domain.Notification{UserID: "U1"}, "k")
+	s.ErrorIs(err, domain.ErrNoRecipient)
+	s.Empty(s.links.links)
+}
+
+func (s *TelegramBotGatewaySuite) TestChannelErrorClassification() {
+	ch := NewTelegramChannel(s.newGateway(http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`), s.links)
+	_, err := ch.Send(s.ctx, &domain.Notification{UserID: "U1"}, "k")
+	s.ErrorIs(err, domain.ErrDeliveryRejected)
+
+	ch = NewTelegramChannel(s.newGateway(http.StatusTooManyRequests, `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":3}}`), s.links)
+	_, err = ch.Send(s.ctx, &domain.Notification{UserID: "U1"}, "k")
+	var te *TelegramError
+	s.Require().True(errors.As(err, &te))
+	s.Equal(3, te.RetryAfter)
+	s.False(errors.Is(err, domain.ErrDeliveryRejected) || errors.Is(err, domain.ErrNoRecipient))
+	s.NotContains(err.Error(), "secret")
+}
+
+func TestTelegramBotGatewaySuite(t *testing.T) {
+	suite.Run(t, new(TelegramBotGatewaySuite))
+}
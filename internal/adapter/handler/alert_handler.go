@@ -3,9 +3,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/shopally-ai/pkg/domain"
 	"github.com/shopally-ai/pkg/usecase"
@@ -30,10 +32,18 @@ type successResponse struct {
 }
 
 // createAlertPayload represents the expected payload for creating an alert.
+// A payload without a condition is a target-price alert on targetPrice, and
+// one without a currency is in ETB. The alert belongs to the caller named by
+// the X-User-ID header.
 type createAlertPayload struct {
-	UserID      string  `json:"userId"`
-	ProductID   string  `json:"productId"`
-	TargetPrice float64 `json:"targetPrice"`
+	ProductID   string                 `json:"productId"`
+	TargetPrice float64                `json:"targetPrice"`
+	Currency    string                 `json:"currency"`
+	Condition   *domain.AlertCondition `json:"condition"`
+	// ExpiresAt is an RFC 3339 time after which the alert stops.
+	ExpiresAt       *time.Time `json:"expiresAt"`
+	CooldownMinutes int        `json:"cooldownMinutes"`
+	Rearm           bool       `json:"rearm"`
 }
 
 // CreateAlertHandler handles POST requests to create a new alert.
@@ -42,6 +52,10 @@ func (h *AlertHandler) CreateAlertHandler(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
 
 	var payload createAlertPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
@@ -50,21 +64,39 @@ func (h *AlertHandler) CreateAlertHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	newAlert := &domain.Alert{
-		UserID:      payload.UserID,
-		ProductID:   payload.ProductID,
-		TargetPrice: payload.TargetPrice,
-		IsActive:    true,
-	}
-
-	if err := h.alertManager.CreateAlert(newAlert); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create alert: %v", err), http.StatusInternalServerError)
+		UserID:          userID,
+		ProductID:       payload.ProductID,
+		ClientID:        ClientIDFrom(r.Context()),
+		TargetPrice:     payload.TargetPrice,
+		Currency:        payload.Currency,
+		IsActive:        true,
+		ExpiresAt:       payload.ExpiresAt,
+		CooldownMinutes: payload.CooldownMinutes,
+		Rearm:           payload.Rearm,
+	}
+	if payload.Condition != nil {
+		newAlert.Condition = *payload.Condition
+	}
+
+	if err := h.alertManager.CreateAlert(r.Context(), newAlert); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			status = http.StatusBadRequest
+		case errors.Is(err, domain.ErrAlertExists), errors.Is(err, domain.ErrAlertLimit):
+			status = http.StatusConflict
+		}
+		http.Error(w, fmt.Sprintf("Failed to create alert: %v", err), status)
 		return
 	}
 
 	response := successResponse{
-		Data: map[string]string{
-			"status":  "Alert created successfully",
-			"alertId": newAlert.ID,
+		Data: map[string]interface{}{
+			"status":    "Alert created successfully",
+			"alertId":   newAlert.ID,
+			"currency":  newAlert.Currency,
+			"condition": newAlert.Condition,
+			"baseline":  newAlert.Baseline,
 		},
 		Error: nil,
 	}
@@ -83,14 +115,17 @@ func (h *AlertHandler) GetAlertHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) != 3 {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	alertID, ok := alertIDFromPath(r)
+	if !ok {
 		http.Error(w, "Invalid URL format", http.StatusBadRequest)
 		return
 	}
-	alertID := parts[2]
 
-	alert, err := h.alertManager.GetAlert(alertID)
+	alert, err := h.alertManager.GetAlert(userID, alertID)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to retrieve alert: %v", err), http.StatusNotFound)
 		return
@@ -115,14 +150,17 @@ func (h *AlertHandler) DeleteAlertHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) != 3 {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	alertID, ok := alertIDFromPath(r)
+	if !ok {
 		http.Error(w, "Invalid URL format", http.StatusBadRequest)
 		return
 	}
-	alertID := parts[2]
 
-	if err := h.alertManager.DeleteAlert(alertID); err != nil {
+	if err := h.alertManager.DeleteAlert(userID, alertID); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to delete alert: %v", err), http.StatusNotFound)
 		return
 	}
@@ -140,3 +178,16 @@ func (h *AlertHandler) DeleteAlertHandler(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
+
+// alertIDFromPath reads the alert ID from the routed {id} wildcard, falling
+// back to parsing a bare /alerts/{id} path when the handler is called directly.
+func alertIDFromPath(r *http.Request) (string, bool) {
+	if id := r.PathValue("id"); id != "" {
+		return id, true
+	}
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) != 3 || parts[2] == "" {
+		return "", false
+	}
+	return parts[2], true
+}
@@ -7,21 +7,44 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/shopally-ai/internal/adapter/gateway"
 	"github.com/shopally-ai/internal/adapter/repository"
 	"github.com/shopally-ai/pkg/usecase"
 )
 
 func TestAlertHandlers(t *testing.T) {
 	mockRepo := repository.NewMockAlertRepository()
-	alertManager := usecase.NewAlertManager(mockRepo)
+	alertManager := usecase.NewAlertManager(mockRepo, gateway.NewMockAlibabaGateway(), nil, 0, nil)
 	alertHandler := NewAlertHandler(alertManager)
 
 	var alertID string
+	// asUser sends r as the given user, as Identify does for the X-User-ID header
+	asUser := func(r *http.Request, userID string) *http.Request {
+		return r.WithContext(WithUserID(r.Context(), userID))
+	}
+
+	t.Run("CreateAlertHandler_Anonymous", func(t *testing.T) {
+		payload := []byte(`{"productId": "prod-abc", "targetPrice": 500.00}`)
+		rr := httptest.NewRecorder()
+		alertHandler.CreateAlertHandler(rr, httptest.NewRequest("POST", "/alerts", bytes.NewBuffer(payload)))
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("anonymous create: got status %v want %v", rr.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("CreateAlertHandler_UnknownProduct", func(t *testing.T) {
+		payload := []byte(`{"productId": "prod-abc", "targetPrice": 500.00}`)
+		rr := httptest.NewRecorder()
+		alertHandler.CreateAlertHandler(rr, asUser(httptest.NewRequest("POST", "/alerts", bytes.NewBuffer(payload)), "user-123"))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("unknown product: got status %v want %v", rr.Code, http.StatusBadRequest)
+		}
+	})
 
 	t.Run("CreateAlertHandler", func(t *testing.T) {
-		payload := []byte(`{"userId": "user-123", "productId": "prod-abc", "targetPrice": 500.00}`)
+		payload := []byte(`{"productId": "MOCK-123", "targetPrice": 500.00}`)
 
-		req := httptest.NewRequest("POST", "/alerts", bytes.NewBuffer(payload))
+		req := asUser(httptest.NewRequest("POST", "/alerts", bytes.NewBuffer(payload)), "user-123")
 		req.Header.Set("Content-Type", "application/json")
 
 		rr := httptest.NewRecorder()
@@ -59,12 +82,39 @@ func TestAlertHandlers(t *testing.T) {
 		}
 	})
 
+	t.Run("CreateAlertHandler_Duplicate", func(t *testing.T) {
+		payload := []byte(`{"productId": "MOCK-123", "targetPrice": 450.00}`)
+		rr := httptest.NewRecorder()
+		alertHandler.CreateAlertHandler(rr, asUser(httptest.NewRequest("POST", "/alerts", bytes.NewBuffer(payload)), "user-123"))
+		if rr.Code != http.StatusConflict {
+			t.Errorf("duplicate alert: got status %v want %v", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("OtherUsersAlertIsHidden", func(t *testing.T) {
+		if alertID == "" {
+			t.Fatal("alertID was not set in previous test")
+		}
+
+		rr := httptest.NewRecorder()
+		alertHandler.GetAlertHandler(rr, asUser(httptest.NewRequest("GET", "/alerts/"+alertID, nil), "user-456"))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("get another user's alert: got status %v want %v", rr.Code, http.StatusNotFound)
+		}
+
+		rr = httptest.NewRecorder()
+		alertHandler.DeleteAlertHandler(rr, asUser(httptest.NewRequest("DELETE", "/alerts/"+alertID, nil), "user-456"))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("delete another user's alert: got status %v want %v", rr.Code, http.StatusNotFound)
+		}
+	})
+
 	t.Run("GetAlertHandler", func(t *testing.T) {
 		if alertID == "" {
 			t.Fatal("alertID was not set in previous test")
 		}
 
-		req := httptest.NewRequest("GET", "/alerts/"+alertID, nil)
+		req := asUser(httptest.NewRequest("GET", "/alerts/"+alertID, nil), "user-123")
 		rr := httptest.NewRecorder()
 
 		alertHandler.GetAlertHandler(rr, req)
@@ -88,7 +138,7 @@ func TestAlertHandlers(t *testing.T) {
 			t.Fatal("alertID was not set in previous test")
 		}
 
-		req := httptest.NewRequest("DELETE", "/alerts/"+alertID, nil)
+		req := asUser(httptest.NewRequest("DELETE", "/alerts/"+alertID, nil), "user-123")
 		rr := httptest.NewRecorder()
 
 		alertHandler.DeleteAlertHandler(rr, req)
@@ -116,7 +166,7 @@ func TestAlertHandlers(t *testing.T) {
 			t.Fatal("alertID was not set in previous test")
 		}
 
-		req := httptest.NewRequest("GET", "/alerts/"+alertID, nil)
+		req := asUser(httptest.NewRequest("GET", "/alerts/"+alertID, nil), "user-123")
 		rr := httptest.NewRecorder()
 
 		alertHandler.GetAlertHandler(rr, req)
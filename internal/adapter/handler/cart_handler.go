@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// CartHandler serves landed-cost estimates of several products bought together.
+type CartHandler struct {
+	estimator *usecase.CartEstimator
+}
+
+// NewCartHandler creates a new CartHandler.
+func NewCartHandler(estimator *usecase.CartEstimator) *CartHandler {
+	return &CartHandler{estimator: estimator}
+}
+
+type cartPayload struct {
+	Items []domain.CartItem `json:"items"`
+}
+
+// Estimate handles POST /cart/estimate with {"items":[{"productId","quantity"}]}.
+func (h *CartHandler) Estimate(w http.ResponseWriter, r *http.Request) {
+	var payload cartPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	est, err := h.estimator.Estimate(r.Context(), payload.Items)
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+	default:
+		writeData(w, http.StatusOK, est)
+	}
+}
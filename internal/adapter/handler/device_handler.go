@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// DeviceHandler serves the push device registration endpoints.
+type DeviceHandler struct {
+	devices *usecase.DeviceManager
+}
+
+// NewDeviceHandler creates a new DeviceHandler.
+func NewDeviceHandler(devices *usecase.DeviceManager) *DeviceHandler {
+	return &DeviceHandler{devices: devices}
+}
+
+type devicePayload struct {
+	Token      string `json:"token"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"appVersion"`
+	Locale     string `json:"locale"`
+}
+
+func (p devicePayload) device() *domain.Device {
+	return &domain.Device{
+		Token:      p.Token,
+		Platform:   domain.DevicePlatform(p.Platform),
+		AppVersion: p.AppVersion,
+		Locale:     p.Locale,
+	}
+}
+
+// RegisterDevice handles POST /devices. Registering a known token refreshes
+// its details and last-seen time.
+func (h *DeviceHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var payload devicePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	d, err := h.devices.Register(r.Context(), userID, payload.device())
+	if err != nil {
+		writeDeviceError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, d)
+}
+
+// RefreshDevice handles PUT /devices/{token}: the body carries the rotated token.
+func (h *DeviceHandler) RefreshDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var payload devicePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	d, err := h.devices.Refresh(r.Context(), userID, strings.TrimSpace(r.PathValue("token")), payload.device())
+	if err != nil {
+		writeDeviceError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, d)
+}
+
+// UnregisterDevice handles DELETE /devices/{token}.
+func (h *DeviceHandler) UnregisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	if err := h.devices.Unregister(r.Context(), userID, strings.TrimSpace(r.PathValue("token"))); err != nil {
+		writeDeviceError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]string{"status": "Device unregistered"})
+}
+
+// ListDevices handles GET /devices.
+func (h *DeviceHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	devices, err := h.devices.List(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+	writeData(w, http.StatusOK, map[string]interface{}{"devices": devices})
+}
+
+func writeDeviceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case errors.Is(err, domain.ErrDeviceNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+	}
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/shopally-ai/internal/platform"
+)
+
+// JobStatusSource reports the state of scheduled jobs.
+type JobStatusSource interface {
+	Statuses() []platform.JobStatus
+}
+
+// JobStatusHandler serves the worker's job status endpoint.
+type JobStatusHandler struct {
+	jobs JobStatusSource
+}
+
+// NewJobStatusHandler creates a new JobStatusHandler.
+func NewJobStatusHandler(jobs JobStatusSource) *JobStatusHandler {
+	return &JobStatusHandler{jobs: jobs}
+}
+
+// GetJobs handles GET /jobs and returns last-run status, duration and error
+// for every registered job.
+func (h *JobStatusHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, map[string]interface{}{"jobs": h.jobs.Statuses()})
+}
@@ -1 +1,75 @@
 package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// UserIDHeader carries the authenticated user's ID, set by the API gateway.
+const UserIDHeader = "X-User-ID"
+
+// ClientIDHeader carries the authenticated API client's ID, set by the API
+// gateway for partner requests.
+const ClientIDHeader = "X-Client-ID"
+
+type userIDKey struct{}
+
+type clientIDKey struct{}
+
+// Identify stores the caller's user ID from the X-User-ID header and API
+// client ID from the X-Client-ID header in the request context. Requests
+// without them pass through anonymously.
+func Identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := strings.TrimSpace(r.Header.Get(UserIDHeader)); id != "" {
+			r = r.WithContext(WithUserID(r.Context(), id))
+		}
+		if id := strings.TrimSpace(r.Header.Get(ClientIDHeader)); id != "" {
+			r = r.WithContext(WithClientID(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithUserID returns a context carrying the user ID.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFrom returns the user ID stored by Identify, or "".
+func UserIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// requireUser returns the caller's user ID or writes a 401 response.
+func requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := UserIDFrom(r.Context())
+	if id == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing "+UserIDHeader+" header")
+		return "", false
+	}
+	return id, true
+}
+
+// WithClientID returns a context carrying the API client ID.
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// ClientIDFrom returns the API client ID stored by Identify, or "".
+func ClientIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDKey{}).(string)
+	return id
+}
+
+// requireClient returns the caller's API client ID or writes a 401 response.
+func requireClient(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := ClientIDFrom(r.Context())
+	if id == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing "+ClientIDHeader+" header")
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// PreferencesHandler serves the caller's notification preferences.
+type PreferencesHandler struct {
+	prefs *usecase.PreferencesManager
+}
+
+// NewPreferencesHandler creates a new PreferencesHandler.
+func NewPreferencesHandler(prefs *usecase.PreferencesManager) *PreferencesHandler {
+	return &PreferencesHandler{prefs: prefs}
+}
+
+// GetPreferences handles GET /me/preferences.
+func (h *PreferencesHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.prefs.Get(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+	writeData(w, http.StatusOK, p)
+}
+
+// PutPreferences handles PUT /me/preferences and replaces the preferences.
+func (h *PreferencesHandler) PutPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var p domain.NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	saved, err := h.prefs.Update(r.Context(), userID, &p)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+	writeData(w, http.StatusOK, saved)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+const (
+	defaultHistoryDays = 90
+	maxHistoryDays     = 365
+)
+
+// PriceHistoryHandler serves the product price chart endpoint.
+type PriceHistoryHandler struct {
+	tracker *usecase.PriceTracker
+}
+
+// NewPriceHistoryHandler creates a new PriceHistoryHandler.
+func NewPriceHistoryHandler(tracker *usecase.PriceTracker) *PriceHistoryHandler {
+	return &PriceHistoryHandler{tracker: tracker}
+}
+
+// GetPriceHistory handles GET /products/{id}/price-history?days=N and returns
+// min/max/current and the series in USD and ETB.
+func (h *PriceHistoryHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "missing product id")
+		return
+	}
+
+	days := defaultHistoryDays
+	if s := strings.TrimSpace(r.URL.Query().Get("days")); s != "" {
+		d, err := strconv.Atoi(s)
+		if err != nil || d <= 0 || d > maxHistoryDays {
+			writeError(w, http.StatusBadRequest, "INVALID_INPUT", "days must be between 1 and 365")
+			return
+		}
+		days = d
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	history, err := h.tracker.History(r.Context(), id, since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+
+	writeData(w, http.StatusOK, history)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+const (
+	defaultHorizonDays = 14
+	maxHorizonDays     = 90
+)
+
+// ProductHandler serves the product detail endpoint.
+type ProductHandler struct {
+	uc *usecase.GetProductUseCase
+}
+
+// NewProductHandler creates a new ProductHandler.
+func NewProductHandler(uc *usecase.GetProductUseCase) *ProductHandler {
+	return &ProductHandler{uc: uc}
+}
+
+// GetProduct handles GET /products/{id}?horizon_days=N and returns the enriched
+// product with a prediction of whether its ETB price drops within N days.
+func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "missing product id")
+		return
+	}
+
+	horizon := defaultHorizonDays
+	if s := strings.TrimSpace(r.URL.Query().Get("horizon_days")); s != "" {
+		d, err := strconv.Atoi(s)
+		if err != nil || d <= 0 || d > maxHorizonDays {
+			writeError(w, http.StatusBadRequest, "INVALID_INPUT", "horizon_days must be between 1 and 90")
+			return
+		}
+		horizon = d
+	}
+
+	p, err := h.uc.GetProduct(r.Context(), id, usecase.ProductOptions{HorizonDays: horizon})
+	if errors.Is(err, domain.ErrProductNotFound) {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+
+	writeData(w, http.StatusOK, p)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// ResolveHandler turns pasted marketplace links into products.
+type ResolveHandler struct {
+	resolver *usecase.ProductURLResolver
+}
+
+// NewResolveHandler creates a new ResolveHandler.
+func NewResolveHandler(resolver *usecase.ProductURLResolver) *ResolveHandler {
+	return &ResolveHandler{resolver: resolver}
+}
+
+type resolvePayload struct {
+	URL string `json:"url"`
+}
+
+// Resolve handles POST /products/resolve with {"url": "..."}.
+func (h *ResolveHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	var payload resolvePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	resolved, err := h.resolver.Resolve(r.Context(), payload.URL)
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case errors.Is(err, domain.ErrProductNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+	default:
+		writeData(w, http.StatusOK, resolved)
+	}
+}
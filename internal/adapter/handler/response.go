@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeData writes a successful envelope response with the given status.
+func writeData(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, envelope{Data: data, Error: nil})
+}
+
+// writeError writes an error envelope using the same shape as the search endpoint.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, envelope{Data: nil, Error: map[string]interface{}{
+		"code":    code,
+		"message": message,
+	}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
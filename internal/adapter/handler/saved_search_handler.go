@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// SavedSearchHandler serves the caller's saved searches.
+type SavedSearchHandler struct {
+	searches *usecase.SavedSearchManager
+}
+
+// NewSavedSearchHandler creates a new SavedSearchHandler.
+func NewSavedSearchHandler(searches *usecase.SavedSearchManager) *SavedSearchHandler {
+	return &SavedSearchHandler{searches: searches}
+}
+
+type savedSearchPayload struct {
+	Query       string  `json:"query"`
+	MaxPriceETB float64 `json:"maxPriceEtb"`
+}
+
+// CreateSavedSearch handles POST /me/saved-searches.
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var payload savedSearchPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	s, err := h.searches.Create(r.Context(), userID, &domain.SavedSearch{Query: payload.Query, MaxPriceETB: payload.MaxPriceETB})
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	writeData(w, http.StatusCreated, s)
+}
+
+// ListSavedSearches handles GET /me/saved-searches.
+func (h *SavedSearchHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	searches, err := h.searches.List(r.Context(), userID)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	if searches == nil {
+		searches = []*domain.SavedSearch{}
+	}
+	writeData(w, http.StatusOK, map[string]interface{}{"savedSearches": searches})
+}
+
+// DeleteSavedSearch handles DELETE /me/saved-searches/{id}.
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	if err := h.searches.Delete(r.Context(), userID, strings.TrimSpace(r.PathValue("id"))); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]string{"status": "Saved search deleted"})
+}
+
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case errors.Is(err, domain.ErrSavedSearchNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+	}
+}
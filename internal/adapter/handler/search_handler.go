@@ -35,7 +35,20 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		return
 	}
 
-	data, err := h.uc.Search(c.Request.Context(), q)
+	var opts usecase.SearchOptions
+	if s := strings.TrimSpace(c.Query("arrives_before")); s != "" {
+		t, err := usecase.ParseDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, envelope{Data: nil, Error: map[string]interface{}{
+				"code":    "INVALID_INPUT",
+				"message": "arrives_before must be a YYYY-MM-DD date or RFC 3339 timestamp",
+			}})
+			return
+		}
+		opts.ArrivesBefore = t
+	}
+
+	data, err := h.uc.Search(c.Request.Context(), q, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, envelope{Data: nil, Error: map[string]interface{}{
 			"code":    "INTERNAL_SERVER_ERROR",
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// SMSSecretHeader carries the shared secret of inbound SMS webhook calls.
+// Providers that cannot set headers may pass it as the "token" query parameter.
+const SMSSecretHeader = "X-SMS-Webhook-Secret"
+
+// SMSHandler serves the inbound SMS webhook.
+type SMSHandler struct {
+	assistant *usecase.SMSAssistant
+	secret    string
+}
+
+// NewSMSHandler creates a new SMSHandler.
+func NewSMSHandler(assistant *usecase.SMSAssistant, secret string) *SMSHandler {
+	return &SMSHandler{assistant: assistant, secret: secret}
+}
+
+type inboundSMS struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// Inbound handles POST /sms/inbound. It accepts a JSON body or form fields
+// id, from, to and text. Reply failures are logged and acknowledged so the
+// provider does not redeliver the message; a redelivery with the same id is
+// acknowledged without another reply.
+func (h *SMSHandler) Inbound(w http.ResponseWriter, r *http.Request) {
+	given := r.Header.Get(SMSSecretHeader)
+	if given == "" {
+		given = r.URL.Query().Get("token")
+	}
+	if h.secret == "" || subtle.ConstantTimeCompare([]byte(given), []byte(h.secret)) != 1 {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid webhook secret")
+		return
+	}
+
+	var in inboundSMS
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid form body")
+			return
+		}
+		in = inboundSMS{ID: r.PostForm.Get("id"), From: r.PostForm.Get("from"), To: r.PostForm.Get("to"), Text: r.PostForm.Get("text")}
+	}
+	if in.From == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "from is required")
+		return
+	}
+
+	msg := domain.SMSIncoming{MessageID: in.ID, From: in.From, To: in.To, Text: in.Text}
+	if err := h.assistant.HandleIncoming(r.Context(), msg); err != nil {
+		log.Printf("sms %s from %s: %v", in.ID, in.From, err)
+	}
+	writeData(w, http.StatusOK, map[string]string{"status": "received"})
+}
+
+// ShortLinkResolver looks up the URL behind a short link code.
+type ShortLinkResolver interface {
+	Resolve(ctx context.Context, code string) (string, error)
+}
+
+// ShortLinkHandler redirects short links sent in SMS replies.
+type ShortLinkHandler struct {
+	links ShortLinkResolver
+}
+
+// NewShortLinkHandler creates a new ShortLinkHandler.
+func NewShortLinkHandler(links ShortLinkResolver) *ShortLinkHandler {
+	return &ShortLinkHandler{links: links}
+}
+
+// Redirect handles GET /s/{code}.
+func (h *ShortLinkHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	target, err := h.links.Resolve(r.Context(), r.PathValue("code"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+	if target == "" {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "link not found or expired")
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
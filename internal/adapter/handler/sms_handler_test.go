@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopally-ai/internal/adapter/gateway"
+	"github.com/shopally-ai/internal/adapter/repository"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+func TestSMSInbound(t *testing.T) {
+	// Stand-in for the outbound SMS provider
+	var replies []map[string]string
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		replies = append(replies, body)
+		_, _ = w.Write([]byte(`{"id":"out-1"}`))
+	}))
+	defer provider.Close()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	alerts := repository.NewMockAlertRepository()
+	search := usecase.NewSearchProductsUseCase(gateway.NewMockAlibabaGateway(), gateway.NewMockLLMGateway(), nil, nil)
+	assistant := usecase.NewSMSAssistant(search, usecase.NewAlertManager(alerts, nil, nil, 0, nil),
+		gateway.NewHTTPSMSGateway(provider.URL, "", "8055", provider.Client()),
+		gateway.NewRedisSMSSessionStore(rdb, "sa:", 0), nil)
+	h := NewSMSHandler(assistant, "s3cret")
+
+	inbound := func(query string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/sms/inbound?"+query, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.Inbound(rr, req)
+		return rr
+	}
+
+	if rr := inbound("token=wrong", url.Values{"from": {"+251911000000"}, "text": {"phone"}}); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("bad secret: status %d", rr.Code)
+	}
+	if len(replies) != 0 {
+		t.Fatalf("replied without a valid secret: %v", replies)
+	}
+
+	if rr := inbound("token=s3cret", url.Values{"id": {"in-1"}, "from": {"+251911000000"}, "to": {"8055"}, "text": {"smartphone"}}); rr.Code != http.StatusOK {
+		t.Fatalf("search: status %d", rr.Code)
+	}
+	if len(replies) != 1 || replies[0]["to"] != "+251911000000" || replies[0]["from"] != "8055" ||
+		!strings.Contains(replies[0]["message"], "1) Mock Smartphone - High Qua.. 4999 ETB") {
+		t.Fatalf("unexpected search reply: %v", replies)
+	}
+
+	// A redelivery of the same message is acknowledged without a second reply
+	if rr := inbound("token=s3cret", url.Values{"id": {"in-1"}, "from": {"+251911000000"}, "to": {"8055"}, "text": {"smartphone"}}); rr.Code != http.StatusOK {
+		t.Fatalf("redelivered search: status %d", rr.Code)
+	}
+	if len(replies) != 1 {
+		t.Fatalf("replied to a redelivery: %v", replies)
+	}
+
+	if rr := inbound("token=s3cret", url.Values{"from": {"+251911000000"}, "text": {"1 4000"}}); rr.Code != http.StatusOK {
+		t.Fatalf("alert: status %d", rr.Code)
+	}
+	active, _ := alerts.ListActiveAlerts()
+	if len(active) != 1 || active[0].ProductID != "MOCK-123" || active[0].TargetPrice != 4000 || active[0].UserID != "sms:+251911000000" {
+		t.Fatalf("unexpected alerts: %+v", active)
+	}
+	if !strings.Contains(replies[1]["message"], "drops to 4000 ETB") {
+		t.Fatalf("confirmation = %q", replies[1]["message"])
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// TelegramSecretHeader carries the secret token registered with setWebhook.
+const TelegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramHandler serves the Telegram bot webhook and account linking.
+type TelegramHandler struct {
+	bot    *usecase.TelegramBot
+	secret string
+}
+
+// NewTelegramHandler creates a new TelegramHandler. Webhook calls must carry
+// secret in the X-Telegram-Bot-Api-Secret-Token header.
+func NewTelegramHandler(bot *usecase.TelegramBot, secret string) *TelegramHandler {
+	return &TelegramHandler{bot: bot, secret: secret}
+}
+
+// telegramUpdate is the part of a Bot API Update the bot reads.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From *struct {
+			Username string `json:"username"`
+		} `json:"from"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// Webhook handles POST /telegram/webhook. Telegram retries any non-2xx
+// response, so processing errors are logged and acknowledged.
+func (h *TelegramHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if h.secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get(TelegramSecretHeader)), []byte(h.secret)) != 1 {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid webhook secret")
+		return
+	}
+	var u telegramUpdate
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid update")
+		return
+	}
+	if u.Message != nil && u.Message.Text != "" {
+		in := domain.TelegramIncoming{UpdateID: u.UpdateID, ChatID: u.Message.Chat.ID, Text: u.Message.Text}
+		if u.Message.From != nil {
+			in.Username = u.Message.From.Username
+		}
+		if err := h.bot.HandleMessage(r.Context(), in); err != nil {
+			log.Printf("telegram update %d: %v", u.UpdateID, err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// CreateLinkCode handles POST /me/telegram/link-code and returns a one-time
+// code the caller sends to the bot.
+func (h *TelegramHandler) CreateLinkCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	code, err := h.bot.CreateLinkCode(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+	writeData(w, http.StatusCreated, code)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// WatchlistHandler serves the caller's watchlist.
+type WatchlistHandler struct {
+	watchlist *usecase.WatchlistManager
+}
+
+// NewWatchlistHandler creates a new WatchlistHandler.
+func NewWatchlistHandler(watchlist *usecase.WatchlistManager) *WatchlistHandler {
+	return &WatchlistHandler{watchlist: watchlist}
+}
+
+type watchlistPayload struct {
+	ProductID string   `json:"productId"`
+	Note      string   `json:"note"`
+	Tags      []string `json:"tags"`
+}
+
+// promotePayload configures the alert a watchlist item becomes. An empty
+// body creates an alert for any price drop.
+type promotePayload struct {
+	TargetPrice     float64                `json:"targetPrice"`
+	Currency        string                 `json:"currency"`
+	Condition       *domain.AlertCondition `json:"condition"`
+	ExpiresAt       *time.Time             `json:"expiresAt"`
+	CooldownMinutes int                    `json:"cooldownMinutes"`
+	Rearm           bool                   `json:"rearm"`
+}
+
+// ListWatchlist handles GET /me/watchlist?tag=phones.
+func (h *WatchlistHandler) ListWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	entries, err := h.watchlist.List(r.Context(), userID, r.URL.Query().Get("tag"))
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]interface{}{"items": entries})
+}
+
+// AddToWatchlist handles POST /me/watchlist. Adding a product that is
+// already on the watchlist updates its note and tags.
+func (h *WatchlistHandler) AddToWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var payload watchlistPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	item, added, err := h.watchlist.Add(r.Context(), userID, domain.WatchlistItem{ProductID: payload.ProductID, Note: payload.Note, Tags: payload.Tags})
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	writeData(w, status, item)
+}
+
+// RemoveFromWatchlist handles DELETE /me/watchlist/{productId}.
+func (h *WatchlistHandler) RemoveFromWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	if err := h.watchlist.Remove(r.Context(), userID, strings.TrimSpace(r.PathValue("productId"))); err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]string{"status": "Removed from watchlist"})
+}
+
+// PromoteToAlert handles POST /me/watchlist/{productId}/alert.
+func (h *WatchlistHandler) PromoteToAlert(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var payload promotePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	alert := &domain.Alert{
+		ClientID:        ClientIDFrom(r.Context()),
+		TargetPrice:     payload.TargetPrice,
+		Currency:        payload.Currency,
+		ExpiresAt:       payload.ExpiresAt,
+		CooldownMinutes: payload.CooldownMinutes,
+		Rearm:           payload.Rearm,
+	}
+	if payload.Condition != nil {
+		alert.Condition = *payload.Condition
+	}
+	created, err := h.watchlist.Promote(r.Context(), userID, strings.TrimSpace(r.PathValue("productId")), alert)
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	writeData(w, http.StatusCreated, created)
+}
+
+func writeWatchlistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case errors.Is(err, domain.ErrWatchlistItemNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case errors.Is(err, domain.ErrAlertExists), errors.Is(err, domain.ErrAlertLimit), errors.Is(err, domain.ErrWatchlistConflict):
+		writeError(w, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+)
+
+// WebhookHandler serves the calling API client's webhooks and delivery log.
+type WebhookHandler struct {
+	webhooks *usecase.WebhookManager
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(webhooks *usecase.WebhookManager) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+type webhookPayload struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// createdWebhook shows the signing secret, which is not returned again.
+type createdWebhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateWebhook handles POST /webhooks.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := requireClient(w, r)
+	if !ok {
+		return
+	}
+	var payload webhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+	wh, err := h.webhooks.Create(r.Context(), clientID, &domain.Webhook{URL: payload.URL, Events: payload.Events})
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeData(w, http.StatusCreated, createdWebhook{ID: wh.ID, URL: wh.URL, Events: wh.Events, Secret: wh.Secret, CreatedAt: wh.CreatedAt})
+}
+
+// ListWebhooks handles GET /webhooks.
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := requireClient(w, r)
+	if !ok {
+		return
+	}
+	webhooks, err := h.webhooks.List(r.Context(), clientID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*domain.Webhook{}
+	}
+	writeData(w, http.StatusOK, map[string]interface{}{"webhooks": webhooks})
+}
+
+// DeleteWebhook handles DELETE /webhooks/{id}.
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := requireClient(w, r)
+	if !ok {
+		return
+	}
+	if err := h.webhooks.Delete(r.Context(), clientID, strings.TrimSpace(r.PathValue("id"))); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]string{"status": "Webhook deleted"})
+}
+
+// ListDeliveries handles GET /webhooks/deliveries?status=failed&limit=20.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := requireClient(w, r)
+	if !ok {
+		return
+	}
+	limit := 0
+	if s := strings.TrimSpace(r.URL.Query().Get("limit")); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	status := domain.WebhookDeliveryStatus(strings.TrimSpace(r.URL.Query().Get("status")))
+	deliveries, err := h.webhooks.Deliveries(r.Context(), clientID, status, limit)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+	writeData(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+// ReplayDelivery handles POST /webhooks/deliveries/{id}/replay.
+func (h *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := requireClient(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.webhooks.Replay(r.Context(), clientID, strings.TrimSpace(r.PathValue("id")))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	writeData(w, http.StatusAccepted, d)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		writeError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+	}
+}
@@ -9,7 +9,21 @@ import (
 
 // Deps contains all handlers that the router should mount.
 type Deps struct {
-	FX *apphandler.FXHandler
+	FX           *apphandler.FXHandler
+	Products     *apphandler.ProductHandler
+	PriceHistory *apphandler.PriceHistoryHandler
+	Alerts       *apphandler.AlertHandler
+	Jobs         *apphandler.JobStatusHandler
+	Devices      *apphandler.DeviceHandler
+	Preferences  *apphandler.PreferencesHandler
+	Telegram     *apphandler.TelegramHandler
+	SMS          *apphandler.SMSHandler
+	ShortLinks   *apphandler.ShortLinkHandler
+	Searches     *apphandler.SavedSearchHandler
+	Webhooks     *apphandler.WebhookHandler
+	Watchlist    *apphandler.WatchlistHandler
+	Cart         *apphandler.CartHandler
+	Resolve      *apphandler.ResolveHandler
 }
 
 // Options control router behavior like base path and middlewares.
@@ -32,6 +46,20 @@ func Build(d Deps, opts ...Options) http.Handler {
 
 	// Mount feature routes
 	mountFX(mux, d.FX, base)
+	mountProducts(mux, d.Products, base)
+	mountPriceHistory(mux, d.PriceHistory, base)
+	mountAlerts(mux, d.Alerts, base)
+	mountJobs(mux, d.Jobs, base)
+	mountDevices(mux, d.Devices, base)
+	mountPreferences(mux, d.Preferences, base)
+	mountTelegram(mux, d.Telegram, base)
+	mountSMS(mux, d.SMS, base)
+	mountShortLinks(mux, d.ShortLinks, base)
+	mountSavedSearches(mux, d.Searches, base)
+	mountWebhooks(mux, d.Webhooks, base)
+	mountWatchlist(mux, d.Watchlist, base)
+	mountCart(mux, d.Cart, base)
+	mountResolve(mux, d.Resolve, base)
 
 	// Wrap with middlewares (outermost first)
 	var h http.Handler = mux
@@ -51,3 +79,117 @@ func mountFX(mux *http.ServeMux, fx *apphandler.FXHandler, base string) {
 	}
 	mux.HandleFunc(path, fx.GetFX)
 }
+
+func mountProducts(mux *http.ServeMux, h *apphandler.ProductHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/products/{id}", h.GetProduct)
+}
+
+func mountPriceHistory(mux *http.ServeMux, h *apphandler.PriceHistoryHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/products/{id}/price-history", h.GetPriceHistory)
+}
+
+func mountAlerts(mux *http.ServeMux, h *apphandler.AlertHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("POST "+base+"/alerts", h.CreateAlertHandler)
+	mux.HandleFunc("GET "+base+"/alerts/{id}", h.GetAlertHandler)
+	mux.HandleFunc("DELETE "+base+"/alerts/{id}", h.DeleteAlertHandler)
+}
+
+func mountJobs(mux *http.ServeMux, h *apphandler.JobStatusHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/jobs", h.GetJobs)
+}
+
+func mountDevices(mux *http.ServeMux, h *apphandler.DeviceHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/devices", h.ListDevices)
+	mux.HandleFunc("POST "+base+"/devices", h.RegisterDevice)
+	mux.HandleFunc("PUT "+base+"/devices/{token}", h.RefreshDevice)
+	mux.HandleFunc("DELETE "+base+"/devices/{token}", h.UnregisterDevice)
+}
+
+func mountPreferences(mux *http.ServeMux, h *apphandler.PreferencesHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/me/preferences", h.GetPreferences)
+	mux.HandleFunc("PUT "+base+"/me/preferences", h.PutPreferences)
+}
+
+func mountTelegram(mux *http.ServeMux, h *apphandler.TelegramHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("POST "+base+"/telegram/webhook", h.Webhook)
+	mux.HandleFunc("POST "+base+"/me/telegram/link-code", h.CreateLinkCode)
+}
+
+func mountSMS(mux *http.ServeMux, h *apphandler.SMSHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("POST "+base+"/sms/inbound", h.Inbound)
+}
+
+func mountShortLinks(mux *http.ServeMux, h *apphandler.ShortLinkHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/s/{code}", h.Redirect)
+}
+
+func mountSavedSearches(mux *http.ServeMux, h *apphandler.SavedSearchHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/me/saved-searches", h.ListSavedSearches)
+	mux.HandleFunc("POST "+base+"/me/saved-searches", h.CreateSavedSearch)
+	mux.HandleFunc("DELETE "+base+"/me/saved-searches/{id}", h.DeleteSavedSearch)
+}
+
+func mountWebhooks(mux *http.ServeMux, h *apphandler.WebhookHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/webhooks", h.ListWebhooks)
+	mux.HandleFunc("POST "+base+"/webhooks", h.CreateWebhook)
+	mux.HandleFunc("DELETE "+base+"/webhooks/{id}", h.DeleteWebhook)
+	mux.HandleFunc("GET "+base+"/webhooks/deliveries", h.ListDeliveries)
+	mux.HandleFunc("POST "+base+"/webhooks/deliveries/{id}/replay", h.ReplayDelivery)
+}
+
+func mountWatchlist(mux *http.ServeMux, h *apphandler.WatchlistHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("GET "+base+"/me/watchlist", h.ListWatchlist)
+	mux.HandleFunc("POST "+base+"/me/watchlist", h.AddToWatchlist)
+	mux.HandleFunc("DELETE "+base+"/me/watchlist/{productId}", h.RemoveFromWatchlist)
+	mux.HandleFunc("POST "+base+"/me/watchlist/{productId}/alert", h.PromoteToAlert)
+}
+
+func mountCart(mux *http.ServeMux, h *apphandler.CartHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("POST "+base+"/cart/estimate", h.Estimate)
+}
+
+func mountResolve(mux *http.ServeMux, h *apphandler.ResolveHandler, base string) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc("POST "+base+"/products/resolve", h.Resolve)
+}
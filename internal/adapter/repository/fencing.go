@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/shopally-ai/internal/platform"
+	"github.com/shopally-ai/pkg/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fenceField stores the highest fencing token a document was written under.
+const fenceField = "fence_token"
+
+// fenced restricts filter to documents not yet written under a newer fencing
+// token than ctx's, so a leader that lost its lease cannot overwrite what its
+// successor wrote. It reports the token to store with the write; writes
+// without a token are not restricted.
+func fenced(ctx context.Context, filter bson.M) (bson.M, int64, bool) {
+	token, ok := platform.FencingToken(ctx)
+	if !ok {
+		return filter, 0, false
+	}
+	filter[fenceField] = bson.M{"$not": bson.M{"$gt": token}}
+	return filter, token, true
+}
+
+// fencedMiss explains a fenced write that matched nothing: the document
+// exists under a newer token, or it does not exist and found is false.
+func fencedMiss(ctx context.Context, coll *mongo.Collection, id string) (found bool, err error) {
+	n, err := coll.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, domain.ErrStaleFencingToken
+	}
+	return false, nil
+}
+
+// toDocument marshals v into a document that fields can be added to.
+func toDocument(v interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -1,22 +1,183 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/shopally-ai/internal/platform"
 	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/google/uuid"
 )
 
+// mongoTimeout bounds repository calls whose port methods carry no context.
+const mongoTimeout = 5 * time.Second
+
+// MongoAlertRepository stores alerts in a MongoDB collection.
+type MongoAlertRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoAlertRepository(db *mongo.Database, collection string) *MongoAlertRepository {
+	if collection == "" {
+		collection = "alerts"
+	}
+	return &MongoAlertRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the active alert lookup indexes. The partial unique
+// index allows one active alert per user and product, so concurrent creates
+// cannot both pass the use case's duplicate check.
+func (r *MongoAlertRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "product_id", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"is_active": true}),
+		},
+		{Keys: bson.D{{Key: "is_active", Value: 1}, {Key: "product_id", Value: 1}}},
+	})
+	return err
+}
+
+// CreateAlert stores the alert, or returns domain.ErrAlertExists when the
+// user already has an active alert on the product.
+func (r *MongoAlertRepository) CreateAlert(alert *domain.Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	alert.ID = uuid.New().String()
+	_, err := r.coll.InsertOne(ctx, alert)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: user %s already watches product %s", domain.ErrAlertExists, alert.UserID, alert.ProductID)
+	}
+	return err
+}
+
+func (r *MongoAlertRepository) GetAlert(alertID string) (*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	var alert domain.Alert
+	err := r.coll.FindOne(ctx, bson.M{"_id": alertID}).Decode(&alert)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("alert with ID %s not found", alertID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *MongoAlertRepository) DeleteAlert(alertID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": alertID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("alert with ID %s not found", alertID)
+	}
+	return nil
+}
+
+func (r *MongoAlertRepository) ListActiveAlerts() ([]*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	cur, err := r.coll.Find(ctx, bson.M{"is_active": true})
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.Alert
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *MongoAlertRepository) ListActiveUserAlerts(userID string) ([]*domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	cur, err := r.coll.Find(ctx, bson.M{"user_id": userID, "is_active": true})
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.Alert
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateAlert replaces the alert. Writes carrying a fencing token are
+// rejected with domain.ErrStaleFencingToken once a newer token wrote it.
+func (r *MongoAlertRepository) UpdateAlert(ctx context.Context, alert *domain.Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, mongoTimeout)
+	defer cancel()
+	doc, err := toDocument(alert)
+	if err != nil {
+		return err
+	}
+	filter, token, isFenced := fenced(ctx, bson.M{"_id": alert.ID})
+	var res *mongo.UpdateResult
+	if isFenced {
+		doc[fenceField] = token
+		res, err = r.coll.ReplaceOne(ctx, filter, doc)
+	} else {
+		// Keep the token of earlier fenced writes, or a stale leader could
+		// overwrite the alert again after any API update
+		res, err = r.coll.UpdateOne(ctx, filter, mongo.Pipeline{{{Key: "$replaceWith", Value: bson.M{
+			"$mergeObjects": bson.A{bson.M{"$literal": doc}, bson.M{fenceField: "$" + fenceField}},
+		}}}})
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if found, err := fencedMiss(ctx, r.coll, alert.ID); found || err != nil {
+			return err
+		}
+		return fmt.Errorf("alert with ID %s not found", alert.ID)
+	}
+	return nil
+}
+
+var _ usecase.AlertRepository = (*MongoAlertRepository)(nil)
+
 type MockAlertRepository struct {
 	alerts sync.Map // map[string]*domain.Alert
+
+	mu     sync.Mutex
+	fences map[string]int64
 }
 
 func NewMockAlertRepository() *MockAlertRepository {
 	return &MockAlertRepository{}
 }
+
+// CreateAlert enforces one active alert per user and product like the
+// Mongo repository's unique index.
 func (r *MockAlertRepository) CreateAlert(alert *domain.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if alert.IsActive {
+		exists := false
+		r.alerts.Range(func(_, value interface{}) bool {
+			a := value.(*domain.Alert)
+			exists = a.IsActive && a.UserID == alert.UserID && a.ProductID == alert.ProductID
+			return !exists
+		})
+		if exists {
+			return fmt.Errorf("%w: user %s already watches product %s", domain.ErrAlertExists, alert.UserID, alert.ProductID)
+		}
+	}
 	alert.ID = uuid.New().String()
 	r.alerts.Store(alert.ID, alert)
 	return nil
@@ -36,3 +197,40 @@ func (r *MockAlertRepository) DeleteAlert(alertID string) error {
 	r.alerts.Delete(alertID)
 	return nil
 }
+func (r *MockAlertRepository) ListActiveAlerts() ([]*domain.Alert, error) {
+	var out []*domain.Alert
+	r.alerts.Range(func(_, value interface{}) bool {
+		if alert, ok := value.(*domain.Alert); ok && alert.IsActive {
+			out = append(out, alert)
+		}
+		return true
+	})
+	return out, nil
+}
+func (r *MockAlertRepository) ListActiveUserAlerts(userID string) ([]*domain.Alert, error) {
+	var out []*domain.Alert
+	r.alerts.Range(func(_, value interface{}) bool {
+		if alert, ok := value.(*domain.Alert); ok && alert.IsActive && alert.UserID == userID {
+			out = append(out, alert)
+		}
+		return true
+	})
+	return out, nil
+}
+func (r *MockAlertRepository) UpdateAlert(ctx context.Context, alert *domain.Alert) error {
+	if _, ok := r.alerts.Load(alert.ID); !ok {
+		return fmt.Errorf("alert with ID %s not found", alert.ID)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	highest, err := platform.AdmitFencingToken(ctx, r.fences[alert.ID])
+	if err != nil {
+		return err
+	}
+	if r.fences == nil {
+		r.fences = map[string]int64{}
+	}
+	r.fences[alert.ID] = highest
+	r.alerts.Store(alert.ID, alert)
+	return nil
+}
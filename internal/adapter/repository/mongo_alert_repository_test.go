@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/shopally-ai/internal/mocks"
+	"github.com/shopally-ai/internal/platform"
 	"github.com/shopally-ai/pkg/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -131,3 +134,46 @@ func TestMockAlertRepository(t *testing.T) {
 		}
 	})
 }
+
+func TestMockAlertRepository_RejectsStaleFencingTokens(t *testing.T) {
+	repo := NewMockAlertRepository()
+	alert := &domain.Alert{UserID: "user-123", ProductID: "product-abc", TargetPrice: 500, IsActive: true}
+	if err := repo.CreateAlert(alert); err != nil {
+		t.Fatalf("CreateAlert failed with error: %v", err)
+	}
+
+	newLeader := platform.WithFencingToken(context.Background(), 2)
+	if err := repo.UpdateAlert(newLeader, alert); err != nil {
+		t.Fatalf("UpdateAlert under the current token failed: %v", err)
+	}
+	staleLeader := platform.WithFencingToken(context.Background(), 1)
+	if err := repo.UpdateAlert(staleLeader, alert); !errors.Is(err, domain.ErrStaleFencingToken) {
+		t.Fatalf("UpdateAlert under a stale token = %v, want ErrStaleFencingToken", err)
+	}
+	if err := repo.UpdateAlert(context.Background(), alert); err != nil {
+		t.Fatalf("UpdateAlert without a token failed: %v", err)
+	}
+	if err := repo.UpdateAlert(staleLeader, alert); !errors.Is(err, domain.ErrStaleFencingToken) {
+		t.Fatalf("an API update must not reset the fence, got %v", err)
+	}
+}
+
+func TestMockAlertRepository_OneActiveAlertPerProduct(t *testing.T) {
+	repo := NewMockAlertRepository()
+	first := &domain.Alert{UserID: "user-123", ProductID: "product-abc", TargetPrice: 500, IsActive: true}
+	if err := repo.CreateAlert(first); err != nil {
+		t.Fatalf("CreateAlert failed with error: %v", err)
+	}
+	dup := &domain.Alert{UserID: "user-123", ProductID: "product-abc", TargetPrice: 400, IsActive: true}
+	if err := repo.CreateAlert(dup); !errors.Is(err, domain.ErrAlertExists) {
+		t.Fatalf("duplicate CreateAlert = %v, want ErrAlertExists", err)
+	}
+
+	first.IsActive = false
+	if err := repo.UpdateAlert(context.Background(), first); err != nil {
+		t.Fatalf("UpdateAlert failed with error: %v", err)
+	}
+	if err := repo.CreateAlert(dup); err != nil {
+		t.Fatalf("CreateAlert after the first alert ended failed: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDeviceRepository stores push devices in a MongoDB collection keyed by token.
+type MongoDeviceRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoDeviceRepository(db *mongo.Database, collection string) *MongoDeviceRepository {
+	if collection == "" {
+		collection = "devices"
+	}
+	return &MongoDeviceRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the indexes used by device lookups and pruning.
+func (r *MongoDeviceRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "last_seen_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoDeviceRepository) UpsertDevice(ctx context.Context, d *domain.Device) error {
+	update := bson.M{
+		"$set": bson.M{
+			"user_id":      d.UserID,
+			"platform":     d.Platform,
+			"app_version":  d.AppVersion,
+			"locale":       d.Locale,
+			"last_seen_at": d.LastSeenAt,
+		},
+		"$setOnInsert": bson.M{"created_at": d.CreatedAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return r.coll.FindOneAndUpdate(ctx, bson.M{"_id": d.Token}, update, opts).Decode(d)
+}
+
+func (r *MongoDeviceRepository) DeleteDevice(ctx context.Context, userID, token string) error {
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": token, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrDeviceNotFound
+	}
+	return nil
+}
+
+func (r *MongoDeviceRepository) ListDevices(ctx context.Context, userID string) ([]*domain.Device, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}})
+	cur, err := r.coll.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := []*domain.Device{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *MongoDeviceRepository) DeleteTokens(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	_, err := r.coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": tokens}})
+	return err
+}
+
+func (r *MongoDeviceRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.coll.DeleteMany(ctx, bson.M{"last_seen_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+var _ usecase.DeviceRepository = (*MongoDeviceRepository)(nil)
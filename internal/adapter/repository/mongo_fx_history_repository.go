@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoFXHistoryRepository stores exchange rate observations in a MongoDB collection.
+type MongoFXHistoryRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoFXHistoryRepository(db *mongo.Database, collection string) *MongoFXHistoryRepository {
+	if collection == "" {
+		collection = "fx_rates"
+	}
+	return &MongoFXHistoryRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the index used by the history queries.
+func (r *MongoFXHistoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "from", Value: 1}, {Key: "to", Value: 1}, {Key: "recorded_at", Value: 1}},
+	})
+	return err
+}
+
+func (r *MongoFXHistoryRepository) RecordRate(ctx context.Context, snapshot *domain.FXRateSnapshot) error {
+	_, err := r.coll.InsertOne(ctx, snapshot)
+	return err
+}
+
+func (r *MongoFXHistoryRepository) ListRates(ctx context.Context, from, to string, since time.Time) ([]*domain.FXRateSnapshot, error) {
+	filter := bson.M{"from": from, "to": to, "recorded_at": bson.M{"$gte": since}}
+	opts := options.Find().SetSort(bson.D{{Key: "recorded_at", Value: 1}})
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.FXRateSnapshot
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _ usecase.FXHistoryRepository = (*MongoFXHistoryRepository)(nil)
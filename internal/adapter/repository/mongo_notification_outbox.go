@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoNotificationOutbox stores notification intents in a MongoDB collection.
+// Claims are a locked_until field set atomically, so several dispatchers can
+// drain the outbox without sending the same notification twice.
+type MongoNotificationOutbox struct {
+	coll *mongo.Collection
+}
+
+func NewMongoNotificationOutbox(db *mongo.Database, collection string) *MongoNotificationOutbox {
+	if collection == "" {
+		collection = "notifications"
+	}
+	return &MongoNotificationOutbox{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the idempotency and dispatch indexes.
+func (r *MongoNotificationOutbox) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "idempotency_key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "deliveries.sent_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoNotificationOutbox) Enqueue(ctx context.Context, n *domain.Notification) (bool, error) {
+	_, err := r.coll.InsertOne(ctx, n)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MongoNotificationOutbox) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Notification, error) {
+	filter := bson.M{
+		"status":          domain.NotificationPending,
+		"next_attempt_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var out []*domain.Notification
+	for len(out) < limit {
+		var n domain.Notification
+		err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&n)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, &n)
+	}
+	return out, nil
+}
+
+// Save rejects writes carrying a fencing token with
+// domain.ErrStaleFencingToken once a newer token wrote the notification.
+func (r *MongoNotificationOutbox) Save(ctx context.Context, n *domain.Notification) error {
+	set := bson.M{
+		"deliveries":      n.Deliveries,
+		"status":          n.Status,
+		"next_attempt_at": n.NextAttemptAt,
+		"updated_at":      n.UpdatedAt,
+	}
+	filter, token, isFenced := fenced(ctx, bson.M{"_id": n.ID})
+	if isFenced {
+		set[fenceField] = token
+	}
+	res, err := r.coll.UpdateOne(ctx, filter, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"locked_until": ""},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 && isFenced {
+		_, err = fencedMiss(ctx, r.coll, n.ID)
+	}
+	return err
+}
+
+func (r *MongoNotificationOutbox) CountSent(ctx context.Context, userID string, since time.Time) (int, error) {
+	n, err := r.coll.CountDocuments(ctx, bson.M{"user_id": userID, "deliveries.sent_at": bson.M{"$gte": since}})
+	return int(n), err
+}
+
+var _ usecase.NotificationOutbox = (*MongoNotificationOutbox)(nil)
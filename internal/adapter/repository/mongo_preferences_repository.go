@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoPreferencesRepository stores notification preferences keyed by user ID.
+type MongoPreferencesRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoPreferencesRepository(db *mongo.Database, collection string) *MongoPreferencesRepository {
+	if collection == "" {
+		collection = "notification_preferences"
+	}
+	return &MongoPreferencesRepository{coll: db.Collection(collection)}
+}
+
+func (r *MongoPreferencesRepository) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	var p domain.NotificationPreferences
+	err := r.coll.FindOne(ctx, bson.M{"_id": userID}).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *MongoPreferencesRepository) SavePreferences(ctx context.Context, p *domain.NotificationPreferences) error {
+	_, err := r.coll.ReplaceOne(ctx, bson.M{"_id": p.UserID}, p, options.Replace().SetUpsert(true))
+	return err
+}
+
+var _ usecase.PreferencesRepository = (*MongoPreferencesRepository)(nil)
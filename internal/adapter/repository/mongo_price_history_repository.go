@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoPriceHistoryRepository stores price snapshots in a MongoDB collection.
+type MongoPriceHistoryRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoPriceHistoryRepository(db *mongo.Database, collection string) *MongoPriceHistoryRepository {
+	if collection == "" {
+		collection = "price_snapshots"
+	}
+	return &MongoPriceHistoryRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the indexes used by the history queries.
+func (r *MongoPriceHistoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "recorded_at", Value: -1}}},
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "last_seen_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoPriceHistoryRepository) LatestSnapshot(ctx context.Context, productID string) (*domain.PriceSnapshot, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "recorded_at", Value: -1}})
+	var s domain.PriceSnapshot
+	err := r.coll.FindOne(ctx, bson.M{"product_id": productID}, opts).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *MongoPriceHistoryRepository) InsertSnapshot(ctx context.Context, snapshot *domain.PriceSnapshot) error {
+	_, err := r.coll.InsertOne(ctx, snapshot)
+	return err
+}
+
+func (r *MongoPriceHistoryRepository) TouchSnapshot(ctx context.Context, id string, seenAt time.Time) error {
+	_, err := r.coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_seen_at": seenAt}})
+	return err
+}
+
+func (r *MongoPriceHistoryRepository) ListSnapshots(ctx context.Context, productID string, since time.Time) ([]*domain.PriceSnapshot, error) {
+	filter := bson.M{"product_id": productID, "last_seen_at": bson.M{"$gte": since}}
+	opts := options.Find().SetSort(bson.D{{Key: "recorded_at", Value: 1}})
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.PriceSnapshot
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *MongoPriceHistoryRepository) ListSnapshotsFor(ctx context.Context, productIDs []string, since time.Time) (map[string][]*domain.PriceSnapshot, error) {
+	out := make(map[string][]*domain.PriceSnapshot, len(productIDs))
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+	filter := bson.M{"product_id": bson.M{"$in": productIDs}, "last_seen_at": bson.M{"$gte": since}}
+	opts := options.Find().SetSort(bson.D{{Key: "recorded_at", Value: 1}})
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*domain.PriceSnapshot
+	if err := cur.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+	for _, s := range snapshots {
+		out[s.ProductID] = append(out[s.ProductID], s)
+	}
+	return out, nil
+}
+
+var _ usecase.PriceHistoryRepository = (*MongoPriceHistoryRepository)(nil)
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoSavedSearchRepository stores saved searches in a MongoDB collection.
+type MongoSavedSearchRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoSavedSearchRepository(db *mongo.Database, collection string) *MongoSavedSearchRepository {
+	if collection == "" {
+		collection = "saved_searches"
+	}
+	return &MongoSavedSearchRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the index used to list a user's saved searches.
+func (r *MongoSavedSearchRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}})
+	return err
+}
+
+func (r *MongoSavedSearchRepository) CreateSavedSearch(ctx context.Context, s *domain.SavedSearch) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	_, err := r.coll.InsertOne(ctx, s)
+	return err
+}
+
+func (r *MongoSavedSearchRepository) ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	cur, err := r.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.SavedSearch
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *MongoSavedSearchRepository) DeleteSavedSearch(ctx context.Context, userID, id string) error {
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+func (r *MongoSavedSearchRepository) SaveRunResults(ctx context.Context, id string, productIDs []string, runAt time.Time) error {
+	_, err := r.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_result_ids": productIDs, "last_run_at": runAt}})
+	return err
+}
+
+var _ usecase.SavedSearchRepository = (*MongoSavedSearchRepository)(nil)
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoTelegramLinkRepository stores Telegram chat links keyed by user ID,
+// pending link codes in a sibling "<collection>_codes" collection and failed
+// link attempts per chat in "<collection>_attempts".
+type MongoTelegramLinkRepository struct {
+	links    *mongo.Collection
+	codes    *mongo.Collection
+	attempts *mongo.Collection
+}
+
+func NewMongoTelegramLinkRepository(db *mongo.Database, collection string) *MongoTelegramLinkRepository {
+	if collection == "" {
+		collection = "telegram_links"
+	}
+	return &MongoTelegramLinkRepository{
+		links:    db.Collection(collection),
+		codes:    db.Collection(collection + "_codes"),
+		attempts: db.Collection(collection + "_attempts"),
+	}
+}
+
+// EnsureIndexes makes chat IDs unique and lets MongoDB expire old codes and
+// attempt windows.
+func (r *MongoTelegramLinkRepository) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.links.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "chat_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+	expiry := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := r.codes.Indexes().CreateOne(ctx, expiry); err != nil {
+		return err
+	}
+	_, err := r.attempts.Indexes().CreateOne(ctx, expiry)
+	return err
+}
+
+func (r *MongoTelegramLinkRepository) CreateLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error {
+	_, err := r.codes.InsertOne(ctx, code)
+	return err
+}
+
+func (r *MongoTelegramLinkRepository) ConsumeLinkCode(ctx context.Context, code string, now time.Time) (*domain.TelegramLinkCode, error) {
+	var lc domain.TelegramLinkCode
+	err := r.codes.FindOneAndDelete(ctx, bson.M{"_id": code, "expires_at": bson.M{"$gt": now}}).Decode(&lc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrLinkCodeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lc, nil
+}
+
+func (r *MongoTelegramLinkRepository) SaveLink(ctx context.Context, link *domain.TelegramLink) error {
+	// A chat belongs to one account: drop its link to anyone else first
+	if _, err := r.links.DeleteMany(ctx, bson.M{"chat_id": link.ChatID, "_id": bson.M{"$ne": link.UserID}}); err != nil {
+		return err
+	}
+	_, err := r.links.ReplaceOne(ctx, bson.M{"_id": link.UserID}, link, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoTelegramLinkRepository) GetLinkByUser(ctx context.Context, userID string) (*domain.TelegramLink, error) {
+	return r.findLink(ctx, bson.M{"_id": userID})
+}
+
+func (r *MongoTelegramLinkRepository) GetLinkByChat(ctx context.Context, chatID int64) (*domain.TelegramLink, error) {
+	return r.findLink(ctx, bson.M{"chat_id": chatID})
+}
+
+func (r *MongoTelegramLinkRepository) DeleteLinkByChat(ctx context.Context, chatID int64) error {
+	_, err := r.links.DeleteMany(ctx, bson.M{"chat_id": chatID})
+	return err
+}
+
+// RecordLinkFailure counts the failure in one atomic update, opening a new
+// window when the chat has none or its window has ended; the TTL index only
+// removes ended windows eventually.
+func (r *MongoTelegramLinkRepository) RecordLinkFailure(ctx context.Context, chatID int64, now time.Time, window time.Duration) (int, error) {
+	open := bson.M{"$gt": bson.A{"$expires_at", now}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures":   bson.M{"$cond": bson.A{open, bson.M{"$add": bson.A{"$failures", 1}}, 1}},
+		"expires_at": bson.M{"$cond": bson.A{open, "$expires_at", now.Add(window)}},
+	}}}}
+	var doc struct {
+		Failures int `bson:"failures"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.attempts.FindOneAndUpdate(ctx, bson.M{"_id": chatID}, update, opts).Decode(&doc); err != nil {
+		return 0, err
+	}
+	return doc.Failures, nil
+}
+
+func (r *MongoTelegramLinkRepository) LinkFailures(ctx context.Context, chatID int64, now time.Time) (int, error) {
+	var doc struct {
+		Failures int `bson:"failures"`
+	}
+	err := r.attempts.FindOne(ctx, bson.M{"_id": chatID, "expires_at": bson.M{"$gt": now}}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return doc.Failures, nil
+}
+
+func (r *MongoTelegramLinkRepository) findLink(ctx context.Context, filter bson.M) (*domain.TelegramLink, error) {
+	var link domain.TelegramLink
+	err := r.links.FindOne(ctx, filter).Decode(&link)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+var _ usecase.TelegramLinkRepository = (*MongoTelegramLinkRepository)(nil)
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoWatchlistRepository stores one watchlist document per user.
+type MongoWatchlistRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoWatchlistRepository(db *mongo.Database, collection string) *MongoWatchlistRepository {
+	if collection == "" {
+		collection = "watchlists"
+	}
+	return &MongoWatchlistRepository{coll: db.Collection(collection)}
+}
+
+func (r *MongoWatchlistRepository) GetWatchlist(ctx context.Context, userID string) (*domain.Watchlist, error) {
+	var w domain.Watchlist
+	err := r.coll.FindOne(ctx, bson.M{"_id": userID}).Decode(&w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// SaveWatchlist replaces the watchlist only if it is still at w.Version.
+// A first save inserts it; watchlists stored before versioning match too.
+// A lost race shows as no match, or as a duplicate key when the upsert
+// tries to insert, and returns domain.ErrWatchlistConflict.
+func (r *MongoWatchlistRepository) SaveWatchlist(ctx context.Context, w *domain.Watchlist) error {
+	filter := bson.M{"_id": w.UserID, "version": w.Version}
+	if w.Version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	next := *w
+	next.Version++
+	res, err := r.coll.ReplaceOne(ctx, filter, &next, options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrWatchlistConflict
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 && res.UpsertedCount == 0 {
+		return domain.ErrWatchlistConflict
+	}
+	w.Version = next.Version
+	return nil
+}
+
+var _ usecase.WatchlistRepository = (*MongoWatchlistRepository)(nil)
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/shopally-ai/pkg/usecase"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoWebhookRepository stores API clients' webhooks in a MongoDB collection.
+type MongoWebhookRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoWebhookRepository(db *mongo.Database, collection string) *MongoWebhookRepository {
+	if collection == "" {
+		collection = "webhooks"
+	}
+	return &MongoWebhookRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the index used to list a client's webhooks.
+func (r *MongoWebhookRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "client_id", Value: 1}}})
+	return err
+}
+
+func (r *MongoWebhookRepository) CreateWebhook(ctx context.Context, w *domain.Webhook) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	_, err := r.coll.InsertOne(ctx, w)
+	return err
+}
+
+func (r *MongoWebhookRepository) ListWebhooks(ctx context.Context, clientID string) ([]*domain.Webhook, error) {
+	cur, err := r.coll.Find(ctx, bson.M{"client_id": clientID})
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.Webhook
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *MongoWebhookRepository) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	var w domain.Webhook
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *MongoWebhookRepository) DeleteWebhook(ctx context.Context, clientID, id string) error {
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": id, "client_id": clientID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+var _ usecase.WebhookRepository = (*MongoWebhookRepository)(nil)
+
+// MongoWebhookDeliveryRepository stores webhook deliveries in a MongoDB collection.
+type MongoWebhookDeliveryRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoWebhookDeliveryRepository(db *mongo.Database, collection string) *MongoWebhookDeliveryRepository {
+	if collection == "" {
+		collection = "webhook_deliveries"
+	}
+	return &MongoWebhookDeliveryRepository{coll: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the index used to page through a client's delivery log.
+func (r *MongoWebhookDeliveryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "client_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
+
+func (r *MongoWebhookDeliveryRepository) CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) (bool, error) {
+	_, err := r.coll.InsertOne(ctx, d)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MongoWebhookDeliveryRepository) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *MongoWebhookDeliveryRepository) SaveDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	_, err := r.coll.ReplaceOne(ctx, bson.M{"_id": d.ID}, d)
+	return err
+}
+
+func (r *MongoWebhookDeliveryRepository) ListDeliveries(ctx context.Context, clientID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	filter := bson.M{"client_id": clientID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.WebhookDelivery
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _ usecase.WebhookDeliveryRepository = (*MongoWebhookDeliveryRepository)(nil)
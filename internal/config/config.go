@@ -10,9 +10,20 @@ type Config struct {
 	} `mapstructure:"server"`
 
 	Mongo struct {
-		URI             string `mapstructure:"uri"`
-		Database        string `mapstructure:"database"`
-		AlertCollection string `mapstructure:"alert_collection"`
+		URI                    string `mapstructure:"uri"`
+		Database               string `mapstructure:"database"`
+		AlertCollection        string `mapstructure:"alert_collection"`
+		PriceHistoryCollection string `mapstructure:"price_history_collection"`
+		FXHistoryCollection    string `mapstructure:"fx_history_collection"`
+		NotificationCollection string `mapstructure:"notification_collection"`
+		DeviceCollection       string `mapstructure:"device_collection"`
+		PreferencesCollection  string `mapstructure:"preferences_collection"`
+		TelegramCollection     string `mapstructure:"telegram_collection"`
+		SavedSearchCollection  string `mapstructure:"saved_search_collection"`
+		WebhookCollection      string `mapstructure:"webhook_collection"`
+		WatchlistCollection    string `mapstructure:"watchlist_collection"`
+		// WebhookDeliveryCollection holds the webhook delivery log.
+		WebhookDeliveryCollection string `mapstructure:"webhook_delivery_collection"`
 	} `mapstructure:"mongo"`
 
 	Redis struct {
@@ -30,6 +41,100 @@ type Config struct {
 		CacheTTLSeconds int    `mapstructure:"cache_ttl_seconds"`
 	}
 
+	Product struct {
+		CacheTTLSeconds int `mapstructure:"cache_ttl_seconds"`
+	} `mapstructure:"product"`
+
+	PriceHistory struct {
+		SnapshotIntervalMinutes int `mapstructure:"snapshot_interval_minutes"`
+	} `mapstructure:"price_history"`
+
+	Deals struct {
+		WindowDays int `mapstructure:"window_days"`
+	} `mapstructure:"deals"`
+
+	// Customs overrides the default landed-cost rules; unset values keep the
+	// defaults and 0 is a valid override. Rates are fractions, e.g. 0.15.
+	Customs struct {
+		DutyFreeUSD            *float64 `mapstructure:"duty_free_usd"`
+		DutyRate               *float64 `mapstructure:"duty_rate"`
+		ExciseRate             *float64 `mapstructure:"excise_rate"`
+		VATRate                *float64 `mapstructure:"vat_rate"`
+		SurtaxRate             *float64 `mapstructure:"surtax_rate"`
+		WithholdingRate        *float64 `mapstructure:"withholding_rate"`
+		CommercialThresholdUSD *float64 `mapstructure:"commercial_threshold_usd"`
+		ClearanceFeeUSD        *float64 `mapstructure:"clearance_fee_usd"`
+	} `mapstructure:"customs"`
+
+	Alerts struct {
+		// MaxActivePerUser caps each user's active alerts; 0 means no limit.
+		MaxActivePerUser int `mapstructure:"max_active_per_user"`
+		// EvaluationConcurrency bounds concurrent product fetches per evaluation cycle.
+		EvaluationConcurrency int `mapstructure:"evaluation_concurrency"`
+	} `mapstructure:"alerts"`
+
+	Prediction struct {
+		LookbackDays int `mapstructure:"lookback_days"`
+	} `mapstructure:"prediction"`
+
+	Queue struct {
+		VisibilityTimeoutSeconds int `mapstructure:"visibility_timeout_seconds"`
+		MaxAttempts              int `mapstructure:"max_attempts"`
+		BaseBackoffSeconds       int `mapstructure:"base_backoff_seconds"`
+		MaxBackoffSeconds        int `mapstructure:"max_backoff_seconds"`
+	} `mapstructure:"queue"`
+
+	Events struct {
+		// TopicPrefix prefixes the queue topics domain events are bridged to;
+		// empty keeps events in process.
+		TopicPrefix string `mapstructure:"topic_prefix"`
+	} `mapstructure:"events"`
+
+	Notifications struct {
+		MaxAttempts        int `mapstructure:"max_attempts"`
+		BaseBackoffSeconds int `mapstructure:"base_backoff_seconds"`
+	} `mapstructure:"notifications"`
+
+	Devices struct {
+		StaleAfterDays int `mapstructure:"stale_after_days"`
+	} `mapstructure:"devices"`
+
+	FCM struct {
+		ProjectID       string `mapstructure:"project_id"`
+		CredentialsFile string `mapstructure:"credentials_file"`
+	} `mapstructure:"fcm"`
+
+	SMTP struct {
+		Host     string `mapstructure:"host"`
+		Port     int    `mapstructure:"port"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+		From     string `mapstructure:"from"`
+		StartTLS bool   `mapstructure:"starttls"`
+	} `mapstructure:"smtp"`
+
+	SMS struct {
+		APIURL        string `mapstructure:"api_url"`
+		APIKey        string `mapstructure:"api_key"`
+		SenderID      string `mapstructure:"sender_id"`
+		WebhookSecret string `mapstructure:"webhook_secret"`
+		// ShortLinkBaseURL prefixes short link codes, e.g. "https://sa.et/s/".
+		ShortLinkBaseURL string `mapstructure:"short_link_base_url"`
+	} `mapstructure:"sms"`
+
+	Telegram struct {
+		BotToken      string `mapstructure:"bot_token"`
+		APIURL        string `mapstructure:"api_url"`
+		WebhookSecret string `mapstructure:"webhook_secret"`
+		BotUsername   string `mapstructure:"bot_username"`
+	} `mapstructure:"telegram"`
+
+	Worker struct {
+		StatusAddr       string               `mapstructure:"status_addr"`
+		LeaderTTLSeconds int                  `mapstructure:"leader_ttl_seconds"`
+		Jobs             map[string]JobConfig `mapstructure:"jobs"`
+	} `mapstructure:"worker"`
+
 	OAuth struct {
 		Google struct {
 			ClientID     string `mapstructure:"client_id"`
@@ -45,6 +150,16 @@ type Config struct {
 	} `mapstructure:"oauth"`
 }
 
+// JobConfig overrides the schedule of a named worker job. Zero values keep
+// the job's built-in defaults.
+type JobConfig struct {
+	Schedule       string `mapstructure:"schedule"` // cron expression or "@every 30m"
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+	JitterSeconds  int    `mapstructure:"jitter_seconds"`
+	Overlap        string `mapstructure:"overlap"` // skip, allow or queue
+	Disabled       bool   `mapstructure:"disabled"`
+}
+
 func LoadConfig(path string) (*Config, error) {
 	viper.SetConfigName("configs/config.dev")
 	viper.SetConfigType("yaml")
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/shopally-ai/pkg/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -78,6 +80,84 @@ func (_m *AlertRepository) GetAlert(alertID string) (*domain.Alert, error) {
 	return r0, r1
 }
 
+// ListActiveAlerts provides a mock function with no fields
+func (_m *AlertRepository) ListActiveAlerts() ([]*domain.Alert, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActiveAlerts")
+	}
+
+	var r0 []*domain.Alert
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*domain.Alert, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*domain.Alert); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Alert)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListActiveUserAlerts provides a mock function with given fields: userID
+func (_m *AlertRepository) ListActiveUserAlerts(userID string) ([]*domain.Alert, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActiveUserAlerts")
+	}
+
+	var r0 []*domain.Alert
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*domain.Alert, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*domain.Alert); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Alert)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAlert provides a mock function with given fields: ctx, alert
+func (_m *AlertRepository) UpdateAlert(ctx context.Context, alert *domain.Alert) error {
+	ret := _m.Called(ctx, alert)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAlert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Alert) error); ok {
+		r0 = rf(ctx, alert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewAlertRepository creates a new instance of AlertRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAlertRepository(t interface {
@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	from := time.Date(2026, 1, 30, 10, 7, 30, 0, time.UTC) // Friday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 30m", from.Add(30 * time.Minute)},
+		{"*/15 * * * *", time.Date(2026, 1, 30, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 1, 30, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, 2, 2, 9, 30, 0, 0, time.UTC)},
+		{"0 8 1,15 * *", time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matching is enough
+		{"0 12 15 * 6", time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		s, err := ParseSchedule(tc.spec, nil)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.spec, err)
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: Next = %v, want %v", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestParseScheduleInLocation(t *testing.T) {
+	addis, err := time.LoadLocation("Africa/Addis_Ababa")
+	if err != nil {
+		t.Skip("tz data unavailable")
+	}
+	s, err := ParseSchedule("0 6 * * *", addis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2026, 1, 30, 4, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 1, 31, 3, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("Next = %v, want %v", got.UTC(), want)
+	}
+}
+
+func TestParseScheduleRejectsInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@every soon", "a * * * *"} {
+		if _, err := ParseSchedule(spec, nil); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Elector runs leader election over a Locker lease. The leader renews its
+// lease every third of the TTL; when it dies the lease expires and another
+// replica takes over within about one TTL.
+type Elector struct {
+	locker *Locker
+	name   string
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	lease    *Lease
+	leaderCx context.Context
+	cancel   context.CancelFunc
+}
+
+func NewElector(locker *Locker, name string, ttl time.Duration) *Elector {
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+	return &Elector{locker: locker, name: name, ttl: ttl}
+}
+
+// Run campaigns for leadership until ctx is cancelled, then steps down.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	defer e.stepDown(context.Background(), true)
+
+	for {
+		e.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Campaign makes a single attempt to acquire or renew leadership and reports
+// whether this replica is the leader afterwards.
+func (e *Elector) Campaign(ctx context.Context) bool {
+	e.tick(ctx)
+	return e.IsLeader()
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	e.mu.Lock()
+	lease := e.lease
+	e.mu.Unlock()
+
+	if lease != nil {
+		err := lease.Renew(ctx)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		// A transient Redis error may still leave the lease valid, but we cannot
+		// prove it; stepping down is the only safe choice
+		log.Printf("leader %s: lost leadership: %v", e.name, err)
+		e.stepDown(ctx, false)
+		return
+	}
+
+	lease, err := e.locker.Acquire(ctx, e.name, e.ttl)
+	if err != nil {
+		if !errors.Is(err, ErrLockHeld) && ctx.Err() == nil {
+			log.Printf("leader %s: acquire: %v", e.name, err)
+		}
+		return
+	}
+	lctx, cancel := context.WithCancel(WithFencingToken(ctx, lease.Token()))
+	e.mu.Lock()
+	e.lease, e.leaderCx, e.cancel = lease, lctx, cancel
+	e.mu.Unlock()
+	log.Printf("leader %s: acquired leadership (token %d)", e.name, lease.Token())
+}
+
+func (e *Elector) stepDown(ctx context.Context, release bool) {
+	e.mu.Lock()
+	lease, cancel := e.lease, e.cancel
+	e.lease, e.leaderCx, e.cancel = nil, nil, nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if release && lease != nil {
+		_ = lease.Release(ctx)
+	}
+}
+
+// IsLeader reports whether this replica currently holds leadership.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lease != nil
+}
+
+// LeaderContext returns a context that carries the fencing token and is
+// cancelled when leadership is lost. ok is false when not the leader.
+func (e *Elector) LeaderContext() (ctx context.Context, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lease == nil {
+		return nil, false
+	}
+	return e.leaderCx, true
+}
@@ -0,0 +1,141 @@
+package platform
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopally-ai/pkg/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type LockSuite struct {
+	suite.Suite
+	ctx    context.Context
+	mr     *miniredis.Miniredis
+	client *Client
+	locker *Locker
+}
+
+func (s *LockSuite) SetupTest() {
+	s.ctx = context.Background()
+	mr, err := miniredis.Run()
+	s.Require().NoError(err)
+	s.mr = mr
+	s.client = &Client{redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	s.locker = NewLocker(s.client, "sa:lock:")
+}
+
+func (s *LockSuite) TearDownTest() {
+	_ = s.client.Close()
+	s.mr.Close()
+}
+
+func (s *LockSuite) TestExclusiveWithIncreasingFencingTokens() {
+	first, err := s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.Require().NoError(err)
+
+	_, err = s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.ErrorIs(err, ErrLockHeld)
+
+	s.Require().NoError(first.Release(s.ctx))
+	second, err := s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.Require().NoError(err)
+	s.Greater(second.Token(), first.Token())
+}
+
+func (s *LockSuite) TestRenewKeepsLeaseAlive() {
+	lease, err := s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.Require().NoError(err)
+
+	s.mr.FastForward(800 * time.Millisecond)
+	s.Require().NoError(lease.Renew(s.ctx))
+	s.mr.FastForward(800 * time.Millisecond)
+
+	_, err = s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.ErrorIs(err, ErrLockHeld)
+}
+
+func (s *LockSuite) TestExpiredLeaseIsTakenOver() {
+	stale, err := s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.Require().NoError(err)
+	s.mr.FastForward(2 * time.Second)
+
+	fresh, err := s.locker.Acquire(s.ctx, "jobs", time.Second)
+	s.Require().NoError(err)
+	s.Greater(fresh.Token(), stale.Token())
+
+	// The stale holder can neither renew nor release the new owner's lock
+	s.ErrorIs(stale.Renew(s.ctx), ErrLockLost)
+	s.ErrorIs(stale.Release(s.ctx), ErrLockLost)
+	s.NoError(fresh.Renew(s.ctx))
+}
+
+func (s *LockSuite) TestElectorFailover() {
+	a := NewElector(s.locker, "leader", time.Second)
+	b := NewElector(s.locker, "leader", time.Second)
+
+	s.True(a.Campaign(s.ctx))
+	s.False(b.Campaign(s.ctx))
+	lctx, ok := a.LeaderContext()
+	s.Require().True(ok)
+	token, _ := FencingToken(lctx)
+
+	// a dies without releasing; its lease expires and b takes over
+	s.mr.FastForward(2 * time.Second)
+	s.True(b.Campaign(s.ctx))
+	bctx, _ := b.LeaderContext()
+	newToken, _ := FencingToken(bctx)
+	s.Greater(newToken, token)
+
+	// a notices on its next renewal and its leader context is cancelled
+	s.False(a.Campaign(s.ctx))
+	s.Error(lctx.Err())
+}
+
+func (s *LockSuite) TestReplacedLeaderWritesAreRejected() {
+	a := NewElector(s.locker, "leader", time.Second)
+	b := NewElector(s.locker, "leader", time.Second)
+	s.True(a.Campaign(s.ctx))
+	actx, _ := a.LeaderContext()
+
+	// a pauses past its lease; b takes over and writes the resource first
+	s.mr.FastForward(2 * time.Second)
+	s.True(b.Campaign(s.ctx))
+	bctx, _ := b.LeaderContext()
+	highest, err := AdmitFencingToken(bctx, 0)
+	s.Require().NoError(err)
+
+	// a resumes still believing it leads, and its write is refused
+	_, err = AdmitFencingToken(actx, highest)
+	s.ErrorIs(err, domain.ErrStaleFencingToken)
+
+	// b keeps writing, and writes without a token are not fenced
+	next, err := AdmitFencingToken(bctx, highest)
+	s.NoError(err)
+	s.Equal(highest, next)
+	next, err = AdmitFencingToken(s.ctx, highest)
+	s.NoError(err)
+	s.Equal(highest, next)
+}
+
+func (s *LockSuite) TestElectorReleasesOnShutdown() {
+	a := NewElector(s.locker, "leader", time.Minute)
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx)
+		close(done)
+	}()
+	s.Eventually(a.IsLeader, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	s.True(NewElector(s.locker, "leader", time.Minute).Campaign(s.ctx))
+}
+
+func TestLockSuite(t *testing.T) {
+	suite.Run(t, new(LockSuite))
+}
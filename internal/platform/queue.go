@@ -1 +1,486 @@
 package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Message is a job delivered by a Queue.
+type Message struct {
+	ID         string
+	Topic      string
+	Payload    []byte
+	Attempt    int // 1-based delivery attempt
+	EnqueuedAt time.Time
+	LastError  string
+}
+
+// Handler processes a message. Returning an error schedules a retry, or moves
+// the message to the dead-letter stream once MaxAttempts is reached.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Queue is a job queue with at-least-once delivery: handlers must be idempotent.
+type Queue interface {
+	Enqueue(ctx context.Context, topic string, payload []byte) (string, error)
+	// Consume processes messages of a topic until ctx is cancelled.
+	Consume(ctx context.Context, topic string, h Handler) error
+}
+
+// consumeRestartBackoff spaces the restarts of a failing consumer.
+var consumeRestartBackoff = QueueOptions{BaseBackoff: time.Second, MaxBackoff: time.Minute}
+
+// ConsumeUntilDone runs q.Consume until ctx is cancelled. Consume returns on
+// errors such as a Redis outage; it is restarted with backoff so a transient
+// failure does not stop the consumer for good. onError is told about each
+// failure and may be nil.
+func ConsumeUntilDone(ctx context.Context, q Queue, topic string, h Handler, onError func(error)) {
+	failures := 0
+	for ctx.Err() == nil {
+		started := time.Now()
+		err := q.Consume(ctx, topic, h)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("consumer stopped")
+		}
+		// A consumer that ran for a while failed afresh
+		if time.Since(started) > consumeRestartBackoff.MaxBackoff {
+			failures = 0
+		}
+		failures++
+		if onError != nil {
+			onError(fmt.Errorf("consume %s: %w", topic, err))
+		}
+		t := time.NewTimer(consumeRestartBackoff.Backoff(failures))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// QueueOptions tunes delivery. Zero values fall back to sensible defaults.
+type QueueOptions struct {
+	KeyPrefix         string        // stream key prefix, default "sa:queue:"
+	Group             string        // consumer group, default "workers"
+	Consumer          string        // consumer name, default hostname-pid
+	VisibilityTimeout time.Duration // max handling time before a message is reclaimed, default 30s
+	MaxAttempts       int           // attempts before dead-lettering, default 5
+	BaseBackoff       time.Duration // first retry delay, doubled per attempt, default 1s
+	MaxBackoff        time.Duration // retry delay cap, default 5m
+	BlockTimeout      time.Duration // how long a read waits for new messages, default 2s
+	BatchSize         int64         // retries promoted and stalled messages reclaimed per round trip, default 10
+}
+
+// NewQueueOptions builds options from configured seconds, so the API and the
+// worker enqueue and consume with the same settings. Zero values keep the
+// defaults.
+func NewQueueOptions(visibilityTimeoutSeconds, maxAttempts, baseBackoffSeconds, maxBackoffSeconds int) QueueOptions {
+	return QueueOptions{
+		VisibilityTimeout: time.Duration(visibilityTimeoutSeconds) * time.Second,
+		MaxAttempts:       maxAttempts,
+		BaseBackoff:       time.Duration(baseBackoffSeconds) * time.Second,
+		MaxBackoff:        time.Duration(maxBackoffSeconds) * time.Second,
+	}
+}
+
+func (o QueueOptions) withDefaults() QueueOptions {
+	if o.KeyPrefix == "" {
+		o.KeyPrefix = "sa:queue:"
+	}
+	if o.Group == "" {
+		o.Group = "workers"
+	}
+	if o.Consumer == "" {
+		host, _ := os.Hostname()
+		o.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if o.VisibilityTimeout <= 0 {
+		o.VisibilityTimeout = 30 * time.Second
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 5 * time.Minute
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = 2 * time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 10
+	}
+	return o
+}
+
+// Backoff returns the delay before the retry that follows the given attempt.
+func (o QueueOptions) Backoff(attempt int) time.Duration {
+	d := o.BaseBackoff
+	for i := 1; i < attempt && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.MaxBackoff {
+		d = o.MaxBackoff
+	}
+	return d
+}
+
+// errVisibilityTimeout marks messages reclaimed from a stalled consumer.
+var errVisibilityTimeout = errors.New("visibility timeout exceeded")
+
+// StreamQueue is a Queue on Redis Streams. Each topic is a stream consumed by
+// a consumer group; retries wait in a sorted set until due and are then moved
+// back onto the stream; exhausted messages go to a "<topic>:dead" stream.
+type StreamQueue struct {
+	client *Client
+	opts   QueueOptions
+}
+
+var _ Queue = (*StreamQueue)(nil)
+
+func NewStreamQueue(client *Client, opts QueueOptions) *StreamQueue {
+	return &StreamQueue{client: client, opts: opts.withDefaults()}
+}
+
+func (q *StreamQueue) streamKey(topic string) string { return q.opts.KeyPrefix + topic }
+func (q *StreamQueue) retryKey(topic string) string  { return q.opts.KeyPrefix + topic + ":retry" }
+func (q *StreamQueue) deadKey(topic string) string   { return q.opts.KeyPrefix + topic + ":dead" }
+
+func (q *StreamQueue) Enqueue(ctx context.Context, topic string, payload []byte) (string, error) {
+	return q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.streamKey(topic),
+		Values: map[string]interface{}{
+			"payload":     payload,
+			"attempt":     1,
+			"enqueued_at": time.Now().UnixMilli(),
+			"last_error":  "",
+		},
+	}).Result()
+}
+
+func (q *StreamQueue) Consume(ctx context.Context, topic string, h Handler) error {
+	if err := q.ensureGroup(ctx, topic); err != nil {
+		return err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := q.promoteDue(ctx, topic); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("promote retries: %w", err)
+		}
+		if err := q.reclaimStalled(ctx, topic); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("reclaim stalled: %w", err)
+		}
+
+		// One message at a time: the visibility timeout of a read starts at
+		// once, so a batch's tail would be reclaimed while it waits its turn
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.opts.Group,
+			Consumer: q.opts.Consumer,
+			Streams:  []string{q.streamKey(topic), ">"},
+			Count:    1,
+			Block:    q.opts.BlockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read group: %w", err)
+		}
+
+		for _, s := range streams {
+			for _, xm := range s.Messages {
+				q.handle(ctx, topic, decodeMessage(topic, xm), h)
+			}
+		}
+	}
+}
+
+// DeadLetters returns up to count messages from the topic's dead-letter stream.
+func (q *StreamQueue) DeadLetters(ctx context.Context, topic string, count int64) ([]*Message, error) {
+	xms, err := q.client.XRangeN(ctx, q.deadKey(topic), "-", "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Message, 0, len(xms))
+	for _, xm := range xms {
+		out = append(out, decodeMessage(topic, xm))
+	}
+	return out, nil
+}
+
+func (q *StreamQueue) ensureGroup(ctx context.Context, topic string) error {
+	err := q.client.XGroupCreateMkStream(ctx, q.streamKey(topic), q.opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create group: %w", err)
+	}
+	return nil
+}
+
+// ownScript checks that a message is still pending for the consumer and resets
+// its idle time, so it is not reclaimed between being read and being handled.
+var ownScript = redis.NewScript(`
+local p = redis.call('XPENDING', KEYS[1], ARGV[1], ARGV[3], ARGV[3], 1)
+if #p == 0 or p[1][2] ~= ARGV[2] then
+  return 0
+end
+redis.call('XCLAIM', KEYS[1], ARGV[1], ARGV[2], 0, ARGV[3], 'JUSTID')
+return 1
+`)
+
+// own reports whether the consumer still holds msg, restarting its visibility
+// timeout if so.
+func (q *StreamQueue) own(ctx context.Context, topic, id string) (bool, error) {
+	n, err := ownScript.Run(ctx, q.client, []string{q.streamKey(topic)}, q.opts.Group, q.opts.Consumer, id).Int()
+	return n == 1, err
+}
+
+func (q *StreamQueue) handle(ctx context.Context, topic string, msg *Message, h Handler) {
+	// Another consumer reclaimed it, or Redis is unreachable; in both cases
+	// handling it here could deliver it twice
+	if owned, err := q.own(ctx, topic, msg.ID); err != nil || !owned {
+		return
+	}
+	hctx, cancel := context.WithTimeout(ctx, q.opts.VisibilityTimeout)
+	err := h(hctx, msg)
+	cancel()
+
+	if err != nil {
+		if ferr := q.fail(ctx, topic, msg, err); ferr != nil {
+			// Leave the message pending; it is reclaimed after the visibility timeout
+			return
+		}
+	}
+	q.ack(ctx, topic, msg.ID)
+}
+
+func (q *StreamQueue) ack(ctx context.Context, topic, id string) {
+	pipe := q.client.TxPipeline()
+	pipe.XAck(ctx, q.streamKey(topic), q.opts.Group, id)
+	pipe.XDel(ctx, q.streamKey(topic), id)
+	_, _ = pipe.Exec(ctx)
+}
+
+// fail schedules a retry with exponential backoff, or dead-letters the message
+// once it has used all of its attempts.
+func (q *StreamQueue) fail(ctx context.Context, topic string, msg *Message, cause error) error {
+	reason := strings.ReplaceAll(cause.Error(), "\n", " ")
+	if msg.Attempt >= q.opts.MaxAttempts {
+		return q.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.deadKey(topic),
+			Values: map[string]interface{}{
+				"payload":     msg.Payload,
+				"attempt":     msg.Attempt,
+				"enqueued_at": msg.EnqueuedAt.UnixMilli(),
+				"last_error":  reason,
+				"original_id": msg.ID,
+				"failed_at":   time.Now().UnixMilli(),
+			},
+		}).Err()
+	}
+
+	// Retry entries carry the payload last so the promote script can split safely
+	member := strings.Join([]string{
+		msg.ID,
+		strconv.Itoa(msg.Attempt + 1),
+		strconv.FormatInt(msg.EnqueuedAt.UnixMilli(), 10),
+		reason,
+		string(msg.Payload),
+	}, "\n")
+	due := time.Now().Add(q.opts.Backoff(msg.Attempt))
+	return q.client.ZAdd(ctx, q.retryKey(topic), redis.Z{Score: float64(due.UnixMilli()), Member: member}).Err()
+}
+
+// promoteScript atomically moves due retries from the sorted set back onto the stream.
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, m in ipairs(due) do
+  if redis.call('ZREM', KEYS[1], m) == 1 then
+    local _, attempt, enqueued, lasterr, payload = string.match(m, '^(.-)\n(.-)\n(.-)\n(.-)\n(.*)$')
+    redis.call('XADD', KEYS[2], '*', 'payload', payload, 'attempt', attempt, 'enqueued_at', enqueued, 'last_error', lasterr)
+  end
+end
+return #due
+`)
+
+func (q *StreamQueue) promoteDue(ctx context.Context, topic string) error {
+	keys := []string{q.retryKey(topic), q.streamKey(topic)}
+	return promoteScript.Run(ctx, q.client, keys, time.Now().UnixMilli(), q.opts.BatchSize).Err()
+}
+
+// reclaimStalled takes over messages left pending longer than the visibility
+// timeout (e.g. by a crashed consumer) and counts them as failed attempts.
+func (q *StreamQueue) reclaimStalled(ctx context.Context, topic string) error {
+	xms, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.streamKey(topic),
+		Group:    q.opts.Group,
+		Consumer: q.opts.Consumer,
+		MinIdle:  q.opts.VisibilityTimeout,
+		Start:    "0-0",
+		Count:    q.opts.BatchSize,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, xm := range xms {
+		msg := decodeMessage(topic, xm)
+		if err := q.fail(ctx, topic, msg, errVisibilityTimeout); err != nil {
+			return err
+		}
+		q.ack(ctx, topic, msg.ID)
+	}
+	return nil
+}
+
+func decodeMessage(topic string, xm redis.XMessage) *Message {
+	msg := &Message{ID: xm.ID, Topic: topic, Attempt: 1}
+	if v, ok := xm.Values["payload"].(string); ok {
+		msg.Payload = []byte(v)
+	}
+	if v, ok := xm.Values["attempt"].(string); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			msg.Attempt = n
+		}
+	}
+	if v, ok := xm.Values["enqueued_at"].(string); ok {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			msg.EnqueuedAt = time.UnixMilli(ms).UTC()
+		}
+	}
+	if v, ok := xm.Values["last_error"].(string); ok {
+		msg.LastError = v
+	}
+	return msg
+}
+
+// MemoryQueue is an in-process Queue with the same retry and dead-letter
+// semantics as StreamQueue. It is meant for tests and single-process runs.
+type MemoryQueue struct {
+	opts QueueOptions
+
+	mu     sync.Mutex
+	seq    int64
+	topics map[string]chan *Message
+	dead   map[string][]*Message
+	// parked holds retries that came due after their consumer stopped; the
+	// next Consume of the topic delivers them.
+	parked map[string][]*Message
+}
+
+var _ Queue = (*MemoryQueue)(nil)
+
+func NewMemoryQueue(opts QueueOptions) *MemoryQueue {
+	return &MemoryQueue{
+		opts:   opts.withDefaults(),
+		topics: map[string]chan *Message{},
+		dead:   map[string][]*Message{},
+		parked: map[string][]*Message{},
+	}
+}
+
+func (q *MemoryQueue) channel(topic string) chan *Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ch, ok := q.topics[topic]
+	if !ok {
+		ch = make(chan *Message, 1024)
+		q.topics[topic] = ch
+	}
+	return ch
+}
+
+func (q *MemoryQueue) Enqueue(ctx context.Context, topic string, payload []byte) (string, error) {
+	q.mu.Lock()
+	q.seq++
+	id := strconv.FormatInt(q.seq, 10)
+	q.mu.Unlock()
+
+	msg := &Message{ID: id, Topic: topic, Payload: append([]byte(nil), payload...), Attempt: 1, EnqueuedAt: time.Now().UTC()}
+	select {
+	case q.channel(topic) <- msg:
+		return id, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (q *MemoryQueue) Consume(ctx context.Context, topic string, h Handler) error {
+	ch := q.channel(topic)
+	q.mu.Lock()
+	parked := q.parked[topic]
+	delete(q.parked, topic)
+	q.mu.Unlock()
+	if len(parked) > 0 {
+		go func() {
+			for _, msg := range parked {
+				q.redeliver(ctx, topic, msg)
+			}
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			hctx, cancel := context.WithTimeout(ctx, q.opts.VisibilityTimeout)
+			err := h(hctx, msg)
+			cancel()
+			if err != nil {
+				q.fail(ctx, topic, msg, err)
+			}
+		}
+	}
+}
+
+// redeliver puts msg back on the topic's channel. The channel only drains
+// while ctx's consumer runs, so once it stops msg is parked instead of
+// blocking forever.
+func (q *MemoryQueue) redeliver(ctx context.Context, topic string, msg *Message) {
+	select {
+	case q.channel(topic) <- msg:
+	case <-ctx.Done():
+		q.mu.Lock()
+		q.parked[topic] = append(q.parked[topic], msg)
+		q.mu.Unlock()
+	}
+}
+
+func (q *MemoryQueue) fail(ctx context.Context, topic string, msg *Message, cause error) {
+	retry := *msg
+	retry.LastError = cause.Error()
+	if msg.Attempt >= q.opts.MaxAttempts {
+		q.mu.Lock()
+		q.dead[topic] = append(q.dead[topic], &retry)
+		q.mu.Unlock()
+		return
+	}
+	retry.Attempt++
+	time.AfterFunc(q.opts.Backoff(msg.Attempt), func() { q.redeliver(ctx, topic, &retry) })
+}
+
+// DeadLetters returns the messages of a topic that exhausted their attempts.
+func (q *MemoryQueue) DeadLetters(topic string) []*Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]*Message(nil), q.dead[topic]...)
+}
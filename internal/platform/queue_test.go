@@ -0,0 +1,366 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+type StreamQueueSuite struct {
+	suite.Suite
+	ctx    context.Context
+	mr     *miniredis.Miniredis
+	client *Client
+	queue  *StreamQueue
+}
+
+func testQueueOptions() QueueOptions {
+	return QueueOptions{
+		Consumer:          "test",
+		VisibilityTimeout: 200 * time.Millisecond,
+		MaxAttempts:       3,
+		BaseBackoff:       10 * time.Millisecond,
+		MaxBackoff:        40 * time.Millisecond,
+		BlockTimeout:      20 * time.Millisecond,
+	}
+}
+
+func (s *StreamQueueSuite) SetupTest() {
+	s.ctx = context.Background()
+	mr, err := miniredis.Run()
+	s.Require().NoError(err)
+	s.mr = mr
+	s.client = &Client{redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	s.queue = NewStreamQueue(s.client, testQueueOptions())
+}
+
+func (s *StreamQueueSuite) TearDownTest() {
+	_ = s.client.Close()
+	s.mr.Close()
+}
+
+// consume runs the queue until stop returns true or the deadline passes.
+func (s *StreamQueueSuite) consume(h Handler, stop func() bool) {
+	ctx, cancel := context.WithTimeout(s.ctx, 3*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.queue.Consume(ctx, "jobs", h) }()
+	for !stop() && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	s.Require().NoError(<-done)
+}
+
+func (s *StreamQueueSuite) TestDeliversAndAcks() {
+	_, err := s.queue.Enqueue(s.ctx, "jobs", []byte(`{"n":1}`))
+	s.Require().NoError(err)
+
+	var mu sync.Mutex
+	var got []*Message
+	s.consume(func(_ context.Context, m *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, m)
+		return nil
+	}, func() bool { mu.Lock(); defer mu.Unlock(); return len(got) == 1 })
+
+	s.Require().Len(got, 1)
+	s.Equal(`{"n":1}`, string(got[0].Payload))
+	s.Equal(1, got[0].Attempt)
+	s.False(got[0].EnqueuedAt.IsZero())
+
+	pending, err := s.client.XPending(s.ctx, "sa:queue:jobs", "workers").Result()
+	s.Require().NoError(err)
+	s.Zero(pending.Count)
+}
+
+func (s *StreamQueueSuite) TestRetriesWithBackoffThenSucceeds() {
+	_, err := s.queue.Enqueue(s.ctx, "jobs", []byte("payload\nwith newline"))
+	s.Require().NoError(err)
+
+	var mu sync.Mutex
+	var attempts []int
+	var last *Message
+	s.consume(func(_ context.Context, m *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, m.Attempt)
+		last = m
+		if m.Attempt < 2 {
+			return errors.New("temporary")
+		}
+		return nil
+	}, func() bool { mu.Lock(); defer mu.Unlock(); return len(attempts) == 2 })
+
+	s.Equal([]int{1, 2}, attempts)
+	s.Equal("payload\nwith newline", string(last.Payload))
+	s.Equal("temporary", last.LastError)
+}
+
+func (s *StreamQueueSuite) TestDeadLettersAfterMaxAttempts() {
+	_, err := s.queue.Enqueue(s.ctx, "jobs", []byte("bad"))
+	s.Require().NoError(err)
+
+	s.consume(func(context.Context, *Message) error {
+		return errors.New("permanent")
+	}, func() bool {
+		dead, _ := s.queue.DeadLetters(s.ctx, "jobs", 10)
+		return len(dead) == 1
+	})
+
+	dead, err := s.queue.DeadLetters(s.ctx, "jobs", 10)
+	s.Require().NoError(err)
+	s.Require().Len(dead, 1)
+	s.Equal("bad", string(dead[0].Payload))
+	s.Equal(3, dead[0].Attempt)
+	s.Equal("permanent", dead[0].LastError)
+}
+
+func (s *StreamQueueSuite) TestReclaimsStalledMessages() {
+	_, err := s.queue.Enqueue(s.ctx, "jobs", []byte("stalled"))
+	s.Require().NoError(err)
+
+	// Another consumer reads the message and crashes without acking it
+	s.Require().NoError(s.queue.ensureGroup(s.ctx, "jobs"))
+	_, err = s.client.XReadGroup(s.ctx, &redis.XReadGroupArgs{
+		Group: "workers", Consumer: "crashed", Streams: []string{"sa:queue:jobs", ">"}, Count: 1,
+	}).Result()
+	s.Require().NoError(err)
+
+	var mu sync.Mutex
+	var got *Message
+	s.consume(func(_ context.Context, m *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = m
+		return nil
+	}, func() bool { mu.Lock(); defer mu.Unlock(); return got != nil })
+
+	s.Require().NotNil(got)
+	s.Equal("stalled", string(got.Payload))
+	s.Equal(2, got.Attempt)
+	s.Equal(errVisibilityTimeout.Error(), got.LastError)
+}
+
+func (s *StreamQueueSuite) TestBatchedMessagesAreNotReclaimedWhileQueued() {
+	for _, p := range []string{"a", "b", "c"} {
+		_, err := s.queue.Enqueue(s.ctx, "jobs", []byte(p))
+		s.Require().NoError(err)
+	}
+	opts := testQueueOptions()
+	opts.Consumer = "other"
+	other := NewStreamQueue(s.client, opts)
+
+	var mu sync.Mutex
+	var handled []string
+	var retried int
+	record := func(m *Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, string(m.Payload))
+		if m.Attempt > 1 {
+			retried++
+		}
+	}
+	count := func() int { mu.Lock(); defer mu.Unlock(); return len(handled) }
+
+	ctx, cancel := context.WithTimeout(s.ctx, 3*time.Second)
+	defer cancel()
+	done := make(chan error, 2)
+	// The slow consumer takes longer than the visibility timeout to get
+	// through all three
+	go func() {
+		done <- s.queue.Consume(ctx, "jobs", func(_ context.Context, m *Message) error {
+			record(m)
+			time.Sleep(120 * time.Millisecond)
+			return nil
+		})
+	}()
+	for count() == 0 && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+	go func() {
+		done <- other.Consume(ctx, "jobs", func(_ context.Context, m *Message) error {
+			record(m)
+			return nil
+		})
+	}()
+	for count() < 3 && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Leave time for any duplicate or retry to show up
+	time.Sleep(400 * time.Millisecond)
+	cancel()
+	s.Require().NoError(<-done)
+	s.Require().NoError(<-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.ElementsMatch([]string{"a", "b", "c"}, handled)
+	s.Zero(retried)
+	retries, err := s.client.ZCard(s.ctx, "sa:queue:jobs:retry").Result()
+	s.Require().NoError(err)
+	s.Zero(retries)
+}
+
+func TestStreamQueueSuite(t *testing.T) {
+	suite.Run(t, new(StreamQueueSuite))
+}
+
+func TestQueueOptionsBackoff(t *testing.T) {
+	o := QueueOptions{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}.withDefaults()
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := o.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestMemoryQueueRetriesAndDeadLetters(t *testing.T) {
+	q := NewMemoryQueue(testQueueOptions())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := q.Enqueue(ctx, "jobs", []byte("ok")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(ctx, "jobs", []byte("bad")); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	okAttempts := 0
+	go func() {
+		_ = q.Consume(ctx, "jobs", func(_ context.Context, m *Message) error {
+			if string(m.Payload) == "bad" {
+				return errors.New("permanent")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			okAttempts++
+			if m.Attempt < 2 {
+				return errors.New("temporary")
+			}
+			return nil
+		})
+	}()
+
+	for len(q.DeadLetters("jobs")) == 0 && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	dead := q.DeadLetters("jobs")
+	if len(dead) != 1 || string(dead[0].Payload) != "bad" || dead[0].Attempt != 3 {
+		t.Fatalf("unexpected dead letters: %+v", dead)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if okAttempts != 2 {
+		t.Fatalf("ok message attempts = %d, want 2", okAttempts)
+	}
+}
+
+// flakyQueue fails its first consumes, then consumes until cancelled.
+type flakyQueue struct {
+	*MemoryQueue
+	mu       sync.Mutex
+	failures int
+	consumes int
+}
+
+func (q *flakyQueue) Consume(ctx context.Context, topic string, h Handler) error {
+	q.mu.Lock()
+	q.consumes++
+	fail := q.consumes <= q.failures
+	q.mu.Unlock()
+	if fail {
+		return errors.New("read group: connection refused")
+	}
+	return q.MemoryQueue.Consume(ctx, topic, h)
+}
+
+func TestConsumeUntilDoneRestartsAfterErrors(t *testing.T) {
+	saved := consumeRestartBackoff
+	consumeRestartBackoff = QueueOptions{BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	defer func() { consumeRestartBackoff = saved }()
+
+	q := &flakyQueue{MemoryQueue: NewMemoryQueue(testQueueOptions()), failures: 3}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if _, err := q.Enqueue(ctx, "jobs", []byte("hook")); err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan string, 1)
+	var reported []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ConsumeUntilDone(ctx, q, "jobs", func(_ context.Context, m *Message) error {
+			handled <- string(m.Payload)
+			return nil
+		}, func(err error) { reported = append(reported, err) })
+	}()
+
+	select {
+	case got := <-handled:
+		if got != "hook" {
+			t.Fatalf("handled %q", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("the consumer was not restarted")
+	}
+	cancel()
+	<-done
+	if len(reported) != 3 {
+		t.Fatalf("reported %d errors, want 3", len(reported))
+	}
+}
+
+func TestMemoryQueueKeepsRetriesDueAfterTheConsumerStopped(t *testing.T) {
+	q := NewMemoryQueue(testQueueOptions())
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if _, err := q.Enqueue(ctx, "jobs", []byte("job")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first consumer fails the job and stops before its retry is due
+	first, stop := context.WithCancel(ctx)
+	failed := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = q.Consume(first, "jobs", func(context.Context, *Message) error {
+			close(failed)
+			return errors.New("temporary")
+		})
+	}()
+	<-failed
+	stop()
+	<-done
+	time.Sleep(3 * testQueueOptions().BaseBackoff)
+
+	attempts := make(chan int, 1)
+	go func() {
+		_ = q.Consume(ctx, "jobs", func(_ context.Context, m *Message) error {
+			attempts <- m.Attempt
+			return nil
+		})
+	}()
+	select {
+	case got := <-attempts:
+		if got != 2 {
+			t.Fatalf("attempt = %d, want 2", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("the retry was lost")
+	}
+}
@@ -0,0 +1,296 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy decides what happens when a job is due while its previous run
+// is still in progress.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops the due run.
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapAllow starts the due run alongside the running one.
+	OverlapAllow OverlapPolicy = "allow"
+	// OverlapQueue runs the due run once the current one finishes. At most one
+	// run is queued; further due runs are skipped.
+	OverlapQueue OverlapPolicy = "queue"
+)
+
+// ParseOverlapPolicy parses a policy name; empty means OverlapSkip.
+func ParseOverlapPolicy(s string) (OverlapPolicy, error) {
+	switch p := OverlapPolicy(s); p {
+	case "":
+		return OverlapSkip, nil
+	case OverlapSkip, OverlapAllow, OverlapQueue:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown overlap policy %q", s)
+	}
+}
+
+// Job is a named unit of scheduled work.
+type Job struct {
+	Name     string
+	Spec     string // schedule as accepted by ParseSchedule
+	Schedule Schedule
+	// Timeout bounds a single run; zero means no timeout.
+	Timeout time.Duration
+	// Jitter delays each run by a random duration in [0, Jitter) so replicas
+	// and jobs sharing a schedule do not fire at the same instant.
+	Jitter     time.Duration
+	Overlap    OverlapPolicy
+	RunOnStart bool
+	Run        func(ctx context.Context) error
+}
+
+// JobStatus is the observable state of a registered job.
+type JobStatus struct {
+	Name         string        `json:"name"`
+	Schedule     string        `json:"schedule"`
+	Overlap      OverlapPolicy `json:"overlap"`
+	Running      int           `json:"running"`
+	Queued       bool          `json:"queued"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	Skipped      int           `json:"skipped"`
+	Standby      int           `json:"standby"` // due runs left to the leader replica
+	LastStart    *time.Time    `json:"lastStart,omitempty"`
+	LastDuration string        `json:"lastDuration,omitempty"`
+	LastError    string        `json:"lastError,omitempty"`
+	LastSuccess  *time.Time    `json:"lastSuccess,omitempty"`
+	NextRun      *time.Time    `json:"nextRun,omitempty"`
+}
+
+type jobState struct {
+	job    Job
+	status JobStatus
+}
+
+// LeaderGate restricts job runs to the elected leader. LeaderContext returns
+// a context cancelled on loss of leadership, or ok=false on other replicas.
+type LeaderGate interface {
+	LeaderContext() (ctx context.Context, ok bool)
+}
+
+// Scheduler runs registered jobs on their schedules.
+type Scheduler struct {
+	mu     sync.Mutex
+	jobs   map[string]*jobState
+	order  []string
+	now    func() time.Time
+	wg     sync.WaitGroup
+	leader LeaderGate
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{jobs: map[string]*jobState{}, now: time.Now}
+}
+
+// SetLeader makes jobs run only while gate reports leadership, so a job runs
+// once per cluster however many replicas are scheduling it. Runs are
+// cancelled when leadership is lost.
+func (s *Scheduler) SetLeader(gate LeaderGate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = gate
+}
+
+// Register adds a job. The schedule is parsed from Spec when Schedule is nil.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	if job.Schedule == nil {
+		sched, err := ParseSchedule(job.Spec, nil)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		job.Schedule = sched
+	}
+	if job.Overlap == "" {
+		job.Overlap = OverlapSkip
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s already registered", job.Name)
+	}
+	s.jobs[job.Name] = &jobState{job: job, status: JobStatus{Name: job.Name, Schedule: job.Spec, Overlap: job.Overlap}}
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start runs every registered job until ctx is cancelled, then waits for
+// in-flight runs to return.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	names := append([]string(nil), s.order...)
+	s.mu.Unlock()
+
+	var loops sync.WaitGroup
+	for _, name := range names {
+		loops.Add(1)
+		go func(st *jobState) {
+			defer loops.Done()
+			s.loop(ctx, st)
+		}(s.jobs[name])
+	}
+	loops.Wait()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, st *jobState) {
+	if st.job.RunOnStart {
+		s.trigger(ctx, st)
+	}
+	for {
+		next := st.job.Schedule.Next(s.now())
+		if next.IsZero() {
+			log.Printf("scheduler: job %s has no future runs", st.job.Name)
+			return
+		}
+		if st.job.Jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(st.job.Jitter))))
+		}
+		s.mu.Lock()
+		st.status.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.trigger(ctx, st)
+		}
+	}
+}
+
+// trigger starts a run subject to the job's overlap policy.
+func (s *Scheduler) trigger(ctx context.Context, st *jobState) {
+	s.mu.Lock()
+	var leaderCtx context.Context
+	if s.leader != nil {
+		lctx, ok := s.leader.LeaderContext()
+		if !ok {
+			st.status.Standby++
+			s.mu.Unlock()
+			return
+		}
+		leaderCtx = lctx
+	}
+	if st.status.Running > 0 {
+		switch {
+		case st.job.Overlap == OverlapQueue && !st.status.Queued:
+			st.status.Queued = true
+			s.mu.Unlock()
+			return
+		case st.job.Overlap != OverlapAllow:
+			st.status.Skipped++
+			s.mu.Unlock()
+			return
+		}
+	}
+	st.status.Running++
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if leaderCtx != nil {
+			var cancel context.CancelFunc
+			ctx, cancel = leaderScoped(ctx, leaderCtx)
+			defer cancel()
+		}
+		for {
+			s.run(ctx, st)
+
+			s.mu.Lock()
+			if st.status.Queued && ctx.Err() == nil {
+				st.status.Queued = false
+				s.mu.Unlock()
+				continue
+			}
+			st.status.Running--
+			s.mu.Unlock()
+			return
+		}
+	}()
+}
+
+func (s *Scheduler) run(ctx context.Context, st *jobState) {
+	runCtx := ctx
+	if st.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, st.job.Timeout)
+		defer cancel()
+	}
+
+	start := s.now()
+	s.mu.Lock()
+	st.status.LastStart = &start
+	s.mu.Unlock()
+
+	err := safeRun(runCtx, st.job.Run)
+	elapsed := s.now().Sub(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.status.Runs++
+	st.status.LastDuration = elapsed.Round(time.Millisecond).String()
+	if err != nil {
+		st.status.Failures++
+		st.status.LastError = err.Error()
+		log.Printf("scheduler: job %s failed after %s: %v", st.job.Name, st.status.LastDuration, err)
+		return
+	}
+	st.status.LastError = ""
+	end := start.Add(elapsed)
+	st.status.LastSuccess = &end
+}
+
+// leaderScoped returns ctx carrying the leader's fencing token and cancelled
+// when either ctx or the leadership ends.
+func leaderScoped(ctx, leaderCtx context.Context) (context.Context, context.CancelFunc) {
+	if token, ok := FencingToken(leaderCtx); ok {
+		ctx = WithFencingToken(ctx, token)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(leaderCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+func safeRun(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// Statuses returns the status of every registered job, ordered by name.
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]JobStatus, 0, len(s.jobs))
+	for _, st := range s.jobs {
+		out = append(out, st.status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,179 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tickSchedule fires every few milliseconds to drive the scheduler in tests.
+type tickSchedule time.Duration
+
+func (t tickSchedule) Next(after time.Time) time.Time { return after.Add(time.Duration(t)) }
+
+func runScheduler(t *testing.T, s *Scheduler, d time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	s.Start(ctx)
+}
+
+func TestSchedulerRecordsStatus(t *testing.T) {
+	s := NewScheduler()
+	var calls atomic.Int32
+	err := s.Register(Job{
+		Name: "flaky", Spec: "tick", Schedule: tickSchedule(10 * time.Millisecond), RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			if calls.Add(1) == 1 {
+				return errors.New("boom")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Register(Job{Name: "flaky", Spec: "@hourly", Run: func(context.Context) error { return nil }}); err == nil {
+		t.Fatal("expected duplicate name error")
+	}
+
+	runScheduler(t, s, 100*time.Millisecond)
+
+	st := s.Statuses()[0]
+	if st.Runs < 2 || st.Failures != 1 || st.LastError != "" || st.LastSuccess == nil || st.LastDuration == "" {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
+
+func TestSchedulerOverlapPolicies(t *testing.T) {
+	slow := func(running, maxRunning *atomic.Int32, runs *atomic.Int32) func(context.Context) error {
+		return func(ctx context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			runs.Add(1)
+			time.Sleep(45 * time.Millisecond)
+			return nil
+		}
+	}
+
+	cases := []struct {
+		policy      OverlapPolicy
+		concurrent  bool
+		wantSkipped bool
+	}{
+		{OverlapSkip, false, true},
+		{OverlapQueue, false, true},
+		{OverlapAllow, true, false},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			var running, maxRunning, runs atomic.Int32
+			s := NewScheduler()
+			_ = s.Register(Job{
+				Name: "slow", Schedule: tickSchedule(10 * time.Millisecond), Overlap: tc.policy,
+				Run: slow(&running, &maxRunning, &runs),
+			})
+			runScheduler(t, s, 150*time.Millisecond)
+
+			st := s.Statuses()[0]
+			if (maxRunning.Load() > 1) != tc.concurrent {
+				t.Fatalf("max concurrent runs = %d", maxRunning.Load())
+			}
+			if (st.Skipped > 0) != tc.wantSkipped {
+				t.Fatalf("skipped = %d", st.Skipped)
+			}
+			if st.Running != 0 {
+				t.Fatalf("running after stop = %d", st.Running)
+			}
+		})
+	}
+}
+
+func TestSchedulerTimeoutAndPanic(t *testing.T) {
+	s := NewScheduler()
+	_ = s.Register(Job{
+		Name: "hang", Schedule: tickSchedule(time.Hour), RunOnStart: true, Timeout: 20 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	_ = s.Register(Job{
+		Name: "panic", Schedule: tickSchedule(time.Hour), RunOnStart: true,
+		Run: func(context.Context) error { panic("oops") },
+	})
+	runScheduler(t, s, 60*time.Millisecond)
+
+	for _, st := range s.Statuses() {
+		if st.Failures != 1 || st.LastError == "" {
+			t.Fatalf("%s: unexpected status %+v", st.Name, st)
+		}
+	}
+}
+
+type fakeLeader struct {
+	ctx context.Context
+	ok  atomic.Bool
+}
+
+func (f *fakeLeader) LeaderContext() (context.Context, bool) { return f.ctx, f.ok.Load() }
+
+func TestSchedulerRunsOnlyOnLeader(t *testing.T) {
+	lctx, lose := context.WithCancel(WithFencingToken(context.Background(), 7))
+	leader := &fakeLeader{ctx: lctx}
+
+	s := NewScheduler()
+	s.SetLeader(leader)
+	var runs atomic.Int32
+	var token atomic.Int64
+	var cancelled atomic.Bool
+	_ = s.Register(Job{
+		Name: "job", Schedule: tickSchedule(10 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			tok, _ := FencingToken(ctx)
+			token.Store(tok)
+			<-ctx.Done()
+			cancelled.Store(true)
+			return ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if runs.Load() != 0 {
+		t.Fatal("standby replica ran a job")
+	}
+	leader.ok.Store(true)
+	time.Sleep(50 * time.Millisecond)
+	if runs.Load() != 1 || token.Load() != 7 {
+		t.Fatalf("runs = %d, token = %d", runs.Load(), token.Load())
+	}
+
+	// Losing leadership cancels the in-flight run
+	leader.ok.Store(false)
+	lose()
+	time.Sleep(20 * time.Millisecond)
+	if !cancelled.Load() {
+		t.Fatal("run not cancelled on leadership loss")
+	}
+	cancel()
+	<-done
+	if st := s.Statuses()[0]; st.Standby == 0 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "net/netip"
+
+// sharedAddressSpace is carrier-grade NAT space, private in practice.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddress reports whether ip is reachable on the public internet.
+// Loopback, private, link-local (such as the cloud metadata endpoint
+// 169.254.169.254) and other special addresses are not: user-supplied URLs
+// must not reach them from inside the network.
+func IsPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
@@ -1,9 +1,276 @@
 package domain
 
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AlertKind is what an alert waits for.
+type AlertKind string
+
+const (
+	// AlertTargetPrice fires when the ETB price is at or below a fixed target.
+	AlertTargetPrice AlertKind = "target_price"
+	// AlertPercentDrop fires when the ETB price fell by a percentage from the baseline.
+	AlertPercentDrop AlertKind = "percent_drop"
+	// AlertAnyDrop fires when the ETB price is below the baseline.
+	AlertAnyDrop AlertKind = "any_drop"
+	// AlertBackInStock fires when a product that was out of stock is available.
+	AlertBackInStock AlertKind = "back_in_stock"
+	// AlertFastDelivery fires when the delivery estimate is within a number of days.
+	AlertFastDelivery AlertKind = "fast_delivery"
+)
+
+// Currencies an alert's prices can be given in.
+const (
+	// CurrencyETB is the default: the currency users see in the app.
+	CurrencyETB = "ETB"
+	// CurrencyUSD is the currency products are listed in.
+	CurrencyUSD = "USD"
+)
+
+// ValidAlertCurrency reports whether alerts can be evaluated in the currency.
+func ValidAlertCurrency(currency string) bool {
+	return currency == CurrencyETB || currency == CurrencyUSD
+}
+
+// DropCause says what lowered a product's price in the alert's currency.
+type DropCause string
+
+const (
+	// DropCauseSeller means the seller lowered the USD price.
+	DropCauseSeller DropCause = "seller"
+	// DropCauseFX means the USD price held and the USD/ETB rate fell.
+	DropCauseFX DropCause = "fx"
+	// DropCauseSellerAndFX means both the USD price and the rate fell.
+	DropCauseSellerAndFX DropCause = "seller_and_fx"
+)
+
+// AlertCondition describes when an alert fires. Only the field of its kind is used.
+type AlertCondition struct {
+	Kind AlertKind `json:"kind" bson:"kind"`
+	// TargetPrice is the price of a target_price alert, in the alert's currency.
+	TargetPrice float64 `json:"targetPrice,omitempty" bson:"target_price,omitempty"`
+	// DropPercent is the drop from the baseline a percent_drop alert waits for.
+	DropPercent float64 `json:"dropPercent,omitempty" bson:"drop_percent,omitempty"`
+	// MaxDeliveryDays is the slowest delivery a fast_delivery alert accepts.
+	MaxDeliveryDays int `json:"maxDeliveryDays,omitempty" bson:"max_delivery_days,omitempty"`
+}
+
+// Validate checks the parameters of the condition's kind.
+func (c AlertCondition) Validate() error {
+	switch c.Kind {
+	case AlertTargetPrice:
+		if !finite(c.TargetPrice) || c.TargetPrice <= 0 {
+			return fmt.Errorf("%w: targetPrice must be positive", ErrInvalidInput)
+		}
+	case AlertPercentDrop:
+		if !finite(c.DropPercent) || c.DropPercent <= 0 || c.DropPercent >= 100 {
+			return fmt.Errorf("%w: dropPercent must be between 0 and 100", ErrInvalidInput)
+		}
+	case AlertAnyDrop, AlertBackInStock:
+	case AlertFastDelivery:
+		if c.MaxDeliveryDays < 1 {
+			return fmt.Errorf("%w: maxDeliveryDays must be at least 1", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown alert kind %q", ErrInvalidInput, c.Kind)
+	}
+	return nil
+}
+
+// finite reports whether v is neither NaN nor infinite, which no comparison
+// rejects on its own.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// NeedsBaseline reports whether the condition compares against the product
+// as it was when the alert was created.
+func (c AlertCondition) NeedsBaseline() bool {
+	return c.Kind != AlertTargetPrice && c.Kind != AlertFastDelivery
+}
+
+// AlertBaseline is the product state recorded when an alert was created.
+type AlertBaseline struct {
+	Price Price `json:"price" bson:"price"`
+	// FXRate is the USD/ETB rate Price was converted at.
+	FXRate  float64 `json:"fxRate,omitempty" bson:"fx_rate,omitempty"`
+	InStock bool    `json:"inStock" bson:"in_stock"`
+	// DeliveryDays is the slowest estimated delivery; 0 when unknown.
+	DeliveryDays int       `json:"deliveryDays,omitempty" bson:"delivery_days,omitempty"`
+	RecordedAt   time.Time `json:"recordedAt" bson:"recorded_at"`
+}
+
 type Alert struct {
-	ID          string  `json:"alertId"`
-	UserID      string  `json:"userId"`
-	ProductID   string  `json:"productId"`
-	TargetPrice float64 `json:"targetPrice"`
-	IsActive    bool    `json:"isActive"`
+	ID        string `json:"alertId" bson:"_id"`
+	UserID    string `json:"userId" bson:"user_id"`
+	ProductID string `json:"productId" bson:"product_id"`
+	// ClientID is the API client the alert was created through; the
+	// client's webhooks are called when it triggers.
+	ClientID string `json:"clientId,omitempty" bson:"client_id,omitempty"`
+	// TargetPrice mirrors Condition.TargetPrice for target_price alerts.
+	TargetPrice float64 `json:"targetPrice" bson:"target_price"`
+	// Currency is the currency of the alert's prices; empty means ETB.
+	Currency  string         `json:"currency" bson:"currency,omitempty"`
+	Condition AlertCondition `json:"condition" bson:"condition"`
+	Baseline  *AlertBaseline `json:"baseline,omitempty" bson:"baseline,omitempty"`
+	IsActive  bool           `json:"isActive" bson:"is_active"`
+	CreatedAt time.Time      `json:"createdAt,omitempty" bson:"created_at,omitempty"`
+	// ExpiresAt, when set, is when the alert stops being evaluated.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expires_at,omitempty"`
+	// CooldownMinutes is the minimum time between two triggers of a re-arming alert.
+	CooldownMinutes int `json:"cooldownMinutes,omitempty" bson:"cooldown_minutes,omitempty"`
+	// Rearm keeps the alert active after it triggers: it fires again once its
+	// condition stopped being met and is met again. Otherwise it deactivates.
+	Rearm bool `json:"rearm" bson:"rearm"`
+	// Disarmed is set on a re-arming alert between a trigger and the
+	// condition no longer being met.
+	Disarmed        bool       `json:"disarmed,omitempty" bson:"disarmed,omitempty"`
+	TriggerCount    int        `json:"triggerCount,omitempty" bson:"trigger_count,omitempty"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt,omitempty" bson:"last_triggered_at,omitempty"`
+	// EndReason says why an inactive alert stopped.
+	EndReason AlertEndReason `json:"endReason,omitempty" bson:"end_reason,omitempty"`
+}
+
+// AlertEndReason says why an alert was deactivated.
+type AlertEndReason string
+
+const (
+	AlertEndedTriggered AlertEndReason = "triggered"
+	AlertEndedExpired   AlertEndReason = "expired"
+)
+
+// ValidateLifecycle checks the alert's expiry and cooldown.
+func (a *Alert) ValidateLifecycle(now time.Time) error {
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidInput)
+	}
+	if a.CooldownMinutes < 0 {
+		return fmt.Errorf("%w: cooldownMinutes must not be negative", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Expired reports whether the alert's expiry has passed.
+func (a *Alert) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+// CoolingDown reports whether the alert triggered less than its cooldown ago.
+func (a *Alert) CoolingDown(now time.Time) bool {
+	if a.LastTriggeredAt == nil || a.CooldownMinutes == 0 {
+		return false
+	}
+	return now.Before(a.LastTriggeredAt.Add(time.Duration(a.CooldownMinutes) * time.Minute))
+}
+
+// MarkTriggered records a trigger at now: a re-arming alert is disarmed
+// until its condition stops being met, any other alert is deactivated.
+func (a *Alert) MarkTriggered(now time.Time) {
+	a.TriggerCount++
+	a.LastTriggeredAt = &now
+	if a.Rearm {
+		a.Disarmed = true
+		return
+	}
+	a.IsActive = false
+	a.EndReason = AlertEndedTriggered
+}
+
+// Expire deactivates the alert because its expiry passed.
+func (a *Alert) Expire() {
+	a.IsActive = false
+	a.EndReason = AlertEndedExpired
+}
+
+// EffectiveCondition returns the alert's condition. Alerts stored before
+// conditions existed are target_price alerts on TargetPrice.
+func (a *Alert) EffectiveCondition() AlertCondition {
+	if a.Condition.Kind == "" {
+		return AlertCondition{Kind: AlertTargetPrice, TargetPrice: a.TargetPrice}
+	}
+	return a.Condition
+}
+
+// TargetCurrency returns the currency the alert is evaluated in. Alerts
+// stored before currencies existed are in ETB.
+func (a *Alert) TargetCurrency() string {
+	if a.Currency == "" {
+		return CurrencyETB
+	}
+	return a.Currency
+}
+
+// Threshold is the price, in the alert's currency, at or below which a
+// price alert fires, or 0 for alerts that are not about price.
+func (a *Alert) Threshold() float64 {
+	c := a.EffectiveCondition()
+	switch {
+	case c.Kind == AlertTargetPrice:
+		return c.TargetPrice
+	case c.Kind == AlertPercentDrop && a.Baseline != nil:
+		return a.Baseline.Price.Amount(a.TargetCurrency()) * (1 - c.DropPercent/100)
+	case c.Kind == AlertAnyDrop && a.Baseline != nil:
+		return a.Baseline.Price.Amount(a.TargetCurrency())
+	}
+	return 0
+}
+
+// DropCause explains a price alert's drop from its baseline: a lower USD
+// price is the seller's doing, a lower rate than the baseline's is the
+// exchange rate's. The rate only matters for ETB alerts. It returns ""
+// when the alert has no baseline or neither moved.
+func (a *Alert) DropCause(price Price, rate float64) DropCause {
+	b := a.Baseline
+	if b == nil {
+		return ""
+	}
+	seller := price.USD > 0 && price.USD < b.Price.USD
+	baseRate := b.FXRate
+	if baseRate == 0 && b.Price.USD > 0 {
+		baseRate = b.Price.ETB / b.Price.USD
+	}
+	fx := a.TargetCurrency() == CurrencyETB && rate > 0 && rate < baseRate
+	switch {
+	case seller && fx:
+		return DropCauseSellerAndFX
+	case seller:
+		return DropCauseSeller
+	case fx:
+		return DropCauseFX
+	}
+	return ""
+}
+
+// Met reports whether the product, at the given price, satisfies the alert.
+func (a *Alert) Met(p *Product, price Price) bool {
+	c := a.EffectiveCondition()
+	switch c.Kind {
+	case AlertTargetPrice, AlertPercentDrop:
+		t, v := a.Threshold(), price.Amount(a.TargetCurrency())
+		return t > 0 && v > 0 && v <= t
+	case AlertAnyDrop:
+		v := price.Amount(a.TargetCurrency())
+		return a.Baseline != nil && v > 0 && v < a.Baseline.Price.Amount(a.TargetCurrency())
+	case AlertBackInStock:
+		return a.Baseline != nil && !a.Baseline.InStock && p.InStock()
+	case AlertFastDelivery:
+		days := p.DeliveryDays()
+		return days > 0 && days <= c.MaxDeliveryDays
+	}
+	return false
+}
+
+// AlertTrigger records an alert whose condition was met.
+type AlertTrigger struct {
+	Alert   *Alert   `json:"alert"`
+	Product *Product `json:"product,omitempty"`
+	Price   Price    `json:"price"`
+	// FXRate is the USD/ETB rate Price was converted at.
+	FXRate float64 `json:"fxRate"`
+	// Cause says whether a price drop came from the seller or the rate.
+	Cause       DropCause `json:"cause,omitempty"`
+	TriggeredAt time.Time `json:"triggeredAt"`
 }
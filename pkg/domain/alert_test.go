@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAlertConditionValidate(t *testing.T) {
+	for _, c := range []AlertCondition{
+		{Kind: "price_went_sideways"},
+		{Kind: AlertTargetPrice},
+		{Kind: AlertPercentDrop, DropPercent: 100},
+		{Kind: AlertFastDelivery},
+		{Kind: AlertTargetPrice, TargetPrice: math.NaN()},
+		{Kind: AlertTargetPrice, TargetPrice: math.Inf(1)},
+		{Kind: AlertPercentDrop, DropPercent: math.NaN()},
+	} {
+		if err := c.Validate(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%+v: expected invalid input, got %v", c, err)
+		}
+	}
+	for _, c := range []AlertCondition{
+		{Kind: AlertTargetPrice, TargetPrice: 900},
+		{Kind: AlertPercentDrop, DropPercent: 10},
+		{Kind: AlertAnyDrop},
+		{Kind: AlertBackInStock},
+		{Kind: AlertFastDelivery, MaxDeliveryDays: 7},
+	} {
+		if err := c.Validate(); err != nil {
+			t.Errorf("%+v: %v", c, err)
+		}
+	}
+}
+
+func TestAlertMet(t *testing.T) {
+	zero, some := 0, 3
+	baseline := &AlertBaseline{Price: Price{ETB: 1000}, InStock: false}
+	cases := []struct {
+		name  string
+		alert Alert
+		p     Product
+		etb   float64
+		want  bool
+	}{
+		{"LegacyTargetHit", Alert{TargetPrice: 900}, Product{}, 900, true},
+		{"LegacyTargetMiss", Alert{TargetPrice: 900}, Product{}, 901, false},
+		{"PercentDropHit", Alert{Condition: AlertCondition{Kind: AlertPercentDrop, DropPercent: 10}, Baseline: baseline}, Product{}, 900, true},
+		{"PercentDropMiss", Alert{Condition: AlertCondition{Kind: AlertPercentDrop, DropPercent: 10}, Baseline: baseline}, Product{}, 950, false},
+		{"AnyDropHit", Alert{Condition: AlertCondition{Kind: AlertAnyDrop}, Baseline: baseline}, Product{}, 999, true},
+		{"AnyDropSamePrice", Alert{Condition: AlertCondition{Kind: AlertAnyDrop}, Baseline: baseline}, Product{}, 1000, false},
+		{"AnyDropNoBaseline", Alert{Condition: AlertCondition{Kind: AlertAnyDrop}}, Product{}, 1, false},
+		{"BackInStock", Alert{Condition: AlertCondition{Kind: AlertBackInStock}, Baseline: baseline}, Product{Stock: &some}, 1000, true},
+		{"StillOutOfStock", Alert{Condition: AlertCondition{Kind: AlertBackInStock}, Baseline: baseline}, Product{Stock: &zero}, 1000, false},
+		{"FastDelivery", Alert{Condition: AlertCondition{Kind: AlertFastDelivery, MaxDeliveryDays: 10}}, Product{Delivery: &DeliveryWindow{MinDays: 5, MaxDays: 10}}, 1000, true},
+		{"SlowDelivery", Alert{Condition: AlertCondition{Kind: AlertFastDelivery, MaxDeliveryDays: 10}}, Product{Delivery: &DeliveryWindow{MinDays: 5, MaxDays: 12}}, 1000, false},
+		{"UnknownDelivery", Alert{Condition: AlertCondition{Kind: AlertFastDelivery, MaxDeliveryDays: 10}}, Product{}, 1000, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.alert.Met(&tc.p, Price{ETB: tc.etb}); got != tc.want {
+				t.Fatalf("Met = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAlertCurrencyAndDropCause(t *testing.T) {
+	baseline := &AlertBaseline{Price: Price{USD: 10, ETB: 1200}, FXRate: 120}
+	usd := Alert{Currency: CurrencyUSD, Condition: AlertCondition{Kind: AlertTargetPrice, TargetPrice: 9}, Baseline: baseline}
+	etb := Alert{Condition: AlertCondition{Kind: AlertAnyDrop}, Baseline: baseline}
+
+	// The rate fell: cheaper in birr, unchanged in dollars
+	fxOnly := Price{USD: 10, ETB: 1100}
+	if usd.Met(&Product{}, fxOnly) || !etb.Met(&Product{}, fxOnly) {
+		t.Fatal("alerts must compare in their own currency")
+	}
+	if c := etb.DropCause(fxOnly, 110); c != DropCauseFX {
+		t.Fatalf("cause = %q, want fx", c)
+	}
+
+	cut := Price{USD: 8, ETB: 880}
+	if !usd.Met(&Product{}, cut) {
+		t.Fatal("USD target not met")
+	}
+	if c := usd.DropCause(cut, 110); c != DropCauseSeller {
+		t.Fatalf("USD alerts ignore the rate, got %q", c)
+	}
+	if c := etb.DropCause(cut, 110); c != DropCauseSellerAndFX {
+		t.Fatalf("cause = %q, want seller_and_fx", c)
+	}
+
+	// Baselines without a stored rate derive it from the recorded price
+	legacy := Alert{Baseline: &AlertBaseline{Price: Price{USD: 10, ETB: 1200}}}
+	if c := legacy.DropCause(Price{USD: 9, ETB: 1080}, 120); c != DropCauseSeller {
+		t.Fatalf("cause = %q, want seller", c)
+	}
+	if (&Alert{}).DropCause(cut, 110) != "" {
+		t.Fatal("no baseline, no cause")
+	}
+}
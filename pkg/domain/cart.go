@@ -0,0 +1,142 @@
+package domain
+
+import "math"
+
+// Cart limits.
+const (
+	CartMaxItems    = 50
+	CartMaxQuantity = 99
+)
+
+// CartItem is a product and how many units of it the user plans to buy.
+type CartItem struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+}
+
+// CustomsRules are the landed-cost rules Ethiopian customs apply to one
+// consignment. Taxes are assessed on the combined customs value of the goods
+// and their shipping, so several items bought together can cross a
+// threshold none of them crosses alone. Rates are fractions, e.g. 0.15.
+type CustomsRules struct {
+	// DutyFreeUSD is the customs value up to which a consignment is not taxed.
+	DutyFreeUSD float64
+	// DutyRate is charged on the customs value.
+	DutyRate float64
+	// ExciseRate is charged on the customs value plus duty.
+	ExciseRate float64
+	// VATRate is charged on the customs value plus duty and excise.
+	VATRate float64
+	// SurtaxRate is charged on the customs value plus duty, excise and VAT.
+	SurtaxRate float64
+	// WithholdingRate is an advance income tax on the customs value.
+	WithholdingRate float64
+	// CommercialThresholdUSD is the customs value above which a consignment
+	// is cleared as a commercial import and pays ClearanceFeeUSD.
+	CommercialThresholdUSD float64
+	ClearanceFeeUSD        float64
+}
+
+// DefaultCustomsRules are the general-goods rates for personal imports.
+func DefaultCustomsRules() CustomsRules {
+	return CustomsRules{
+		DutyFreeUSD:            50,
+		DutyRate:               0.30,
+		ExciseRate:             0,
+		VATRate:                0.15,
+		SurtaxRate:             0.10,
+		WithholdingRate:        0.03,
+		CommercialThresholdUSD: 1000,
+		ClearanceFeeUSD:        25,
+	}
+}
+
+// CustomsAssessment is the tax on a consignment, in USD.
+type CustomsAssessment struct {
+	CustomsValue float64
+	DutyFree     bool
+	Commercial   bool
+	Duty         float64
+	Excise       float64
+	VAT          float64
+	Surtax       float64
+	Withholding  float64
+	ClearanceFee float64
+}
+
+// Total is everything customs charge.
+func (a CustomsAssessment) Total() float64 {
+	return roundUSD(a.Duty + a.Excise + a.VAT + a.Surtax + a.Withholding + a.ClearanceFee)
+}
+
+// Assess applies the rules to a consignment's customs value in USD.
+func (r CustomsRules) Assess(customsValueUSD float64) CustomsAssessment {
+	a := CustomsAssessment{CustomsValue: roundUSD(customsValueUSD)}
+	if customsValueUSD <= r.DutyFreeUSD {
+		a.DutyFree = true
+		return a
+	}
+	v := customsValueUSD
+	duty := v * r.DutyRate
+	excise := (v + duty) * r.ExciseRate
+	vat := (v + duty + excise) * r.VATRate
+	surtax := (v + duty + excise + vat) * r.SurtaxRate
+	a.Duty, a.Excise, a.VAT, a.Surtax = roundUSD(duty), roundUSD(excise), roundUSD(vat), roundUSD(surtax)
+	a.Withholding = roundUSD(v * r.WithholdingRate)
+	if r.CommercialThresholdUSD > 0 && v > r.CommercialThresholdUSD {
+		a.Commercial = true
+		a.ClearanceFee = r.ClearanceFeeUSD
+	}
+	return a
+}
+
+func roundUSD(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// CartEstimate is the landed cost of a cart.
+type CartEstimate struct {
+	Sellers  []CartSellerGroup `json:"sellers"`
+	Goods    Price             `json:"goods"`
+	Shipping Price             `json:"shipping"`
+	Customs  CustomsBreakdown  `json:"customs"`
+	Total    Price             `json:"total"`
+	// FXRate is the USD/ETB rate the estimate was converted at.
+	FXRate float64 `json:"fxRate"`
+}
+
+// CartSellerGroup is the part of a cart one seller ships as one parcel.
+type CartSellerGroup struct {
+	SellerID   string     `json:"sellerId"`
+	SellerName string     `json:"sellerName,omitempty"`
+	Items      []CartLine `json:"items"`
+	Subtotal   Price      `json:"subtotal"`
+	Shipping   Price      `json:"shipping"`
+	// ShippingMethod is the method the shipping cost is based on.
+	ShippingMethod string `json:"shippingMethod,omitempty"`
+	// DeliveryDays is the slowest estimate of the group's items; 0 when unknown.
+	DeliveryDays int `json:"deliveryDays,omitempty"`
+}
+
+// CartLine is one product of a cart.
+type CartLine struct {
+	ProductID string `json:"productId"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice Price  `json:"unitPrice"`
+	Subtotal  Price  `json:"subtotal"`
+}
+
+// CustomsBreakdown is a CustomsAssessment converted to prices.
+type CustomsBreakdown struct {
+	CustomsValue Price `json:"customsValue"`
+	DutyFree     bool  `json:"dutyFree"`
+	Commercial   bool  `json:"commercial"`
+	Duty         Price `json:"duty"`
+	Excise       Price `json:"excise"`
+	VAT          Price `json:"vat"`
+	Surtax       Price `json:"surtax"`
+	Withholding  Price `json:"withholding"`
+	ClearanceFee Price `json:"clearanceFee"`
+	Total        Price `json:"total"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "testing"
+
+func TestCustomsRules_Assess(t *testing.T) {
+	rules := CustomsRules{DutyFreeUSD: 50, DutyRate: 0.30, VATRate: 0.15, SurtaxRate: 0.10, WithholdingRate: 0.03, CommercialThresholdUSD: 1000, ClearanceFeeUSD: 25}
+
+	if a := rules.Assess(50); !a.DutyFree || a.Total() != 0 {
+		t.Errorf("a consignment at the threshold should be duty free: %+v", a)
+	}
+
+	a := rules.Assess(100)
+	// duty 30, VAT 19.5 on 130, surtax 14.95 on 149.5, withholding 3
+	if a.DutyFree || a.Duty != 30 || a.VAT != 19.5 || a.Surtax != 14.95 || a.Withholding != 3 || a.Commercial {
+		t.Errorf("unexpected assessment %+v", a)
+	}
+	if a.Total() != 67.45 {
+		t.Errorf("expected 67.45, got %v", a.Total())
+	}
+
+	if a := rules.Assess(1500); !a.Commercial || a.ClearanceFee != 25 {
+		t.Errorf("expected a commercial clearance fee: %+v", a)
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// DealQuality classifies a current price against the product's own history.
+type DealQuality string
+
+const (
+	// DealGenuineLow means the price is at or near the lowest seen in the window.
+	DealGenuineLow DealQuality = "genuine_low"
+	// DealNormal means the price is in line with the product's usual price.
+	DealNormal DealQuality = "normal"
+	// DealInflated means the price or its claimed "original price" is above
+	// what the product usually sold for, so the advertised discount is not real.
+	DealInflated DealQuality = "inflated"
+)
+
+// DealVerdict is the outcome of a deal-quality analysis.
+type DealVerdict struct {
+	Quality        DealQuality `json:"quality"`
+	Confidence     float64     `json:"confidence"`
+	Reason         string      `json:"reason"`
+	WindowStart    time.Time   `json:"windowStart"`
+	WindowEnd      time.Time   `json:"windowEnd"`
+	SampleDays     int         `json:"sampleDays"`
+	ReferenceUSD   float64     `json:"referenceUsd"`
+	LowestUSD      float64     `json:"lowestUsd"`
+	HighestUSD     float64     `json:"highestUsd"`
+	CurrentUSD     float64     `json:"currentUsd"`
+	ClaimedOrigUSD float64     `json:"claimedOriginalUsd,omitempty"`
+}
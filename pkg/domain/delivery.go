@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// deliveryPattern matches estimates such as "15-30 days", "7 days" or "2 – 3 weeks".
+var deliveryPattern = regexp.MustCompile(`(?i)(\d+)\s*(?:[-–~]|to)?\s*(\d+)?\s*(business\s+days?|days?|weeks?)`)
+
+// ParseDeliveryEstimate converts a free-text estimate into a DeliveryWindow
+// whose arrival dates are counted from "from". Business days skip weekends,
+// so the window is in calendar days. It reports false when the text does not
+// contain a recognizable day or week range.
+func ParseDeliveryEstimate(text string, from time.Time) (DeliveryWindow, bool) {
+	m := deliveryPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return DeliveryWindow{}, false
+	}
+
+	lo, err := strconv.Atoi(m[1])
+	if err != nil {
+		return DeliveryWindow{}, false
+	}
+	hi := lo
+	if m[2] != "" {
+		if hi, err = strconv.Atoi(m[2]); err != nil {
+			return DeliveryWindow{}, false
+		}
+	}
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	switch unit := strings.ToLower(m[3]); {
+	case strings.HasPrefix(unit, "week"):
+		lo, hi = lo*7, hi*7
+	case strings.HasPrefix(unit, "business"):
+		lo, hi = calendarDays(lo, from), calendarDays(hi, from)
+	}
+
+	return NewDeliveryWindow(lo, hi, from), true
+}
+
+// calendarDays returns how many calendar days after "from" the n-th business
+// day falls, counting Monday to Friday.
+func calendarDays(n int, from time.Time) int {
+	days := 0
+	for day := from; n > 0; {
+		day = day.AddDate(0, 0, 1)
+		days++
+		if wd := day.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			n--
+		}
+	}
+	return days
+}
+
+// NewDeliveryWindow builds a window of minDays..maxDays counted from "from".
+func NewDeliveryWindow(minDays, maxDays int, from time.Time) DeliveryWindow {
+	return DeliveryWindow{
+		MinDays:         minDays,
+		MaxDays:         maxDays,
+		EarliestArrival: from.AddDate(0, 0, minDays),
+		LatestArrival:   from.AddDate(0, 0, maxDays),
+	}
+}
+
+// ArrivesBefore reports whether the latest expected arrival is strictly before t.
+func (w DeliveryWindow) ArrivesBefore(t time.Time) bool {
+	return w.LatestArrival.Before(t)
+}
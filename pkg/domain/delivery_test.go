@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDeliveryEstimate(t *testing.T) {
+	// A Thursday
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		text     string
+		ok       bool
+		min, max int
+	}{
+		{"15-30 days", true, 15, 30},
+		{"7 days", true, 7, 7},
+		{"10 to 20 business days", true, 14, 28},
+		{"1 business day", true, 1, 1},
+		{"2 business days", true, 4, 4},
+		{"0 business days", true, 0, 0},
+		{"2-3 weeks", true, 14, 21},
+		{"30-15 days", true, 15, 30},
+		{"ships soon", false, 0, 0},
+		{"", false, 0, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.text, func(t *testing.T) {
+			w, ok := ParseDeliveryEstimate(tc.text, from)
+			if ok != tc.ok {
+				t.Fatalf("ok mismatch: got %v want %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if w.MinDays != tc.min || w.MaxDays != tc.max {
+				t.Errorf("days mismatch: got %d-%d want %d-%d", w.MinDays, w.MaxDays, tc.min, tc.max)
+			}
+			if !w.EarliestArrival.Equal(from.AddDate(0, 0, tc.min)) {
+				t.Errorf("earliest arrival mismatch: got %v", w.EarliestArrival)
+			}
+			if !w.LatestArrival.Equal(from.AddDate(0, 0, tc.max)) {
+				t.Errorf("latest arrival mismatch: got %v", w.LatestArrival)
+			}
+		})
+	}
+}
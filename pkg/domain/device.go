@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// DevicePlatform is the OS a push token was issued for.
+type DevicePlatform string
+
+const (
+	PlatformAndroid DevicePlatform = "android"
+	PlatformIOS     DevicePlatform = "ios"
+	PlatformWeb     DevicePlatform = "web"
+)
+
+// Valid reports whether p is a supported platform.
+func (p DevicePlatform) Valid() bool {
+	switch p {
+	case PlatformAndroid, PlatformIOS, PlatformWeb:
+		return true
+	}
+	return false
+}
+
+// ErrDeviceNotFound is returned when a device token is not registered to the user.
+var ErrDeviceNotFound = errors.New("device not found")
+
+// Device is a user's app installation that can receive FCM push messages.
+// The FCM token identifies the device.
+type Device struct {
+	Token      string         `json:"token" bson:"_id"`
+	UserID     string         `json:"userId" bson:"user_id"`
+	Platform   DevicePlatform `json:"platform" bson:"platform"`
+	AppVersion string         `json:"appVersion,omitempty" bson:"app_version,omitempty"`
+	Locale     string         `json:"locale,omitempty" bson:"locale,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt" bson:"created_at"`
+	LastSeenAt time.Time      `json:"lastSeenAt" bson:"last_seen_at"`
+}
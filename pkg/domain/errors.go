@@ -0,0 +1,24 @@
+package domain
+
+import "errors"
+
+// ErrProductNotFound is returned when a product ID is unknown to the upstream catalog.
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrInvalidInput is wrapped by use cases to reject malformed requests.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrAlertExists is returned when a user already has an active alert on a product.
+var ErrAlertExists = errors.New("active alert already exists")
+
+// ErrAlertNotFound is returned when an alert does not exist or belongs to
+// another user.
+var ErrAlertNotFound = errors.New("alert not found")
+
+// ErrAlertLimit is returned when a user has reached the active alert limit.
+var ErrAlertLimit = errors.New("active alert limit reached")
+
+// ErrStaleFencingToken is returned when a write carries a fencing token older
+// than one the resource has already accepted: the writer lost its lease to a
+// newer holder while it was working.
+var ErrStaleFencingToken = errors.New("stale fencing token")
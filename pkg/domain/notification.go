@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ChannelKind identifies a notification delivery channel.
+type ChannelKind string
+
+const (
+	ChannelPush     ChannelKind = "push"
+	ChannelEmail    ChannelKind = "email"
+	ChannelTelegram ChannelKind = "telegram"
+	ChannelSMS      ChannelKind = "sms"
+)
+
+// NotificationKind identifies what a notification is about.
+type NotificationKind string
+
+const (
+	NotificationPriceAlert  NotificationKind = "price_alert"
+	NotificationBackInStock NotificationKind = "back_in_stock"
+	NotificationDigest      NotificationKind = "digest"
+	// NotificationDeliveryAlert reports a faster delivery estimate.
+	NotificationDeliveryAlert NotificationKind = "delivery_alert"
+	// NotificationSavedSearch reports new products matching a saved search.
+	NotificationSavedSearch NotificationKind = "saved_search"
+)
+
+// DeliveryState is the state of a notification on one channel.
+type DeliveryState string
+
+const (
+	DeliveryPending DeliveryState = "pending"
+	DeliverySent    DeliveryState = "sent"
+	// DeliveryFailed is final: the channel rejected the message or retries ran out.
+	DeliveryFailed DeliveryState = "failed"
+	// DeliverySkipped means the channel had nowhere to deliver to.
+	DeliverySkipped DeliveryState = "skipped"
+)
+
+// NotificationStatus is the overall state of an outbox entry.
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationDone    NotificationStatus = "done"
+)
+
+var (
+	// ErrDeliveryRejected marks a permanent channel failure that must not be retried.
+	ErrDeliveryRejected = errors.New("delivery rejected")
+	// ErrNoRecipient means the user has no address, device or chat for the channel.
+	ErrNoRecipient = errors.New("no recipient for channel")
+)
+
+// ChannelDelivery tracks delivery of a notification on one channel.
+type ChannelDelivery struct {
+	Channel       ChannelKind   `json:"channel" bson:"channel"`
+	State         DeliveryState `json:"state" bson:"state"`
+	Attempts      int           `json:"attempts" bson:"attempts"`
+	LastError     string        `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	NextAttemptAt time.Time     `json:"nextAttemptAt" bson:"next_attempt_at"`
+	SentAt        *time.Time    `json:"sentAt,omitempty" bson:"sent_at,omitempty"`
+	ProviderID    string        `json:"providerId,omitempty" bson:"provider_id,omitempty"`
+}
+
+// IdempotencyKey is the key a channel uses to deduplicate sends of this delivery.
+func (d ChannelDelivery) IdempotencyKey(n *Notification) string {
+	return n.IdempotencyKey + ":" + string(d.Channel)
+}
+
+// Notification is an outbox entry: the intent to tell a user something,
+// with its delivery state on every channel.
+type Notification struct {
+	ID             string            `json:"id" bson:"_id"`
+	IdempotencyKey string            `json:"idempotencyKey" bson:"idempotency_key"`
+	UserID         string            `json:"userId" bson:"user_id"`
+	Kind           NotificationKind  `json:"kind" bson:"kind"`
+	Title          string            `json:"title" bson:"title"`
+	Body           string            `json:"body" bson:"body"`
+	Data           map[string]string `json:"data,omitempty" bson:"data,omitempty"`
+	// Items lists the products of a digest notification.
+	Items      []NotificationItem `json:"items,omitempty" bson:"items,omitempty"`
+	Deliveries []ChannelDelivery  `json:"deliveries" bson:"deliveries"`
+	Status     NotificationStatus `json:"status" bson:"status"`
+	// NextAttemptAt is the earliest pending delivery's next attempt.
+	NextAttemptAt time.Time `json:"nextAttemptAt" bson:"next_attempt_at"`
+	CreatedAt     time.Time `json:"createdAt" bson:"created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// NotificationItem is one product line of a notification.
+type NotificationItem struct {
+	ProductID string  `json:"productId" bson:"product_id"`
+	Title     string  `json:"title" bson:"title"`
+	ImageURL  string  `json:"imageUrl,omitempty" bson:"image_url,omitempty"`
+	URL       string  `json:"url,omitempty" bson:"url,omitempty"`
+	PriceETB  float64 `json:"priceEtb" bson:"price_etb"`
+	// PreviousETB is the earlier price the item is compared with; 0 if unknown.
+	PreviousETB float64 `json:"previousEtb,omitempty" bson:"previous_etb,omitempty"`
+}
+
+// Refresh recomputes Status and NextAttemptAt from the deliveries.
+func (n *Notification) Refresh() {
+	n.Status = NotificationDone
+	n.NextAttemptAt = time.Time{}
+	for _, d := range n.Deliveries {
+		if d.State != DeliveryPending {
+			continue
+		}
+		if n.Status == NotificationDone || d.NextAttemptAt.Before(n.NextAttemptAt) {
+			n.NextAttemptAt = d.NextAttemptAt
+		}
+		n.Status = NotificationPending
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// FXRateSnapshot is a recorded exchange rate observation.
+type FXRateSnapshot struct {
+	ID         string    `json:"id" bson:"_id"`
+	From       string    `json:"from" bson:"from"`
+	To         string    `json:"to" bson:"to"`
+	Rate       float64   `json:"rate" bson:"rate"`
+	RecordedAt time.Time `json:"recordedAt" bson:"recorded_at"`
+}
+
+// Recommendation tells the shopper whether to buy now or wait for a drop.
+type Recommendation string
+
+const (
+	RecommendBuyNow Recommendation = "buy_now"
+	RecommendWait   Recommendation = "wait"
+)
+
+// PredictionModel exposes the fitted parameters behind a prediction.
+type PredictionModel struct {
+	SampleDays      int     `json:"sampleDays"`
+	TrendUSDPerDay  float64 `json:"trendUsdPerDay"`
+	CyclePeriodDays int     `json:"cyclePeriodDays,omitempty"`
+	ResidualUSD     float64 `json:"residualUsd"`
+	FXRate          float64 `json:"fxRate"`
+	FXTrendPerDay   float64 `json:"fxTrendPerDay"`
+}
+
+// PricePrediction estimates whether a product's ETB price is likely to drop
+// within the horizon, using a seasonal baseline plus trend model.
+type PricePrediction struct {
+	HorizonDays     int             `json:"horizonDays"`
+	Recommendation  Recommendation  `json:"recommendation"`
+	DropProbability float64         `json:"dropProbability"`
+	CurrentETB      float64         `json:"currentEtb"`
+	ExpectedLowETB  float64         `json:"expectedLowEtb"`
+	ExpectedHighETB float64         `json:"expectedHighEtb"`
+	BestDay         int             `json:"bestDay"`
+	Rationale       []string        `json:"rationale"`
+	Model           PredictionModel `json:"model"`
+}
@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+	// Embedded zone data so user time zones resolve on minimal images
+	_ "time/tzdata"
+)
+
+// DefaultTimeZone is used for users who have not chosen a time zone.
+const DefaultTimeZone = "Africa/Addis_Ababa"
+
+// DeliveryMode decides whether notifications are sent as they happen or held
+// for the user's digest time.
+type DeliveryMode string
+
+const (
+	DeliveryInstant DeliveryMode = "instant"
+	DeliveryDigest  DeliveryMode = "digest"
+)
+
+// DefaultLocale is the language of users who have not chosen one.
+const DefaultLocale = "en"
+
+// SupportedLocales are the languages notifications can be written in.
+var SupportedLocales = []string{"en", "am"}
+
+// maxDailyCap bounds the per-day notification limit a user can choose.
+const maxDailyCap = 100
+
+// QuietHours is a daily local-time window in which nothing is delivered.
+// Start after End spans midnight, e.g. 22:00-07:00.
+type QuietHours struct {
+	Enabled bool   `json:"enabled" bson:"enabled"`
+	Start   string `json:"start" bson:"start"` // HH:MM
+	End     string `json:"end" bson:"end"`     // HH:MM
+}
+
+// NotificationPreferences controls how and when a user is notified.
+type NotificationPreferences struct {
+	UserID     string        `json:"userId" bson:"_id"`
+	Channels   []ChannelKind `json:"channels" bson:"channels"`
+	TimeZone   string        `json:"timeZone" bson:"time_zone"`
+	QuietHours QuietHours    `json:"quietHours" bson:"quiet_hours"`
+	// MaxPerDay caps notifications per local day; 0 means no cap.
+	MaxPerDay int          `json:"maxPerDay" bson:"max_per_day"`
+	Mode      DeliveryMode `json:"mode" bson:"mode"`
+	// DigestTime is when held notifications go out in digest mode (HH:MM).
+	DigestTime string `json:"digestTime" bson:"digest_time"`
+	// Email is the address for the email channel; empty disables it.
+	Email string `json:"email,omitempty" bson:"email,omitempty"`
+	// Locale is the language notifications are written in, e.g. "en" or "am".
+	Locale    string    `json:"locale" bson:"locale"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// DefaultPreferences returns the preferences of a user who has not set any.
+func DefaultPreferences(userID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:     userID,
+		Channels:   []ChannelKind{ChannelPush, ChannelEmail, ChannelTelegram, ChannelSMS},
+		TimeZone:   DefaultTimeZone,
+		QuietHours: QuietHours{Enabled: true, Start: "22:00", End: "07:00"},
+		MaxPerDay:  10,
+		Mode:       DeliveryInstant,
+		DigestTime: "18:00",
+		Locale:     DefaultLocale,
+	}
+}
+
+// Validate checks the preferences and fills defaults for empty fields.
+func (p *NotificationPreferences) Validate() error {
+	if p.TimeZone == "" {
+		p.TimeZone = DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(p.TimeZone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidInput, p.TimeZone)
+	}
+	if p.Mode == "" {
+		p.Mode = DeliveryInstant
+	}
+	if p.Mode != DeliveryInstant && p.Mode != DeliveryDigest {
+		return fmt.Errorf("%w: mode must be instant or digest", ErrInvalidInput)
+	}
+	if p.DigestTime == "" {
+		p.DigestTime = "18:00"
+	}
+	clocks := []string{p.DigestTime}
+	if p.QuietHours.Enabled {
+		clocks = append(clocks, p.QuietHours.Start, p.QuietHours.End)
+	}
+	for _, hm := range clocks {
+		if _, err := parseClock(hm); err != nil {
+			return fmt.Errorf("%w: times must be HH:MM", ErrInvalidInput)
+		}
+	}
+	if p.Email != "" {
+		addr, err := mail.ParseAddress(p.Email)
+		if err != nil {
+			return fmt.Errorf("%w: invalid email address", ErrInvalidInput)
+		}
+		p.Email = addr.Address
+	}
+	if p.Locale == "" {
+		p.Locale = DefaultLocale
+	}
+	if !supportedLocale(p.Locale) {
+		return fmt.Errorf("%w: unsupported locale %q", ErrInvalidInput, p.Locale)
+	}
+	if p.MaxPerDay < 0 || p.MaxPerDay > maxDailyCap {
+		return fmt.Errorf("%w: maxPerDay must be between 0 and %d", ErrInvalidInput, maxDailyCap)
+	}
+	seen := map[ChannelKind]bool{}
+	for _, c := range p.Channels {
+		switch c {
+		case ChannelPush, ChannelEmail, ChannelTelegram, ChannelSMS:
+		default:
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidInput, c)
+		}
+		if seen[c] {
+			return fmt.Errorf("%w: duplicate channel %q", ErrInvalidInput, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// Location returns the user's time zone, falling back to the default.
+func (p *NotificationPreferences) Location() *time.Location {
+	if loc, err := time.LoadLocation(p.TimeZone); err == nil {
+		return loc
+	}
+	loc, _ := time.LoadLocation(DefaultTimeZone)
+	return loc
+}
+
+// ChannelEnabled reports whether the user accepts notifications on the channel.
+func (p *NotificationPreferences) ChannelEnabled(c ChannelKind) bool {
+	for _, k := range p.Channels {
+		if k == c {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietUntil returns the end of the quiet period containing t, or false when
+// t is outside quiet hours.
+func (p *NotificationPreferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if !p.QuietHours.Enabled {
+		return time.Time{}, false
+	}
+	start, err1 := parseClock(p.QuietHours.Start)
+	end, err2 := parseClock(p.QuietHours.End)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(p.Location())
+	now := clockOf(local)
+	var quiet bool
+	if start < end {
+		quiet = now >= start && now < end
+	} else {
+		quiet = now >= start || now < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+	return nextClock(local, end), true
+}
+
+// NextDigest returns the next digest time strictly after t.
+func (p *NotificationPreferences) NextDigest(t time.Time) time.Time {
+	at, err := parseClock(p.DigestTime)
+	if err != nil {
+		at = 18 * time.Hour
+	}
+	return nextClock(t.In(p.Location()), at)
+}
+
+// DayStart returns the start of the user's local day containing t.
+func (p *NotificationPreferences) DayStart(t time.Time) time.Time {
+	local := t.In(p.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+func supportedLocale(l string) bool {
+	for _, s := range SupportedLocales {
+		if s == l {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses HH:MM into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func clockOf(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// nextClock returns the first time after local at the given clock offset.
+func nextClock(local time.Time, at time.Duration) time.Time {
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	next := time.Date(day.Year(), day.Month(), day.Day(), int(at/time.Hour), int(at%time.Hour/time.Minute), 0, 0, day.Location())
+	if !next.After(local) {
+		next = time.Date(day.Year(), day.Month(), day.Day()+1, int(at/time.Hour), int(at%time.Hour/time.Minute), 0, 0, day.Location())
+	}
+	return next
+}
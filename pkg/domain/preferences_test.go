@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietUntil(t *testing.T) {
+	p := DefaultPreferences("U1") // 22:00-07:00 Addis Ababa (UTC+3)
+	cases := []struct {
+		at    time.Time
+		quiet bool
+		until time.Time
+	}{
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), true, time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)},   // 03:00 local
+		{time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC), true, time.Date(2026, 3, 2, 4, 0, 0, 0, time.UTC)}, // 22:30 local
+		{time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC), false, time.Time{}},                                  // 07:00 local
+		{time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), false, time.Time{}},
+	}
+	for _, tc := range cases {
+		until, quiet := p.QuietUntil(tc.at)
+		if quiet != tc.quiet || !until.Equal(tc.until) {
+			t.Errorf("%v: got (%v, %v), want (%v, %v)", tc.at, until, quiet, tc.until, tc.quiet)
+		}
+	}
+
+	p.QuietHours = QuietHours{Enabled: true, Start: "13:00", End: "15:00"}
+	if _, quiet := p.QuietUntil(time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)); !quiet {
+		t.Error("13:30 local should be quiet in a same-day window")
+	}
+}
+
+func TestNextDigestAndDayStart(t *testing.T) {
+	p := DefaultPreferences("U1")
+	at := time.Date(2026, 3, 1, 16, 0, 0, 0, time.UTC) // 19:00 local, after the 18:00 digest
+	if got, want := p.NextDigest(at), time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextDigest = %v, want %v", got.UTC(), want)
+	}
+	if got, want := p.DayStart(time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)), time.Date(2026, 3, 1, 21, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("DayStart = %v, want %v", got.UTC(), want)
+	}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// PriceSnapshot is a recorded observation of a product's price. A snapshot is
+// only written when the price changed; unchanged observations extend LastSeenAt.
+type PriceSnapshot struct {
+	ID         string    `json:"id" bson:"_id"`
+	ProductID  string    `json:"productId" bson:"product_id"`
+	Price      Price     `json:"price" bson:"price"`
+	FXRate     float64   `json:"fxRate" bson:"fx_rate"`
+	RecordedAt time.Time `json:"recordedAt" bson:"recorded_at"`
+	LastSeenAt time.Time `json:"lastSeenAt" bson:"last_seen_at"`
+}
+
+// PriceStats summarizes a price series in a single currency.
+type PriceStats struct {
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Current float64 `json:"current"`
+}
+
+// PricePoint is one point of a price chart.
+type PricePoint struct {
+	Time time.Time `json:"time"`
+	USD  float64   `json:"usd"`
+	ETB  float64   `json:"etb"`
+}
+
+// PriceHistory is the chart-ready price series of a product.
+type PriceHistory struct {
+	ProductID string       `json:"productId"`
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	USD       PriceStats   `json:"usd"`
+	ETB       PriceStats   `json:"etb"`
+	Points    []PricePoint `json:"points"`
+}
@@ -4,21 +4,89 @@ import "time"
 
 // Price represents the price of a product in different currencies.
 type Price struct {
-	ETB         float64   `json:"etb"`
-	USD         float64   `json:"usd"`
-	FXTimestamp time.Time `json:"fxTimestamp"`
+	ETB         float64   `json:"etb" bson:"etb"`
+	USD         float64   `json:"usd" bson:"usd"`
+	FXTimestamp time.Time `json:"fxTimestamp" bson:"fx_timestamp"`
+}
+
+// Amount returns the price in the currency, or 0 for currencies a Price
+// does not carry.
+func (p Price) Amount(currency string) float64 {
+	switch currency {
+	case CurrencyETB:
+		return p.ETB
+	case CurrencyUSD:
+		return p.USD
+	}
+	return 0
+}
+
+// DeliveryWindow is the structured form of a product's delivery estimate.
+// Arrival dates are computed from the time the estimate was produced.
+type DeliveryWindow struct {
+	MinDays         int       `json:"minDays"`
+	MaxDays         int       `json:"maxDays"`
+	EarliestArrival time.Time `json:"earliestArrival"`
+	LatestArrival   time.Time `json:"latestArrival"`
+	ShippingMethod  string    `json:"shippingMethod,omitempty"`
+	Destination     string    `json:"destination,omitempty"`
+}
+
+// Seller describes the store a product is sold by.
+type Seller struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	StoreURL         string  `json:"storeUrl,omitempty"`
+	Country          string  `json:"country,omitempty"`
+	PositiveFeedback float64 `json:"positiveFeedback"`
+	Followers        int     `json:"followers"`
+	YearsActive      int     `json:"yearsActive"`
+}
+
+// ShippingOption is one line of a product's shipping breakdown.
+type ShippingOption struct {
+	Method   string          `json:"method"`
+	Cost     Price           `json:"cost"`
+	Delivery *DeliveryWindow `json:"delivery,omitempty"`
+	Tracked  bool            `json:"tracked"`
 }
 
 // Product represents a product found on an e-commerce platform.
 type Product struct {
-	ID                string   `json:"id"`
-	Title             string   `json:"title"`
-	ImageURL          string   `json:"imageUrl"`
-	AIMatchPercentage int      `json:"aiMatchPercentage"`
-	Price             Price    `json:"price"`
-	ProductRating     float64  `json:"productRating"`
-	SellerScore       int      `json:"sellerScore"`
-	DeliveryEstimate  string   `json:"deliveryEstimate"`
-	SummaryBullets    []string `json:"summaryBullets"`
-	DeeplinkURL       string   `json:"deeplinkUrl"`
+	ID                string           `json:"id"`
+	Title             string           `json:"title"`
+	ImageURL          string           `json:"imageUrl"`
+	AIMatchPercentage int              `json:"aiMatchPercentage"`
+	Price             Price            `json:"price"`
+	OriginalPrice     *Price           `json:"originalPrice,omitempty"`
+	ProductRating     float64          `json:"productRating"`
+	SellerScore       int              `json:"sellerScore"`
+	DeliveryEstimate  string           `json:"deliveryEstimate"`
+	Delivery          *DeliveryWindow  `json:"delivery,omitempty"`
+	SummaryBullets    []string         `json:"summaryBullets"`
+	DeeplinkURL       string           `json:"deeplinkUrl"`
+	Seller            *Seller          `json:"seller,omitempty"`
+	Shipping          []ShippingOption `json:"shipping,omitempty"`
+	Deal              *DealVerdict     `json:"deal,omitempty"`
+	Prediction        *PricePrediction `json:"prediction,omitempty"`
+	// Stock is the number of units available; nil when the source does not say.
+	Stock *int `json:"stock,omitempty"`
+}
+
+// InStock reports whether the product can be ordered. Products without
+// stock information are assumed to be available.
+func (p *Product) InStock() bool {
+	return p.Stock == nil || *p.Stock > 0
+}
+
+// DeliveryDays returns the slowest estimated delivery in days, or 0 when
+// the product has no usable estimate.
+func (p *Product) DeliveryDays() int {
+	if p.Delivery != nil {
+		return p.Delivery.MaxDays
+	}
+	if w, ok := ParseDeliveryEstimate(p.DeliveryEstimate, time.Now()); ok {
+		return w.MaxDays
+	}
+	return 0
 }
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Product sources a pasted link can point to.
+const (
+	SourceAliExpress = "aliexpress"
+	SourceAlibaba    = "alibaba"
+)
+
+var (
+	// aliExpressItemPath matches /item/1005001234567890.html, /i/….html and
+	// /item/some-title/1005….html.
+	aliExpressItemPath = regexp.MustCompile(`/(?:item|i)/(?:[^/]+/)?(\d{6,20})\.html?$`)
+	// aliExpressStorePath matches the old /store/product/title/123_32801234567.html.
+	aliExpressStorePath = regexp.MustCompile(`/store/product/[^/]+/\d+_(\d{6,20})\.html?$`)
+	// alibabaDetailPath matches /product-detail/Some-Title_1600123456789.html.
+	alibabaDetailPath = regexp.MustCompile(`/product-detail/(?:[^/]*_)?(\d{6,20})\.html?$`)
+	productIDParam    = regexp.MustCompile(`^\d{6,20}$`)
+
+	// shortLinkHosts serve AliExpress share and affiliate links.
+	shortLinkHosts = []string{"a.aliexpress.com", "s.click.aliexpress.com", "click.aliexpress.com", "ali.ski", "aliexpi.com"}
+)
+
+// ProductRef identifies a product on its source marketplace.
+type ProductRef struct {
+	Source    string `json:"source"`
+	ProductID string `json:"productId"`
+	// CanonicalURL is the product's desktop URL without tracking parameters.
+	CanonicalURL string `json:"canonicalUrl"`
+}
+
+// ParseProductURL extracts the product from an AliExpress or Alibaba
+// product URL, desktop or mobile. It reports false for other URLs,
+// including short links, which must be expanded first.
+func ParseProductURL(raw string) (ProductRef, bool) {
+	u, ok := parseWebURL(raw)
+	if !ok {
+		return ProductRef{}, false
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case isAliExpressHost(host):
+		if m := aliExpressItemPath.FindStringSubmatch(u.Path); m != nil {
+			return aliExpressRef(m[1]), true
+		}
+		if m := aliExpressStorePath.FindStringSubmatch(u.Path); m != nil {
+			return aliExpressRef(m[1]), true
+		}
+		for _, key := range []string{"productId", "productIds", "objectId"} {
+			if id := u.Query().Get(key); productIDParam.MatchString(id) {
+				return aliExpressRef(id), true
+			}
+		}
+	case hostUnder(host, "alibaba.com"):
+		if m := alibabaDetailPath.FindStringSubmatch(u.Path); m != nil {
+			return ProductRef{
+				Source:       SourceAlibaba,
+				ProductID:    m[1],
+				CanonicalURL: "https://www.alibaba.com/product-detail/_" + m[1] + ".html",
+			}, true
+		}
+	}
+	return ProductRef{}, false
+}
+
+// IsShortProductLink reports whether raw is an AliExpress short or
+// affiliate link, whose product is only known after following its redirects.
+func IsShortProductLink(raw string) bool {
+	u, ok := parseWebURL(raw)
+	if !ok {
+		return false
+	}
+	return isShortLinkHost(strings.ToLower(u.Hostname()))
+}
+
+// IsProductLinkHost reports whether host belongs to AliExpress, Alibaba or
+// one of the short-link services their share links go through.
+func IsProductLinkHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return isAliExpressHost(host) || hostUnder(host, "alibaba.com") || isShortLinkHost(host)
+}
+
+func isShortLinkHost(host string) bool {
+	for _, h := range shortLinkHosts {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func aliExpressRef(id string) ProductRef {
+	return ProductRef{
+		Source:       SourceAliExpress,
+		ProductID:    id,
+		CanonicalURL: "https://www.aliexpress.com/item/" + id + ".html",
+	}
+}
+
+func isAliExpressHost(host string) bool {
+	for _, d := range []string{"aliexpress.com", "aliexpress.us", "aliexpress.ru"} {
+		if hostUnder(host, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostUnder reports whether host is parent or one of its subdomains.
+func hostUnder(host, parent string) bool {
+	return host == parent || strings.HasSuffix(host, "."+parent)
+}
+
+// parseWebURL parses an http(s) URL, adding the scheme users often leave out.
+func parseWebURL(raw string) (*url.URL, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
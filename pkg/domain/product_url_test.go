@@ -0,0 +1,72 @@
+package domain
+
+import "testing"
+
+func TestParseProductURL(t *testing.T) {
+	cases := []struct {
+		raw    string
+		source string
+		id     string
+	}{
+		{"https://www.aliexpress.com/item/1005004567890123.html?spm=a2g0o.home&gatewayAdapt=glo2usa", SourceAliExpress, "1005004567890123"},
+		{"aliexpress.com/item/1005004567890123.html", SourceAliExpress, "1005004567890123"},
+		{"https://m.aliexpress.com/i/1005004567890123.html", SourceAliExpress, "1005004567890123"},
+		{"https://pt.aliexpress.com/item/wireless-earbuds/1005004567890123.html", SourceAliExpress, "1005004567890123"},
+		{"https://www.aliexpress.us/item/3256804567890123.html", SourceAliExpress, "3256804567890123"},
+		{"https://www.aliexpress.com/store/product/Phone-Case/123456_32801234567.html", SourceAliExpress, "32801234567"},
+		{"https://m.aliexpress.com/app/web/item.html?productId=1005004567890123", SourceAliExpress, "1005004567890123"},
+		{"https://www.alibaba.com/product-detail/Solar-Panel-400W_1600123456789.html?spm=a2700", SourceAlibaba, "1600123456789"},
+		{"  https://m.alibaba.com/product-detail/_1600123456789.html ", SourceAlibaba, "1600123456789"},
+	}
+	for _, c := range cases {
+		ref, ok := ParseProductURL(c.raw)
+		if !ok || ref.Source != c.source || ref.ProductID != c.id {
+			t.Errorf("ParseProductURL(%q) = %+v, %v; want %s %s", c.raw, ref, ok, c.source, c.id)
+		}
+	}
+
+	ref, _ := ParseProductURL("https://www.aliexpress.com/item/1005004567890123.html?aff_fcid=x")
+	if ref.CanonicalURL != "https://www.aliexpress.com/item/1005004567890123.html" {
+		t.Errorf("unexpected canonical URL %q", ref.CanonicalURL)
+	}
+
+	for _, raw := range []string{
+		"",
+		"not a url",
+		"https://a.aliexpress.com/_mK8abcd",
+		"https://www.aliexpress.com/category/100003109/women-clothing.html",
+		"https://evil-aliexpress.com/item/1005004567890123.html",
+		"https://www.amazon.com/item/1005004567890123.html",
+		"ftp://www.aliexpress.com/item/1005004567890123.html",
+	} {
+		if ref, ok := ParseProductURL(raw); ok {
+			t.Errorf("ParseProductURL(%q) = %+v; want no match", raw, ref)
+		}
+	}
+}
+
+func TestIsShortProductLink(t *testing.T) {
+	for _, raw := range []string{"https://a.aliexpress.com/_mK8abcd", "s.click.aliexpress.com/e/_DlXyz"} {
+		if !IsShortProductLink(raw) {
+			t.Errorf("IsShortProductLink(%q) = false", raw)
+		}
+	}
+	for _, raw := range []string{"https://www.aliexpress.com/item/1005004567890123.html", "https://bit.ly/abc"} {
+		if IsShortProductLink(raw) {
+			t.Errorf("IsShortProductLink(%q) = true", raw)
+		}
+	}
+}
+
+func TestIsProductLinkHost(t *testing.T) {
+	for _, host := range []string{"a.aliexpress.com", "www.aliexpress.us", "m.alibaba.com", "ali.ski", "WWW.AliExpress.com."} {
+		if !IsProductLinkHost(host) {
+			t.Errorf("IsProductLinkHost(%q) = false", host)
+		}
+	}
+	for _, host := range []string{"", "169.254.169.254", "localhost", "evil-aliexpress.com", "aliexpress.com.evil.io", "x.ali.ski"} {
+		if IsProductLinkHost(host) {
+			t.Errorf("IsProductLinkHost(%q) = true", host)
+		}
+	}
+}
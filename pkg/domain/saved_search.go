@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSavedSearchNotFound is returned when a saved search does not exist for the user.
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// SavedSearch is a search a user asked to be told about when new products
+// match it.
+type SavedSearch struct {
+	ID     string `json:"id" bson:"_id"`
+	UserID string `json:"userId" bson:"user_id"`
+	Query  string `json:"query" bson:"query"`
+	// Intent is the query parsed when the search was saved; runs reuse it.
+	Intent map[string]interface{} `json:"intent,omitempty" bson:"intent,omitempty"`
+	// MaxPriceETB is the price a new product must match or beat; 0 means any price.
+	MaxPriceETB float64 `json:"maxPriceEtb,omitempty" bson:"max_price_etb,omitempty"`
+	// LastResultIDs are the products recent runs returned, newest first, so a
+	// product that drops out of one run and comes back is not new again.
+	LastResultIDs []string   `json:"-" bson:"last_result_ids"`
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty" bson:"last_run_at,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt" bson:"created_at"`
+}
+
+// Matches reports whether a product at the ETB price meets the price constraint.
+func (s *SavedSearch) Matches(priceETB float64) bool {
+	return s.MaxPriceETB == 0 || (priceETB > 0 && priceETB <= s.MaxPriceETB)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// smsUserPrefix marks user IDs of people known only by their phone number.
+const smsUserPrefix = "sms:"
+
+// SMSUserID returns the user ID of a phone-only user.
+func SMSUserID(phone string) string {
+	return smsUserPrefix + phone
+}
+
+// PhoneFromUserID returns the phone number of a phone-only user ID.
+func PhoneFromUserID(userID string) (string, bool) {
+	if !strings.HasPrefix(userID, smsUserPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(userID, smsUserPrefix), true
+}
+
+// SMSIncoming is a text message received on our short code or number.
+type SMSIncoming struct {
+	MessageID string
+	From      string
+	To        string
+	Text      string
+}
+
+// SMSResult is a search result sent to a phone, kept so the user can pick
+// it by number in a reply.
+type SMSResult struct {
+	ProductID string  `json:"productId"`
+	Title     string  `json:"title"`
+	PriceETB  float64 `json:"priceEtb"`
+}
+
+// SMSSession holds the last results sent to a phone.
+type SMSSession struct {
+	Phone     string      `json:"phone"`
+	Results   []SMSResult `json:"results"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
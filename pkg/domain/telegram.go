@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLinkCodeInvalid is returned for unknown, used or expired link codes.
+var ErrLinkCodeInvalid = errors.New("link code is invalid or expired")
+
+// TelegramLink connects a user account to the Telegram chat with our bot.
+type TelegramLink struct {
+	UserID   string    `json:"userId" bson:"_id"`
+	ChatID   int64     `json:"chatId" bson:"chat_id"`
+	Username string    `json:"username,omitempty" bson:"username,omitempty"`
+	LinkedAt time.Time `json:"linkedAt" bson:"linked_at"`
+}
+
+// TelegramLinkCode is a one-time code a user sends to the bot to link their chat.
+type TelegramLinkCode struct {
+	Code      string    `json:"code" bson:"_id"`
+	UserID    string    `json:"userId" bson:"user_id"`
+	ExpiresAt time.Time `json:"expiresAt" bson:"expires_at"`
+	// BotURL opens the bot with the code pre-filled.
+	BotURL string `json:"botUrl,omitempty" bson:"-"`
+}
+
+// TelegramIncoming is a text message received by the bot.
+type TelegramIncoming struct {
+	UpdateID int64
+	ChatID   int64
+	Username string
+	Text     string
+}
+
+// TelegramButton is an inline keyboard button that opens a URL.
+type TelegramButton struct {
+	Text string
+	URL  string
+}
+
+// TelegramMessage is an outgoing bot message. Text is Telegram HTML; when
+// PhotoURL is set the message is sent as a photo with Text as its caption.
+type TelegramMessage struct {
+	Text     string
+	PhotoURL string
+	Buttons  []TelegramButton
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrWatchlistItemNotFound is returned when a product is not on the user's watchlist.
+	ErrWatchlistItemNotFound = errors.New("watchlist item not found")
+	// ErrWatchlistConflict is returned when a watchlist is saved after
+	// another request changed it since it was read.
+	ErrWatchlistConflict = errors.New("watchlist changed concurrently")
+)
+
+// Watchlist limits.
+const (
+	WatchlistMaxItems  = 200
+	WatchlistMaxTags   = 10
+	watchlistTagRunes  = 30
+	watchlistNoteRunes = 500
+)
+
+// Watchlist is a user's bookmarked products. It is stored as one document
+// per user.
+type Watchlist struct {
+	UserID    string          `json:"userId" bson:"_id"`
+	Items     []WatchlistItem `json:"items" bson:"items"`
+	UpdatedAt time.Time       `json:"updatedAt" bson:"updated_at"`
+	// Version counts saves; a save must carry the version it read.
+	Version int64 `json:"-" bson:"version"`
+}
+
+// WatchlistItem is a bookmarked product with the price it had when saved.
+type WatchlistItem struct {
+	ProductID  string    `json:"productId" bson:"product_id"`
+	Title      string    `json:"title" bson:"title"`
+	ImageURL   string    `json:"imageUrl,omitempty" bson:"image_url,omitempty"`
+	Note       string    `json:"note,omitempty" bson:"note,omitempty"`
+	Tags       []string  `json:"tags,omitempty" bson:"tags,omitempty"`
+	SavedPrice Price     `json:"savedPrice" bson:"saved_price"`
+	AddedAt    time.Time `json:"addedAt" bson:"added_at"`
+	// AlertID is set once the item was promoted to a price alert.
+	AlertID string `json:"alertId,omitempty" bson:"alert_id,omitempty"`
+}
+
+// Item returns the item of the product, or nil.
+func (w *Watchlist) Item(productID string) *WatchlistItem {
+	for i := range w.Items {
+		if w.Items[i].ProductID == productID {
+			return &w.Items[i]
+		}
+	}
+	return nil
+}
+
+// Add appends the item. It returns ErrInvalidInput when the list is full.
+func (w *Watchlist) Add(item WatchlistItem) error {
+	if len(w.Items) >= WatchlistMaxItems {
+		return fmt.Errorf("%w: a watchlist holds at most %d products", ErrInvalidInput, WatchlistMaxItems)
+	}
+	w.Items = append(w.Items, item)
+	return nil
+}
+
+// Remove deletes the product's item or returns ErrWatchlistItemNotFound.
+func (w *Watchlist) Remove(productID string) error {
+	for i := range w.Items {
+		if w.Items[i].ProductID == productID {
+			w.Items = append(w.Items[:i], w.Items[i+1:]...)
+			return nil
+		}
+	}
+	return ErrWatchlistItemNotFound
+}
+
+// HasTag reports whether the item is tagged with tag.
+func (it *WatchlistItem) HasTag(tag string) bool {
+	for _, t := range it.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeWatchlistNote trims a note and checks its length.
+func NormalizeWatchlistNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > watchlistNoteRunes {
+		return "", fmt.Errorf("%w: note must be at most %d characters", ErrInvalidInput, watchlistNoteRunes)
+	}
+	return note, nil
+}
+
+// NormalizeWatchlistTags lowercases, trims and de-duplicates tags, keeping
+// their order, and checks their number and length.
+func NormalizeWatchlistTags(tags []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t == "" || seen[t] {
+			continue
+		}
+		if len([]rune(t)) > watchlistTagRunes {
+			return nil, fmt.Errorf("%w: tags must be at most %d characters", ErrInvalidInput, watchlistTagRunes)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > WatchlistMaxTags {
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidInput, WatchlistMaxTags)
+	}
+	return out, nil
+}
+
+// WatchlistEntry is a watchlist item with the product's current price.
+// CurrentPrice and Change are nil when the product could not be fetched.
+type WatchlistEntry struct {
+	WatchlistItem
+	CurrentPrice *Price      `json:"currentPrice,omitempty"`
+	Change       *PriceDelta `json:"change,omitempty"`
+}
+
+// PriceDelta is how much a price moved; negative values are drops.
+type PriceDelta struct {
+	USD float64 `json:"usd"`
+	ETB float64 `json:"etb"`
+	// Percent is the ETB change relative to the earlier price.
+	Percent float64 `json:"percent"`
+}
+
+// PriceDeltaBetween returns the change from before to now.
+func PriceDeltaBetween(before, now Price) PriceDelta {
+	d := PriceDelta{
+		USD: math.Round((now.USD-before.USD)*100) / 100,
+		ETB: math.Round((now.ETB-before.ETB)*100) / 100,
+	}
+	if before.ETB > 0 {
+		d.Percent = math.Round((now.ETB-before.ETB)/before.ETB*10000) / 100
+	}
+	return d
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook does not exist for the client.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a delivery does not exist for the client.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Webhook request headers. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook's secret.
+const (
+	WebhookSignatureHeader = "X-ShopAlly-Signature"
+	WebhookTimestampHeader = "X-ShopAlly-Timestamp"
+	WebhookEventHeader     = "X-ShopAlly-Event"
+	WebhookDeliveryHeader  = "X-ShopAlly-Delivery"
+)
+
+// Webhook is an API client's subscription to server-to-server callbacks.
+type Webhook struct {
+	ID       string `json:"id" bson:"_id"`
+	ClientID string `json:"clientId" bson:"client_id"`
+	URL      string `json:"url" bson:"url"`
+	// Events are the event names the webhook receives.
+	Events []string `json:"events" bson:"events"`
+	// Secret signs deliveries. It is only shown when the webhook is created.
+	Secret    string    `json:"-" bson:"secret"`
+	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
+}
+
+// Wants reports whether the webhook subscribes to the event.
+func (w *Webhook) Wants(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookAttempt is one request made for a delivery.
+type WebhookAttempt struct {
+	At         time.Time `json:"at" bson:"at"`
+	StatusCode int       `json:"statusCode,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS int64     `json:"durationMs" bson:"duration_ms"`
+}
+
+// WebhookDelivery is one event sent to one webhook, with the log of its
+// attempts. The payload is stored so a replay sends the same body.
+type WebhookDelivery struct {
+	ID        string                `json:"id" bson:"_id"`
+	WebhookID string                `json:"webhookId" bson:"webhook_id"`
+	ClientID  string                `json:"clientId" bson:"client_id"`
+	Event     string                `json:"event" bson:"event"`
+	Payload   string                `json:"payload" bson:"payload"`
+	Status    WebhookDeliveryStatus `json:"status" bson:"status"`
+	// Attempts counts attempts since the delivery was created or replayed;
+	// Log keeps every attempt.
+	Attempts    int              `json:"attempts" bson:"attempts"`
+	Log         []WebhookAttempt `json:"log" bson:"log"`
+	CreatedAt   time.Time        `json:"createdAt" bson:"created_at"`
+	DeliveredAt *time.Time       `json:"deliveredAt,omitempty" bson:"delivered_at,omitempty"`
+	ReplayedAt  *time.Time       `json:"replayedAt,omitempty" bson:"replayed_at,omitempty"`
+}
+
+// SignWebhook returns the signature header value for a body sent at ts.
+func SignWebhook(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks a signature and that ts is within tolerance of now,
+// which is how receivers should reject forged and replayed requests.
+func VerifyWebhook(secret, signature string, ts, now time.Time, tolerance time.Duration, body []byte) bool {
+	if d := now.Sub(ts); d > tolerance || d < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(SignWebhook(secret, ts, body)))
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"D1"}`)
+	sig := SignWebhook("secret", ts, body)
+
+	// The signature is hex(HMAC-SHA256("1700000000.{body}")) under the secret
+	if sig != "sha256=177033b2fbf211fa6f85415117ab63d644e4aac6bed1195d65cd739c6ac875c4" {
+		t.Fatalf("unexpected signature %q", sig)
+	}
+	if !VerifyWebhook("secret", sig, ts, ts.Add(time.Minute), 5*time.Minute, body) {
+		t.Error("valid signature rejected")
+	}
+	if VerifyWebhook("other", sig, ts, ts, 5*time.Minute, body) {
+		t.Error("signature accepted under another secret")
+	}
+	if VerifyWebhook("secret", sig, ts, ts, 5*time.Minute, []byte(`{"id":"D2"}`)) {
+		t.Error("signature accepted for another body")
+	}
+	if VerifyWebhook("secret", sig, ts, ts.Add(10*time.Minute), 5*time.Minute, body) {
+		t.Error("stale timestamp accepted")
+	}
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// minDealSampleDays is the history needed before a verdict is given.
+	minDealSampleDays = 3
+	// fullConfidenceDays is the history at which coverage stops limiting confidence.
+	fullConfidenceDays = 30
+	// recentSpikeDays is how far back a pre-sale price hike is looked for.
+	recentSpikeDays = 14
+	// annotateTimeout bounds the history lookup of a search's verdicts.
+	annotateTimeout = 2 * time.Second
+)
+
+// DealAnalyzer classifies a product's current price against its stored price
+// snapshots. It works on USD prices so that FX moves do not look like discounts.
+type DealAnalyzer struct {
+	history         PriceHistoryRepository
+	window          time.Duration
+	annotateTimeout time.Duration
+}
+
+// NewDealAnalyzer creates a DealAnalyzer that looks back over the given window.
+func NewDealAnalyzer(history PriceHistoryRepository, window time.Duration) *DealAnalyzer {
+	if window <= 0 {
+		window = 90 * 24 * time.Hour
+	}
+	return &DealAnalyzer{history: history, window: window, annotateTimeout: annotateTimeout}
+}
+
+// Analyze returns the deal verdict for the product's current price, or nil
+// when there is not enough history to judge it.
+func (a *DealAnalyzer) Analyze(ctx context.Context, p *domain.Product) (*domain.DealVerdict, error) {
+	end := time.Now().UTC()
+	start := end.Add(-a.window)
+
+	snapshots, err := a.history.ListSnapshots(ctx, p.ID, start)
+	if err != nil {
+		return nil, err
+	}
+	return verdict(p, snapshots, start, end), nil
+}
+
+// Annotate attaches verdicts to the given products. It is best effort: the
+// history of all products is read in one query bounded by annotateTimeout,
+// and when that fails the products are left without a verdict.
+func (a *DealAnalyzer) Annotate(ctx context.Context, products []*domain.Product) {
+	var ids []string
+	for _, p := range products {
+		if p != nil && p.Price.USD > 0 && !containsString(ids, p.ID) {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-a.window)
+	ctx, cancel := context.WithTimeout(ctx, a.annotateTimeout)
+	defer cancel()
+	history, err := a.history.ListSnapshotsFor(ctx, ids, start)
+	if err != nil {
+		return
+	}
+	for _, p := range products {
+		if p != nil {
+			p.Deal = verdict(p, history[p.ID], start, end)
+		}
+	}
+}
+
+// verdict judges the product's current price against its snapshots between
+// start and end, or returns nil when there is not enough history.
+func verdict(p *domain.Product, snapshots []*domain.PriceSnapshot, start, end time.Time) *domain.DealVerdict {
+	series := dailySeries(snapshots, start, end)
+	if len(series) < minDealSampleDays || p.Price.USD <= 0 {
+		return nil
+	}
+
+	usd := make([]float64, len(series))
+	for i, s := range series {
+		usd[i] = s.USD
+	}
+	recent := usd
+	if len(recent) > recentSpikeDays {
+		recent = recent[len(recent)-recentSpikeDays:]
+	}
+
+	v := &domain.DealVerdict{
+		WindowStart:  series[0].Day,
+		WindowEnd:    end,
+		SampleDays:   len(series),
+		ReferenceUSD: roundCents(median(usd)),
+		LowestUSD:    minOf(usd),
+		HighestUSD:   maxOf(usd),
+		CurrentUSD:   p.Price.USD,
+	}
+	if v.ReferenceUSD <= 0 {
+		return nil
+	}
+	if p.OriginalPrice != nil {
+		v.ClaimedOrigUSD = p.OriginalPrice.USD
+	}
+	classifyDeal(v, maxOf(recent))
+	return v
+}
+
+// classifyDeal applies the transparent rule set:
+//   - genuine low: within 2% of the window low and at least 7% under the median;
+//   - inflated: at least 7% over the median, or a claimed discount whose
+//     "original price" was never charged, or which follows a recent price hike;
+//   - normal otherwise.
+func classifyDeal(v *domain.DealVerdict, recentHigh float64) {
+	ref, cur := v.ReferenceUSD, v.CurrentUSD
+	coverage := math.Min(1, float64(v.SampleDays)/fullConfidenceDays)
+	deviation := (cur - ref) / ref
+
+	claimsDiscount := v.ClaimedOrigUSD > cur*1.05
+	switch {
+	case cur <= v.LowestUSD*1.02 && deviation <= -0.07:
+		v.Quality = domain.DealGenuineLow
+		v.Reason = fmt.Sprintf("price is %.0f%% below its usual %.2f USD and at its lowest in %d days", -deviation*100, ref, v.SampleDays)
+		v.Confidence = coverage * clarity(-deviation)
+	case deviation >= 0.07:
+		v.Quality = domain.DealInflated
+		v.Reason = fmt.Sprintf("price is %.0f%% above its usual %.2f USD", deviation*100, ref)
+		v.Confidence = coverage * clarity(deviation)
+	case claimsDiscount && deviation >= -0.05 && v.ClaimedOrigUSD > v.HighestUSD*1.05:
+		v.Quality = domain.DealInflated
+		v.Reason = fmt.Sprintf("claimed original price %.2f USD was never charged in %d days; highest seen was %.2f USD", v.ClaimedOrigUSD, v.SampleDays, v.HighestUSD)
+		v.Confidence = coverage * clarity((v.ClaimedOrigUSD-v.HighestUSD)/v.HighestUSD)
+	case claimsDiscount && deviation >= -0.05 && recentHigh >= ref*1.10:
+		v.Quality = domain.DealInflated
+		v.Reason = fmt.Sprintf("price was raised to %.2f USD shortly before the sale; the sale price matches its usual %.2f USD", recentHigh, ref)
+		v.Confidence = coverage * clarity((recentHigh-ref)/ref)
+	default:
+		v.Quality = domain.DealNormal
+		v.Reason = fmt.Sprintf("price is within %.0f%% of its usual %.2f USD", math.Abs(deviation)*100, ref)
+		v.Confidence = coverage * math.Max(0.3, 1-math.Abs(deviation)*5)
+	}
+	v.Confidence = math.Round(v.Confidence*100) / 100
+}
+
+// clarity maps how far past a threshold a signal is to a 0.5..1 factor.
+func clarity(margin float64) float64 {
+	return math.Min(1, 0.5+margin*2.5)
+}
+
+// dailyPrice is the price in effect on one day.
+type dailyPrice struct {
+	Day time.Time
+	USD float64
+	ETB float64
+}
+
+// dailySeries expands de-duplicated snapshots into one price per UTC day
+// between start and end. Days not covered by any snapshot are skipped.
+func dailySeries(snapshots []*domain.PriceSnapshot, start, end time.Time) []dailyPrice {
+	sorted := append([]*domain.PriceSnapshot(nil), snapshots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].RecordedAt.Before(sorted[j].RecordedAt) })
+
+	var out []dailyPrice
+	for day := start.Truncate(24 * time.Hour); !day.After(end); day = day.Add(24 * time.Hour) {
+		dayEnd := day.Add(24 * time.Hour)
+		var current *domain.PriceSnapshot
+		for _, s := range sorted {
+			if !s.RecordedAt.Before(dayEnd) {
+				break
+			}
+			if !s.LastSeenAt.Before(day) {
+				current = s
+			}
+		}
+		if current != nil {
+			out = append(out, dailyPrice{Day: day, USD: current.Price.USD, ETB: current.Price.ETB})
+		}
+	}
+	return out
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), values...)
+	sort.Float64s(s)
+	mid := len(s) / 2
+	if len(s)%2 == 0 {
+		return (s[mid-1] + s[mid]) / 2
+	}
+	return s[mid]
+}
+
+func minOf(values []float64) float64 {
+	m := math.Inf(1)
+	for _, v := range values {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+func maxOf(values []float64) float64 {
+	m := math.Inf(-1)
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
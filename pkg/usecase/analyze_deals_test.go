@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// span is a price that stayed current for a number of days.
+type span struct {
+	usd  float64
+	days int
+}
+
+// steadyHistory returns one snapshot per span, ending now.
+func steadyHistory(productID string, now time.Time, spans ...span) *memoryPriceHistory {
+	h := &memoryPriceHistory{}
+	start := now
+	for _, s := range spans {
+		start = start.AddDate(0, 0, -s.days)
+	}
+	for i, s := range spans {
+		end := start.AddDate(0, 0, s.days)
+		h.snapshots = append(h.snapshots, &domain.PriceSnapshot{
+			ID:         productID + string(rune('a'+i)),
+			ProductID:  productID,
+			Price:      domain.Price{USD: s.usd},
+			RecordedAt: start,
+			LastSeenAt: end,
+		})
+		start = end
+	}
+	return h
+}
+
+func TestDealAnalyzer(t *testing.T) {
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	cases := []struct {
+		name     string
+		history  *memoryPriceHistory
+		product  *domain.Product
+		expected domain.DealQuality
+	}{
+		{
+			name:     "GenuineLow",
+			history:  steadyHistory("p", now, span{100, 40}, span{80, 1}),
+			product:  &domain.Product{ID: "p", Price: domain.Price{USD: 80}, OriginalPrice: &domain.Price{USD: 100}},
+			expected: domain.DealGenuineLow,
+		},
+		{
+			name:     "Normal",
+			history:  steadyHistory("p", now, span{100, 40}),
+			product:  &domain.Product{ID: "p", Price: domain.Price{USD: 99}},
+			expected: domain.DealNormal,
+		},
+		{
+			name:     "AboveUsualPrice",
+			history:  steadyHistory("p", now, span{100, 40}),
+			product:  &domain.Product{ID: "p", Price: domain.Price{USD: 120}},
+			expected: domain.DealInflated,
+		},
+		{
+			name:     "OriginalPriceNeverCharged",
+			history:  steadyHistory("p", now, span{100, 40}),
+			product:  &domain.Product{ID: "p", Price: domain.Price{USD: 100}, OriginalPrice: &domain.Price{USD: 200}},
+			expected: domain.DealInflated,
+		},
+		{
+			name:     "RaisedJustBeforeSale",
+			history:  steadyHistory("p", now, span{100, 40}, span{150, 5}, span{100, 1}),
+			product:  &domain.Product{ID: "p", Price: domain.Price{USD: 100}, OriginalPrice: &domain.Price{USD: 150}},
+			expected: domain.DealInflated,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := NewDealAnalyzer(tc.history, 90*24*time.Hour)
+			v, err := a.Analyze(ctx, tc.product)
+			if err != nil {
+				t.Fatalf("Analyze failed: %v", err)
+			}
+			if v == nil {
+				t.Fatal("expected a verdict")
+			}
+			if v.Quality != tc.expected {
+				t.Errorf("quality mismatch: got %s want %s (%s)", v.Quality, tc.expected, v.Reason)
+			}
+			if v.Confidence <= 0 || v.Confidence > 1 {
+				t.Errorf("confidence out of range: %v", v.Confidence)
+			}
+			if v.SampleDays < minDealSampleDays || v.WindowStart.IsZero() {
+				t.Errorf("reference window not reported: %+v", v)
+			}
+		})
+	}
+
+	t.Run("NotEnoughHistory", func(t *testing.T) {
+		a := NewDealAnalyzer(steadyHistory("p", now, span{100, 1}), 0)
+		v, err := a.Analyze(ctx, &domain.Product{ID: "p", Price: domain.Price{USD: 100}})
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		if v != nil {
+			t.Errorf("expected no verdict, got %+v", v)
+		}
+	})
+}
+
+// slowPriceHistory answers only when the caller gives up.
+type slowPriceHistory struct{ memoryPriceHistory }
+
+func (s *slowPriceHistory) ListSnapshotsFor(ctx context.Context, productIDs []string, since time.Time) (map[string][]*domain.PriceSnapshot, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDealAnalyzer_Annotate(t *testing.T) {
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	history := steadyHistory("p1", now, span{100, 40})
+	history.snapshots = append(history.snapshots, steadyHistory("p2", now, span{100, 40}).snapshots...)
+	products := []*domain.Product{
+		{ID: "p1", Price: domain.Price{USD: 120}},
+		{ID: "p2", Price: domain.Price{USD: 99}},
+		{ID: "p3", Price: domain.Price{USD: 10}},
+		nil,
+	}
+	NewDealAnalyzer(history, 0).Annotate(ctx, products)
+	if history.queries != 1 {
+		t.Fatalf("history queries = %d, want 1", history.queries)
+	}
+	if products[0].Deal == nil || products[0].Deal.Quality != domain.DealInflated ||
+		products[1].Deal == nil || products[1].Deal.Quality != domain.DealNormal || products[2].Deal != nil {
+		t.Fatalf("unexpected verdicts: %+v %+v %+v", products[0].Deal, products[1].Deal, products[2].Deal)
+	}
+
+	// A slow history store costs the verdicts, not the search
+	a := NewDealAnalyzer(&slowPriceHistory{}, 0)
+	a.annotateTimeout = 20 * time.Millisecond
+	slow := []*domain.Product{{ID: "p1", Price: domain.Price{USD: 120}}}
+	started := time.Now()
+	a.Annotate(ctx, slow)
+	if time.Since(started) > time.Second || slow[0].Deal != nil {
+		t.Fatalf("Annotate took %v and set %+v", time.Since(started), slow[0].Deal)
+	}
+}
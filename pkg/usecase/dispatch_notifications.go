@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// dispatchBatch is how many notifications one dispatch pass claims.
+	dispatchBatch = 50
+	// maxDeliveryBackoff caps the delay between delivery attempts.
+	maxDeliveryBackoff = time.Hour
+	// digestWindow is how long after the digest time held notifications go out.
+	digestWindow = time.Hour
+)
+
+// NotificationDispatcher delivers pending outbox notifications through the
+// registered channels, retrying each channel independently with exponential
+// backoff until it succeeds or runs out of attempts. User preferences decide
+// the channels used; quiet hours, the daily cap and digest mode defer
+// delivery without using up attempts.
+type NotificationDispatcher struct {
+	outbox      NotificationOutbox
+	prefs       PreferencesRepository
+	channels    map[domain.ChannelKind]NotificationChannel
+	maxAttempts int
+	baseBackoff time.Duration
+	lease       time.Duration
+}
+
+// NewNotificationDispatcher creates a new NotificationDispatcher. prefs may be
+// nil to apply the default preferences to everyone.
+func NewNotificationDispatcher(outbox NotificationOutbox, prefs PreferencesRepository, channels []NotificationChannel, maxAttempts int, baseBackoff time.Duration) *NotificationDispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if baseBackoff <= 0 {
+		baseBackoff = 30 * time.Second
+	}
+	byKind := make(map[domain.ChannelKind]NotificationChannel, len(channels))
+	for _, c := range channels {
+		byKind[c.Kind()] = c
+	}
+	return &NotificationDispatcher{
+		outbox:      outbox,
+		prefs:       prefs,
+		channels:    byKind,
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+		lease:       2 * time.Minute,
+	}
+}
+
+// DispatchDue delivers every due notification and returns the number of
+// channel deliveries that succeeded.
+func (d *NotificationDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	sent := 0
+	var errs []error
+	for {
+		batch, err := d.outbox.ClaimDue(ctx, time.Now().UTC(), d.lease, dispatchBatch)
+		if err != nil {
+			return sent, errors.Join(append(errs, fmt.Errorf("claim: %w", err))...)
+		}
+		for _, n := range batch {
+			sent += d.deliver(ctx, n)
+			if err := d.outbox.Save(ctx, n); err != nil {
+				errs = append(errs, fmt.Errorf("notification %s: %w", n.ID, err))
+			}
+		}
+		if len(batch) < dispatchBatch || ctx.Err() != nil {
+			return sent, errors.Join(errs...)
+		}
+	}
+}
+
+// deliver attempts every due pending delivery of n and updates its state.
+func (d *NotificationDispatcher) deliver(ctx context.Context, n *domain.Notification) int {
+	now := time.Now().UTC()
+	n.UpdatedAt = now
+	defer n.Refresh()
+
+	prefs, err := loadPreferences(ctx, d.prefs, n.UserID)
+	if err != nil {
+		// Without preferences we cannot tell whether sending now is allowed
+		d.deferDue(n, now, now.Add(d.baseBackoff), "preferences: "+err.Error())
+		return 0
+	}
+	for i := range n.Deliveries {
+		del := &n.Deliveries[i]
+		if del.State == domain.DeliveryPending && !prefs.ChannelEnabled(del.Channel) {
+			del.State, del.LastError = domain.DeliverySkipped, "channel disabled by user"
+		}
+	}
+	until, reason, err := d.deferral(ctx, n, prefs, now)
+	if err != nil {
+		d.deferDue(n, now, now.Add(d.baseBackoff), err.Error())
+		return 0
+	}
+	if !until.IsZero() {
+		d.deferDue(n, now, until, reason)
+		return 0
+	}
+
+	sent := 0
+	for i := range n.Deliveries {
+		del := &n.Deliveries[i]
+		if del.State != domain.DeliveryPending || del.NextAttemptAt.After(now) {
+			continue
+		}
+		ch, ok := d.channels[del.Channel]
+		if !ok {
+			del.State, del.LastError = domain.DeliverySkipped, "channel not configured"
+			continue
+		}
+
+		del.Attempts++
+		providerID, err := ch.Send(ctx, n, del.IdempotencyKey(n))
+		switch {
+		case err == nil:
+			at := time.Now().UTC()
+			del.State, del.SentAt, del.ProviderID, del.LastError = domain.DeliverySent, &at, providerID, ""
+			sent++
+		case errors.Is(err, domain.ErrNoRecipient):
+			del.State, del.LastError = domain.DeliverySkipped, err.Error()
+		case errors.Is(err, domain.ErrDeliveryRejected) || del.Attempts >= d.maxAttempts:
+			del.State, del.LastError = domain.DeliveryFailed, err.Error()
+		default:
+			del.LastError = err.Error()
+			del.NextAttemptAt = now.Add(d.backoff(del.Attempts))
+		}
+	}
+	return sent
+}
+
+// deferral returns when n may be delivered if the user's preferences hold it
+// back now, or the zero time if it can go out immediately.
+func (d *NotificationDispatcher) deferral(ctx context.Context, n *domain.Notification, prefs *domain.NotificationPreferences, now time.Time) (time.Time, string, error) {
+	if until, quiet := prefs.QuietUntil(now); quiet {
+		return until, "quiet hours", nil
+	}
+	if startedDelivery(n) {
+		// Caps and digest timing apply to whole notifications, not to the
+		// remaining channels of one that already reached the user
+		return time.Time{}, "", nil
+	}
+	if n.Kind == domain.NotificationDigest {
+		// Digests go out at the user's digest time whatever the delivery
+		// mode, and are not held back by the daily cap
+		if !inDigestWindow(prefs, now) {
+			return prefs.NextDigest(now), "digest time", nil
+		}
+		return time.Time{}, "", nil
+	}
+	if prefs.Mode == domain.DeliveryDigest && !inDigestWindow(prefs, now) {
+		return prefs.NextDigest(now), "digest mode", nil
+	}
+	if prefs.MaxPerDay > 0 {
+		dayStart := prefs.DayStart(now)
+		count, err := d.outbox.CountSent(ctx, n.UserID, dayStart)
+		if err != nil {
+			return time.Time{}, "", fmt.Errorf("count sent: %w", err)
+		}
+		if count >= prefs.MaxPerDay {
+			return dayStart.AddDate(0, 0, 1), "daily limit reached", nil
+		}
+	}
+	return time.Time{}, "", nil
+}
+
+// deferDue moves every due pending delivery to the given time without
+// counting an attempt.
+func (d *NotificationDispatcher) deferDue(n *domain.Notification, now, until time.Time, reason string) {
+	for i := range n.Deliveries {
+		del := &n.Deliveries[i]
+		if del.State == domain.DeliveryPending && !del.NextAttemptAt.After(now) {
+			del.NextAttemptAt, del.LastError = until.UTC(), "deferred: "+reason
+		}
+	}
+}
+
+// inDigestWindow reports whether now is within digestWindow after the user's
+// digest time.
+func inDigestWindow(prefs *domain.NotificationPreferences, now time.Time) bool {
+	return !prefs.NextDigest(now.Add(-digestWindow)).After(now)
+}
+
+func startedDelivery(n *domain.Notification) bool {
+	for _, del := range n.Deliveries {
+		if del.State == domain.DeliverySent {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *NotificationDispatcher) backoff(attempt int) time.Duration {
+	b := d.baseBackoff
+	for i := 1; i < attempt && b < maxDeliveryBackoff; i++ {
+		b *= 2
+	}
+	return min(b, maxDeliveryBackoff)
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// CartEstimator estimates the landed cost of buying several products
+// together: goods, combined shipping per seller and customs on the whole
+// consignment.
+type CartEstimator struct {
+	alibabaGateway AlibabaGateway
+	fx             IFXClient
+	rules          domain.CustomsRules
+}
+
+// NewCartEstimator creates a new CartEstimator.
+func NewCartEstimator(ag AlibabaGateway, fx IFXClient, rules domain.CustomsRules) *CartEstimator {
+	return &CartEstimator{alibabaGateway: ag, fx: fx, rules: rules}
+}
+
+// Estimate prices the cart. Items of the same product are merged. Items are
+// grouped by seller, and each seller ships its items as one parcel charged
+// at the dearest of their cheapest shipping options. Customs are assessed on
+// the combined value of goods and shipping.
+func (e *CartEstimator) Estimate(ctx context.Context, items []domain.CartItem) (*domain.CartEstimate, error) {
+	quantities, order, err := mergeCartItems(items)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := e.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil {
+		return nil, fmt.Errorf("fx rate: %w", err)
+	}
+	now := time.Now().UTC()
+	usd := func(v float64) domain.Price { return convertPrice(roundCents(v), rate, now) }
+
+	type group struct {
+		domain.CartSellerGroup
+		goodsUSD, shippingUSD float64
+	}
+	var groups []*group
+	bySeller := map[string]*group{}
+	var goodsUSD, shippingUSD float64
+	for _, id := range order {
+		p, err := e.alibabaGateway.GetProduct(ctx, id)
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return nil, fmt.Errorf("%w: unknown product %q", domain.ErrInvalidInput, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("product %s: %w", id, err)
+		}
+		qty := quantities[id]
+		if p.Stock != nil && *p.Stock < qty {
+			return nil, fmt.Errorf("%w: only %d of product %q in stock", domain.ErrInvalidInput, *p.Stock, id)
+		}
+
+		// Products without a known seller ship on their own
+		sellerID, sellerName := "product:"+p.ID, ""
+		if p.Seller != nil && p.Seller.ID != "" {
+			sellerID, sellerName = p.Seller.ID, p.Seller.Name
+		}
+		g := bySeller[sellerID]
+		if g == nil {
+			g = &group{CartSellerGroup: domain.CartSellerGroup{SellerID: sellerID, SellerName: sellerName}}
+			bySeller[sellerID] = g
+			groups = append(groups, g)
+		}
+
+		lineUSD := p.Price.USD * float64(qty)
+		g.Items = append(g.Items, domain.CartLine{
+			ProductID: p.ID,
+			Title:     p.Title,
+			Quantity:  qty,
+			UnitPrice: usd(p.Price.USD),
+			Subtotal:  usd(lineUSD),
+		})
+		g.goodsUSD += lineUSD
+		if method, cost, ok := cheapestShipping(p); ok && (g.ShippingMethod == "" || cost > g.shippingUSD) {
+			g.ShippingMethod, g.shippingUSD = method, cost
+		}
+		if days := p.DeliveryDays(); days > g.DeliveryDays {
+			g.DeliveryDays = days
+		}
+	}
+
+	est := &domain.CartEstimate{FXRate: rate}
+	for _, g := range groups {
+		g.Subtotal, g.Shipping = usd(g.goodsUSD), usd(g.shippingUSD)
+		goodsUSD += g.goodsUSD
+		shippingUSD += g.shippingUSD
+		est.Sellers = append(est.Sellers, g.CartSellerGroup)
+	}
+
+	a := e.rules.Assess(goodsUSD + shippingUSD)
+	est.Goods, est.Shipping = usd(goodsUSD), usd(shippingUSD)
+	est.Customs = domain.CustomsBreakdown{
+		CustomsValue: usd(a.CustomsValue),
+		DutyFree:     a.DutyFree,
+		Commercial:   a.Commercial,
+		Duty:         usd(a.Duty),
+		Excise:       usd(a.Excise),
+		VAT:          usd(a.VAT),
+		Surtax:       usd(a.Surtax),
+		Withholding:  usd(a.Withholding),
+		ClearanceFee: usd(a.ClearanceFee),
+		Total:        usd(a.Total()),
+	}
+	est.Total = usd(goodsUSD + shippingUSD + a.Total())
+	return est, nil
+}
+
+// mergeCartItems validates the items and sums quantities per product,
+// keeping the order products first appear in.
+func mergeCartItems(items []domain.CartItem) (map[string]int, []string, error) {
+	if len(items) == 0 {
+		return nil, nil, fmt.Errorf("%w: the cart is empty", domain.ErrInvalidInput)
+	}
+	if len(items) > domain.CartMaxItems {
+		return nil, nil, fmt.Errorf("%w: at most %d items", domain.ErrInvalidInput, domain.CartMaxItems)
+	}
+	quantities := map[string]int{}
+	var order []string
+	for _, it := range items {
+		id := strings.TrimSpace(it.ProductID)
+		if id == "" {
+			return nil, nil, fmt.Errorf("%w: productId is required", domain.ErrInvalidInput)
+		}
+		if it.Quantity < 1 {
+			return nil, nil, fmt.Errorf("%w: quantity of %q must be at least 1", domain.ErrInvalidInput, id)
+		}
+		if _, ok := quantities[id]; !ok {
+			order = append(order, id)
+		}
+		quantities[id] += it.Quantity
+		if quantities[id] > domain.CartMaxQuantity {
+			return nil, nil, fmt.Errorf("%w: at most %d units of %q", domain.ErrInvalidInput, domain.CartMaxQuantity, id)
+		}
+	}
+	return quantities, order, nil
+}
+
+// cheapestShipping returns the product's cheapest shipping option in USD.
+func cheapestShipping(p *domain.Product) (string, float64, bool) {
+	var method string
+	var cost float64
+	for _, o := range p.Shipping {
+		if method == "" || o.Cost.USD < cost {
+			method, cost = o.Method, o.Cost.USD
+		}
+	}
+	return method, cost, method != ""
+}
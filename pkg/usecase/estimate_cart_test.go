@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+func TestCartEstimator_CombinesShippingAndCustoms(t *testing.T) {
+	shipping := func(costs ...float64) []domain.ShippingOption {
+		var out []domain.ShippingOption
+		for i, c := range costs {
+			out = append(out, domain.ShippingOption{Method: []string{"standard", "express"}[i], Cost: domain.Price{USD: c}})
+		}
+		return out
+	}
+	seller := &domain.Seller{ID: "S1", Name: "Gadget Store"}
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Title: "Phone", Price: domain.Price{USD: 20}, Seller: seller, Shipping: shipping(4, 15)},
+		{ID: "P2", Title: "Case", Price: domain.Price{USD: 5}, Seller: seller, Shipping: shipping(2)},
+		{ID: "P3", Title: "Lamp", Price: domain.Price{USD: 8}, Shipping: shipping(3)},
+	}}
+	rules := domain.CustomsRules{DutyFreeUSD: 50, DutyRate: 0.30, VATRate: 0.15, SurtaxRate: 0.10, WithholdingRate: 0.03}
+	e := NewCartEstimator(ag, &fixedFX{rate: 100}, rules)
+
+	// Each item alone is under the duty-free threshold
+	single, err := e.Estimate(context.Background(), []domain.CartItem{{ProductID: "P1", Quantity: 1}})
+	if err != nil {
+		t.Fatalf("Estimate failed: %v", err)
+	}
+	if !single.Customs.DutyFree || single.Total.USD != 24 || single.Total.ETB != 2400 {
+		t.Errorf("unexpected single-item estimate %+v", single)
+	}
+
+	est, err := e.Estimate(context.Background(), []domain.CartItem{
+		{ProductID: "P1", Quantity: 2},
+		{ProductID: "P2", Quantity: 1},
+		{ProductID: "P3", Quantity: 1},
+		{ProductID: "P2", Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("Estimate failed: %v", err)
+	}
+	if len(est.Sellers) != 2 || est.Sellers[0].SellerID != "S1" || len(est.Sellers[0].Items) != 2 {
+		t.Fatalf("expected the store's items in one parcel, got %+v", est.Sellers)
+	}
+	store := est.Sellers[0]
+	if store.Items[1].Quantity != 2 || store.Subtotal.USD != 50 || store.Shipping.USD != 4 || store.ShippingMethod != "standard" {
+		t.Errorf("unexpected store parcel %+v", store)
+	}
+	// Goods 58 and shipping 7 make a taxable consignment of 65
+	if est.Goods.USD != 58 || est.Shipping.USD != 7 || est.Customs.DutyFree || est.Customs.CustomsValue.USD != 65 {
+		t.Errorf("unexpected totals %+v", est)
+	}
+	want := 65 + est.Customs.Total.USD
+	if est.Customs.Duty.USD != 19.5 || est.Total.USD != want || est.Total.ETB != want*100 {
+		t.Errorf("unexpected customs %+v total %+v", est.Customs, est.Total)
+	}
+
+	for _, bad := range [][]domain.CartItem{
+		nil,
+		{{ProductID: "P1", Quantity: 0}},
+		{{ProductID: "nope", Quantity: 1}},
+		{{ProductID: "P1", Quantity: 60}, {ProductID: "P1", Quantity: 60}},
+	} {
+		if _, err := e.Estimate(context.Background(), bad); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", bad, err)
+		}
+	}
+}
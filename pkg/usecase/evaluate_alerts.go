@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// defaultEvaluationConcurrency bounds concurrent product fetches when the
+// evaluator is created without a limit.
+const defaultEvaluationConcurrency = 8
+
+// AlertEvaluator checks active alerts against the current state of their
+// products. Prices are converted with the current USD/ETB rate and each
+// alert is compared in its own currency.
+type AlertEvaluator struct {
+	alerts         AlertRepository
+	alibabaGateway AlibabaGateway
+	fx             IFXClient
+	concurrency    int
+	events         EventPublisher
+}
+
+// NewAlertEvaluator creates a new AlertEvaluator. concurrency bounds how many
+// products are fetched at once; 0 uses a default. events receives
+// AlertTriggered and may be nil.
+func NewAlertEvaluator(alerts AlertRepository, ag AlibabaGateway, fx IFXClient, concurrency int, events EventPublisher) *AlertEvaluator {
+	if concurrency <= 0 {
+		concurrency = defaultEvaluationConcurrency
+	}
+	return &AlertEvaluator{alerts: alerts, alibabaGateway: ag, fx: fx, concurrency: concurrency, events: events}
+}
+
+// AlertCycleStats describes one evaluation cycle.
+type AlertCycleStats struct {
+	ProductsFetched int
+	AlertsEvaluated int
+	AlertsTriggered int
+	AlertsExpired   int
+	Errors          int
+	Duration        time.Duration
+}
+
+// productEvaluation is the outcome of evaluating the alerts of one product.
+type productEvaluation struct {
+	fetched   bool
+	evaluated int
+	triggers  []*domain.AlertTrigger
+	errs      []error
+}
+
+// Evaluate runs one cycle and returns its triggers; see EvaluateCycle.
+func (e *AlertEvaluator) Evaluate(ctx context.Context) ([]*domain.AlertTrigger, error) {
+	triggers, _, err := e.EvaluateCycle(ctx)
+	return triggers, err
+}
+
+// EvaluateCycle returns a trigger for every active alert whose condition is
+// met and updates each alert's lifecycle: expired alerts are deactivated,
+// triggered alerts are deactivated or disarmed, and disarmed alerts re-arm
+// once their condition is no longer met. A re-arming alert does not trigger
+// again within its cooldown.
+//
+// Alerts are grouped by product, so each product is fetched once however
+// many alerts reference it, and up to the evaluator's concurrency products
+// are fetched at once. The USD/ETB rate is fetched once per cycle. Errors
+// for individual products and alerts are collected and do not stop the run.
+func (e *AlertEvaluator) EvaluateCycle(ctx context.Context) ([]*domain.AlertTrigger, AlertCycleStats, error) {
+	start := time.Now()
+	var stats AlertCycleStats
+	finish := func(triggers []*domain.AlertTrigger, errs []error) ([]*domain.AlertTrigger, AlertCycleStats, error) {
+		stats.AlertsTriggered = len(triggers)
+		stats.Errors = len(errs)
+		stats.Duration = time.Since(start)
+		return triggers, stats, errors.Join(errs...)
+	}
+
+	alerts, err := e.alerts.ListActiveAlerts()
+	if err != nil {
+		return finish(nil, []error{fmt.Errorf("list active alerts: %w", err)})
+	}
+
+	now := time.Now().UTC()
+	var errs []error
+	byProduct := map[string][]*domain.Alert{}
+	for _, a := range alerts {
+		if a.Expired(now) {
+			a.Expire()
+			stats.AlertsExpired++
+			if err := e.alerts.UpdateAlert(ctx, a); err != nil {
+				errs = append(errs, fmt.Errorf("expire alert %s: %w", a.ID, err))
+			}
+			continue
+		}
+		byProduct[a.ProductID] = append(byProduct[a.ProductID], a)
+	}
+	if len(byProduct) == 0 {
+		return finish(nil, errs)
+	}
+	ids := make([]string, 0, len(byProduct))
+	for id := range byProduct {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rate, err := e.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil {
+		return finish(nil, append(errs, fmt.Errorf("fx rate: %w", err)))
+	}
+
+	// Results are indexed by product so triggers come out in product order
+	results := make([]productEvaluation, len(ids))
+	sem := make(chan struct{}, e.concurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].errs = []error{fmt.Errorf("product %s: %w", id, ctx.Err())}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = e.evaluateProduct(ctx, id, byProduct[id], rate, now)
+		}(i, id)
+	}
+	wg.Wait()
+
+	var triggers []*domain.AlertTrigger
+	for _, r := range results {
+		if r.fetched {
+			stats.ProductsFetched++
+		}
+		stats.AlertsEvaluated += r.evaluated
+		triggers = append(triggers, r.triggers...)
+		errs = append(errs, r.errs...)
+	}
+	if e.events != nil {
+		for _, t := range triggers {
+			if err := e.events.Publish(ctx, AlertTriggered{Trigger: t}); err != nil {
+				errs = append(errs, fmt.Errorf("alert %s: %w", t.Alert.ID, err))
+			}
+		}
+	}
+	return finish(triggers, errs)
+}
+
+// evaluateProduct fetches one product and evaluates the alerts watching it.
+func (e *AlertEvaluator) evaluateProduct(ctx context.Context, id string, alerts []*domain.Alert, rate float64, now time.Time) productEvaluation {
+	var r productEvaluation
+	p, err := e.alibabaGateway.GetProduct(ctx, id)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("product %s: %w", id, err))
+		return r
+	}
+	r.fetched = true
+	price := convertPrice(p.Price.USD, rate, now)
+	for _, a := range alerts {
+		r.evaluated++
+		if !a.Met(p, price) {
+			if a.Disarmed {
+				a.Disarmed = false
+				if err := e.alerts.UpdateAlert(ctx, a); err != nil {
+					r.errs = append(r.errs, fmt.Errorf("re-arm alert %s: %w", a.ID, err))
+				}
+			}
+			continue
+		}
+		if a.Disarmed || a.CoolingDown(now) {
+			continue
+		}
+		a.MarkTriggered(now)
+		// A failed update still yields the trigger; the next run finds the
+		// same trigger count and the notification is deduplicated. A stale
+		// fencing token means a newer leader owns the alert and reports it.
+		if err := e.alerts.UpdateAlert(ctx, a); err != nil {
+			r.errs = append(r.errs, fmt.Errorf("update alert %s: %w", a.ID, err))
+			if errors.Is(err, domain.ErrStaleFencingToken) {
+				continue
+			}
+		}
+		r.triggers = append(r.triggers, &domain.AlertTrigger{
+			Alert:       a,
+			Product:     p,
+			Price:       price,
+			FXRate:      rate,
+			Cause:       a.DropCause(price, rate),
+			TriggeredAt: now,
+		})
+	}
+	return r
+}
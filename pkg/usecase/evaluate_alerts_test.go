@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+func TestAlertEvaluator_TriggersAtOrBelowTargetETB(t *testing.T) {
+	repo := newMockAlertRepository()
+	for _, a := range []*domain.Alert{
+		{ID: "hit", ProductID: "P1", TargetPrice: 1500, IsActive: true},
+		{ID: "exact", ProductID: "P1", TargetPrice: 1000, IsActive: true},
+		{ID: "miss", ProductID: "P1", TargetPrice: 999, IsActive: true},
+		{ID: "inactive", ProductID: "P1", TargetPrice: 5000, IsActive: false},
+		{ID: "gone", ProductID: "MISSING", TargetPrice: 5000, IsActive: true},
+	} {
+		_ = repo.CreateAlert(a)
+	}
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Price: domain.Price{USD: 10}}}}
+	fx := &fixedFX{rate: 100}
+
+	triggers, err := NewAlertEvaluator(repo, ag, fx, 0, nil).Evaluate(context.Background())
+	if err == nil {
+		t.Fatal("expected error for missing product")
+	}
+
+	got := map[string]float64{}
+	for _, tr := range triggers {
+		got[tr.Alert.ID] = tr.Price.ETB
+	}
+	if len(got) != 2 || got["hit"] != 1000 || got["exact"] != 1000 {
+		t.Fatalf("unexpected triggers: %v", got)
+	}
+	if fx.calls != 1 {
+		t.Fatalf("fx calls = %d, want 1", fx.calls)
+	}
+}
+
+func TestAlertEvaluator_ConditionKinds(t *testing.T) {
+	repo := newMockAlertRepository()
+	inStock := 5
+	was := &domain.AlertBaseline{Price: domain.Price{ETB: 1200}, InStock: false}
+	for _, a := range []*domain.Alert{
+		{ID: "drop", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertPercentDrop, DropPercent: 10}, Baseline: was, IsActive: true},
+		{ID: "deep", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertPercentDrop, DropPercent: 50}, Baseline: was, IsActive: true},
+		{ID: "any", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertAnyDrop}, Baseline: was, IsActive: true},
+		{ID: "stock", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertBackInStock}, Baseline: was, IsActive: true},
+		{ID: "fast", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertFastDelivery, MaxDeliveryDays: 14}, IsActive: true},
+		{ID: "faster", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertFastDelivery, MaxDeliveryDays: 7}, IsActive: true},
+	} {
+		_ = repo.CreateAlert(a)
+	}
+	ag := &stubAlibabaGateway{products: []*domain.Product{{
+		ID: "P1", Title: "Kettle", Price: domain.Price{USD: 10}, Stock: &inStock,
+		Delivery: &domain.DeliveryWindow{MinDays: 8, MaxDays: 12},
+	}}}
+
+	triggers, err := NewAlertEvaluator(repo, ag, &fixedFX{rate: 100}, 0, nil).Evaluate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, tr := range triggers {
+		if tr.Product == nil || tr.Product.ID != "P1" {
+			t.Fatalf("trigger without product: %+v", tr)
+		}
+		got[tr.Alert.ID] = true
+	}
+	if len(got) != 4 || !got["drop"] || !got["any"] || !got["stock"] || !got["fast"] {
+		t.Fatalf("unexpected triggers: %v", got)
+	}
+}
+
+func TestAlertEvaluator_AlertCurrency(t *testing.T) {
+	repo := newMockAlertRepository()
+	// Recorded at 10 USD and 120 ETB per dollar; the rate is now 100
+	was := &domain.AlertBaseline{Price: domain.Price{USD: 10, ETB: 1200}, FXRate: 120}
+	for _, a := range []*domain.Alert{
+		{ID: "etb", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertTargetPrice, TargetPrice: 1100}, Baseline: was, IsActive: true},
+		{ID: "usd", ProductID: "P1", Currency: domain.CurrencyUSD, Condition: domain.AlertCondition{Kind: domain.AlertTargetPrice, TargetPrice: 9.5}, Baseline: was, IsActive: true},
+	} {
+		_ = repo.CreateAlert(a)
+	}
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Price: domain.Price{USD: 10}}}}
+
+	triggers, err := NewAlertEvaluator(repo, ag, &fixedFX{rate: 100}, 0, nil).Evaluate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 1 || triggers[0].Alert.ID != "etb" {
+		t.Fatalf("unexpected triggers: %+v", triggers)
+	}
+	if tr := triggers[0]; tr.FXRate != 100 || tr.Cause != domain.DropCauseFX {
+		t.Fatalf("rate/cause not recorded: %+v", tr)
+	}
+}
+
+func TestAlertEvaluator_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAlertRepository()
+	past := time.Now().Add(-time.Minute)
+	for _, a := range []*domain.Alert{
+		{ID: "once", ProductID: "P1", TargetPrice: 1500, IsActive: true},
+		{ID: "rearm", ProductID: "P1", TargetPrice: 1500, Rearm: true, IsActive: true},
+		{ID: "cooldown", ProductID: "P1", TargetPrice: 1500, Rearm: true, CooldownMinutes: 60, IsActive: true},
+		{ID: "expired", ProductID: "P1", TargetPrice: 1500, ExpiresAt: &past, IsActive: true},
+	} {
+		_ = repo.CreateAlert(a)
+	}
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Price: domain.Price{USD: 10}}}}
+	e := NewAlertEvaluator(repo, ag, &fixedFX{rate: 100}, 0, nil)
+	run := func() map[string]bool {
+		t.Helper()
+		triggers, err := e.Evaluate(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]bool{}
+		for _, tr := range triggers {
+			got[tr.Alert.ID] = true
+		}
+		return got
+	}
+	alert := func(id string) *domain.Alert {
+		a, _ := repo.GetAlert(id)
+		return a
+	}
+
+	if got := run(); len(got) != 3 || !got["once"] || !got["rearm"] || !got["cooldown"] {
+		t.Fatalf("first run: %v", got)
+	}
+	if a := alert("once"); a.IsActive || a.EndReason != domain.AlertEndedTriggered {
+		t.Fatalf("one-shot alert still active: %+v", a)
+	}
+	if a := alert("expired"); a.IsActive || a.EndReason != domain.AlertEndedExpired {
+		t.Fatalf("expired alert still active: %+v", a)
+	}
+
+	// Still below target: re-arming alerts wait for the price to recover
+	if got := run(); len(got) != 0 {
+		t.Fatalf("fired again without re-arming: %v", got)
+	}
+
+	ag.products[0].Price.USD = 20
+	if got := run(); len(got) != 0 || alert("rearm").Disarmed || alert("cooldown").Disarmed {
+		t.Fatalf("did not re-arm above target: %v", got)
+	}
+
+	// Back below target: the cooldown holds one of them back
+	ag.products[0].Price.USD = 10
+	if got := run(); len(got) != 1 || !got["rearm"] {
+		t.Fatalf("after re-arming: %v", got)
+	}
+	if a := alert("rearm"); a.TriggerCount != 2 || !a.IsActive || alertIdempotencyKey(a) != "alert:rearm:2" {
+		t.Fatalf("second trigger not recorded: %+v", a)
+	}
+}
+
+// countingGateway serves products priced at 10 USD, counting fetches per
+// product and the highest number of fetches in flight.
+type countingGateway struct {
+	stubAlibabaGateway
+	mu             sync.Mutex
+	fetches        map[string]int
+	inFlight, peak int
+}
+
+func (g *countingGateway) GetProduct(ctx context.Context, id string) (*domain.Product, error) {
+	g.mu.Lock()
+	g.fetches[id]++
+	g.inFlight++
+	if g.inFlight > g.peak {
+		g.peak = g.inFlight
+	}
+	g.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	g.mu.Lock()
+	g.inFlight--
+	g.mu.Unlock()
+	if id == "MISSING" {
+		return nil, domain.ErrProductNotFound
+	}
+	return &domain.Product{ID: id, Price: domain.Price{USD: 10}}, nil
+}
+
+func TestAlertEvaluator_GroupsByProductWithBoundedConcurrency(t *testing.T) {
+	repo := newMockAlertRepository()
+	// 12 products watched by 50 alerts each, two of them in USD
+	for p := 0; p < 12; p++ {
+		for u := 0; u < 50; u++ {
+			a := &domain.Alert{ID: fmt.Sprintf("A%d-%d", p, u), ProductID: fmt.Sprintf("P%02d", p), TargetPrice: 900, IsActive: true}
+			if u == 0 {
+				a.TargetPrice = 1000
+			}
+			if u >= 48 {
+				a.Currency, a.TargetPrice = domain.CurrencyUSD, 10
+			}
+			_ = repo.CreateAlert(a)
+		}
+	}
+	_ = repo.CreateAlert(&domain.Alert{ID: "gone", ProductID: "MISSING", TargetPrice: 900, IsActive: true})
+	ag := &countingGateway{fetches: map[string]int{}}
+	fx := &fixedFX{rate: 100}
+
+	triggers, stats, err := NewAlertEvaluator(repo, ag, fx, 3, nil).EvaluateCycle(context.Background())
+	if err == nil {
+		t.Fatal("expected the missing product's error")
+	}
+	if ag.peak > 3 {
+		t.Fatalf("%d fetches in flight, limit 3", ag.peak)
+	}
+	for id, n := range ag.fetches {
+		if n != 1 {
+			t.Fatalf("product %s fetched %d times", id, n)
+		}
+	}
+	if fx.calls != 1 {
+		t.Fatalf("fx calls = %d, want 1", fx.calls)
+	}
+	// Per product: the 1000 ETB target and both 10 USD targets
+	if len(triggers) != 36 || triggers[0].Alert.ProductID != "P00" || triggers[35].Alert.ProductID != "P11" {
+		t.Fatalf("triggers = %d", len(triggers))
+	}
+	want := AlertCycleStats{ProductsFetched: 12, AlertsEvaluated: 600, AlertsTriggered: 36, Errors: 1}
+	stats.Duration = 0
+	if stats != want {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+// fencedOutRepository rejects every alert update as a replaced leader's.
+type fencedOutRepository struct {
+	*mockAlertRepository
+}
+
+func (r fencedOutRepository) UpdateAlert(ctx context.Context, alert *domain.Alert) error {
+	return domain.ErrStaleFencingToken
+}
+
+func TestAlertEvaluator_StaleLeaderReportsNoTriggers(t *testing.T) {
+	repo := newMockAlertRepository()
+	_ = repo.CreateAlert(&domain.Alert{ID: "hit", ProductID: "P1", TargetPrice: 1500, IsActive: true})
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Price: domain.Price{USD: 10}}}}
+
+	triggers, err := NewAlertEvaluator(fencedOutRepository{repo}, ag, &fixedFX{rate: 100}, 0, nil).Evaluate(context.Background())
+	if !errors.Is(err, domain.ErrStaleFencingToken) {
+		t.Fatalf("expected a stale token error, got %v", err)
+	}
+	if len(triggers) != 0 {
+		t.Fatalf("a replaced leader must not report triggers, got %d", len(triggers))
+	}
+}
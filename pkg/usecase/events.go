@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// Domain event names. They double as queue topic suffixes when events are
+// bridged onto the job queue.
+const (
+	EventAlertCreated   = "alert.created"
+	EventAlertTriggered = "alert.triggered"
+	EventPriceChanged   = "price.changed"
+	EventFXRateUpdated  = "fx.rate_updated"
+
+	// AllEvents subscribes a handler to every event.
+	AllEvents = "*"
+)
+
+// Event is something that happened in the domain. Publishers emit events
+// after the change is stored, so subscribers see committed state.
+type Event interface {
+	EventName() string
+	OccurredAt() time.Time
+}
+
+// AlertCreated is published when a user creates an alert.
+type AlertCreated struct {
+	Alert *domain.Alert `json:"alert"`
+	At    time.Time     `json:"at"`
+}
+
+func (e AlertCreated) EventName() string     { return EventAlertCreated }
+func (e AlertCreated) OccurredAt() time.Time { return e.At }
+
+// AlertTriggered is published when an alert's condition is met.
+type AlertTriggered struct {
+	Trigger *domain.AlertTrigger `json:"trigger"`
+}
+
+func (e AlertTriggered) EventName() string     { return EventAlertTriggered }
+func (e AlertTriggered) OccurredAt() time.Time { return e.Trigger.TriggeredAt }
+
+// PriceChanged is published when a tracked product's price differs from its
+// previous snapshot.
+type PriceChanged struct {
+	ProductID string       `json:"productId"`
+	Previous  domain.Price `json:"previous"`
+	Current   domain.Price `json:"current"`
+	At        time.Time    `json:"at"`
+}
+
+func (e PriceChanged) EventName() string     { return EventPriceChanged }
+func (e PriceChanged) OccurredAt() time.Time { return e.At }
+
+// FXRateUpdated is published when an exchange rate is recorded.
+type FXRateUpdated struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Rate float64   `json:"rate"`
+	At   time.Time `json:"at"`
+}
+
+func (e FXRateUpdated) EventName() string     { return EventFXRateUpdated }
+func (e FXRateUpdated) OccurredAt() time.Time { return e.At }
+
+// EventPublisher publishes domain events. Use cases take it as an optional
+// dependency, so a nil publisher disables events.
+type EventPublisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// EventHandler handles one published event.
+type EventHandler func(ctx context.Context, e Event) error
+
+type eventSubscription struct {
+	handler EventHandler
+	async   bool
+}
+
+// EventBus is an in-process EventPublisher. Sync subscribers run in Publish,
+// in subscription order, and their errors are returned to the publisher.
+// Async subscribers run in their own goroutine after Publish returns, with
+// a context that is not canceled with the publisher's.
+type EventBus struct {
+	mu      sync.RWMutex
+	subs    map[string][]eventSubscription
+	onError func(Event, error)
+	wg      sync.WaitGroup
+}
+
+var _ EventPublisher = (*EventBus)(nil)
+
+// NewEventBus creates a new EventBus. onError receives the error of every
+// failed subscriber, sync or async, and may be nil.
+func NewEventBus(onError func(Event, error)) *EventBus {
+	return &EventBus{subs: map[string][]eventSubscription{}, onError: onError}
+}
+
+// Subscribe runs h in Publish for events with the given name, or for every
+// event with AllEvents.
+func (b *EventBus) Subscribe(name string, h EventHandler) {
+	b.subscribe(name, eventSubscription{handler: h})
+}
+
+// SubscribeAsync runs h in the background for events with the given name, or
+// for every event with AllEvents.
+func (b *EventBus) SubscribeAsync(name string, h EventHandler) {
+	b.subscribe(name, eventSubscription{handler: h, async: true})
+}
+
+func (b *EventBus) subscribe(name string, s eventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[name] = append(b.subs[name], s)
+}
+
+// Publish delivers e to its subscribers and returns the errors of the sync
+// ones. A nil bus publishes nothing.
+func (b *EventBus) Publish(ctx context.Context, e Event) error {
+	if b == nil || e == nil {
+		return nil
+	}
+	b.mu.RLock()
+	subs := append(append([]eventSubscription(nil), b.subs[e.EventName()]...), b.subs[AllEvents]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, s := range subs {
+		if s.async {
+			b.wg.Add(1)
+			go func(h EventHandler) {
+				defer b.wg.Done()
+				b.report(e, b.deliver(context.WithoutCancel(ctx), h, e))
+			}(s.handler)
+			continue
+		}
+		if err := b.deliver(ctx, s.handler, e); err != nil {
+			b.report(e, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wait blocks until every async subscriber started so far has returned,
+// e.g. before shutting down.
+func (b *EventBus) Wait() {
+	b.wg.Wait()
+}
+
+// deliver runs one subscriber, turning a panic into an error so a faulty
+// subscriber cannot take down the publisher.
+func (b *EventBus) deliver(ctx context.Context, h EventHandler, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event %s: subscriber panicked: %v", e.EventName(), r)
+		}
+	}()
+	if err := h(ctx, e); err != nil {
+		return fmt.Errorf("event %s: %w", e.EventName(), err)
+	}
+	return nil
+}
+
+func (b *EventBus) report(e Event, err error) {
+	if err != nil && b.onError != nil {
+		b.onError(e, err)
+	}
+}
+
+// EventEnvelope is the queue payload of a bridged event.
+type EventEnvelope struct {
+	Name       string          `json:"name"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// QueueBridge returns a handler that enqueues events as EventEnvelope JSON on
+// the topic prefix+name, e.g. "events.alert.triggered", so other processes
+// can consume them. Delivery is at least once.
+func QueueBridge(queue JobQueue, prefix string) EventHandler {
+	return func(ctx context.Context, e Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(EventEnvelope{Name: e.EventName(), OccurredAt: e.OccurredAt(), Data: data})
+		if err != nil {
+			return err
+		}
+		_, err = queue.Enqueue(ctx, prefix+e.EventName(), payload)
+		return err
+	}
+}
+
+// DecodeEvent turns a payload written by QueueBridge back into its typed event.
+func DecodeEvent(payload []byte) (Event, error) {
+	var env EventEnvelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return nil, fmt.Errorf("%w: event envelope: %v", domain.ErrInvalidInput, err)
+	}
+	var e Event
+	var err error
+	switch env.Name {
+	case EventAlertCreated:
+		var v AlertCreated
+		err = json.Unmarshal(env.Data, &v)
+		e = v
+	case EventAlertTriggered:
+		var v AlertTriggered
+		if err = json.Unmarshal(env.Data, &v); err == nil && v.Trigger == nil {
+			err = errors.New("missing trigger")
+		}
+		e = v
+	case EventPriceChanged:
+		var v PriceChanged
+		err = json.Unmarshal(env.Data, &v)
+		e = v
+	case EventFXRateUpdated:
+		var v FXRateUpdated
+		err = json.Unmarshal(env.Data, &v)
+		e = v
+	default:
+		return nil, fmt.Errorf("%w: unknown event %q", domain.ErrInvalidInput, env.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: event %s: %v", domain.ErrInvalidInput, env.Name, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryJobQueue struct {
+	mu     sync.Mutex
+	topics []string
+	jobs   [][]byte
+}
+
+func (q *memoryJobQueue) Enqueue(ctx context.Context, topic string, payload []byte) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.topics = append(q.topics, topic)
+	q.jobs = append(q.jobs, payload)
+	return topic, nil
+}
+
+type recordingEvents struct {
+	events []Event
+}
+
+func (r *recordingEvents) Publish(ctx context.Context, e Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestEventBus_SyncAndAsyncSubscribers(t *testing.T) {
+	var reported []error
+	var mu sync.Mutex
+	bus := NewEventBus(func(e Event, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+
+	var order []string
+	bus.Subscribe(EventFXRateUpdated, func(ctx context.Context, e Event) error {
+		order = append(order, "first")
+		return nil
+	})
+	bus.Subscribe(EventFXRateUpdated, func(ctx context.Context, e Event) error {
+		order = append(order, "second")
+		return errors.New("boom")
+	})
+	bus.Subscribe(EventPriceChanged, func(ctx context.Context, e Event) error {
+		t.Error("subscriber of another event was called")
+		return nil
+	})
+	async := make(chan Event, 2)
+	bus.SubscribeAsync(AllEvents, func(ctx context.Context, e Event) error {
+		async <- e
+		return nil
+	})
+	bus.SubscribeAsync(AllEvents, func(ctx context.Context, e Event) error {
+		panic("faulty subscriber")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := bus.Publish(ctx, FXRateUpdated{From: "USD", To: "ETB", Rate: 150})
+	cancel()
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the sync subscriber's error, got %v", err)
+	}
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("sync subscribers ran as %v", order)
+	}
+
+	bus.Wait()
+	if e := <-async; e.(FXRateUpdated).Rate != 150 {
+		t.Errorf("async subscriber got %+v", e)
+	}
+	if len(reported) != 2 || !strings.Contains(reported[1].Error(), "panicked") {
+		t.Errorf("expected the sync error and the panic to be reported, got %v", reported)
+	}
+
+	var nilBus *EventBus
+	if err := nilBus.Publish(context.Background(), FXRateUpdated{}); err != nil {
+		t.Errorf("nil bus: %v", err)
+	}
+}
+
+func TestQueueBridge_RoundTrip(t *testing.T) {
+	queue := &memoryJobQueue{}
+	bus := NewEventBus(nil)
+	bus.Subscribe(AllEvents, QueueBridge(queue, "events."))
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	trigger := &domain.AlertTrigger{
+		Alert:       &domain.Alert{ID: "A1", ProductID: "P1"},
+		Price:       domain.Price{USD: 9, ETB: 1350},
+		FXRate:      150,
+		Cause:       domain.DropCauseSeller,
+		TriggeredAt: at,
+	}
+	if err := bus.Publish(context.Background(), AlertTriggered{Trigger: trigger}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(queue.topics) != 1 || queue.topics[0] != "events.alert.triggered" {
+		t.Fatalf("unexpected topics %v", queue.topics)
+	}
+
+	e, err := DecodeEvent(queue.jobs[0])
+	if err != nil {
+		t.Fatalf("DecodeEvent failed: %v", err)
+	}
+	got, ok := e.(AlertTriggered)
+	if !ok {
+		t.Fatalf("decoded %T", e)
+	}
+	if got.Trigger.Alert.ID != "A1" || got.Trigger.Cause != domain.DropCauseSeller || !got.OccurredAt().Equal(at) {
+		t.Errorf("unexpected trigger %+v", got.Trigger)
+	}
+
+	if _, err := DecodeEvent([]byte(`{"name":"nope","data":{}}`)); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown event, got %v", err)
+	}
+}
+
+func TestPublishers_EmitEvents(t *testing.T) {
+	ctx := context.Background()
+	events := &recordingEvents{}
+
+	m := NewAlertManager(newMockAlertRepository(), nil, nil, 0, events)
+	if err := m.CreateAlert(ctx, &domain.Alert{UserID: "U1", ProductID: "P1", TargetPrice: 100}); err != nil {
+		t.Fatalf("CreateAlert failed: %v", err)
+	}
+
+	history := &memoryPriceHistory{}
+	tracker := NewPriceTracker(nil, nil, history, nil, nil, 0, events)
+	for _, usd := range []float64{10, 10, 8} {
+		if _, err := tracker.RecordPrice(ctx, "P1", usd, 150); err != nil {
+			t.Fatalf("RecordPrice failed: %v", err)
+		}
+	}
+
+	fxTracker := NewFXTracker(&fixedFX{rate: 151}, &memoryFXHistory{}, events)
+	if _, err := fxTracker.Record(ctx, "usd", "etb"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	var names []string
+	for _, e := range events.events {
+		names = append(names, e.EventName())
+	}
+	// The first price is a baseline and the repeated one is unchanged
+	if strings.Join(names, ",") != "alert.created,price.changed,fx.rate_updated" {
+		t.Fatalf("unexpected events %v", names)
+	}
+	if created := events.events[0].(AlertCreated); created.Alert.UserID != "U1" || !created.Alert.IsActive {
+		t.Errorf("unexpected alert %+v", created.Alert)
+	}
+	if pc := events.events[1].(PriceChanged); pc.Previous.USD != 10 || pc.Current.USD != 8 {
+		t.Errorf("unexpected price change %+v", pc)
+	}
+	if fx := events.events[2].(FXRateUpdated); fx.From != "USD" || fx.To != "ETB" || fx.Rate != 151 {
+		t.Errorf("unexpected fx event %+v", fx)
+	}
+}
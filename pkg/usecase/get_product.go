@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// GetProductUseCase loads a single product and enriches it for the detail view:
+// prices converted to ETB, shipping costs converted, and LLM summary bullets.
+// Enriched products are cached through the cache port, and every lookup is
+// recorded as a view so the product's price gets tracked. The deal verdict and
+// price prediction are computed per request since they depend on the latest
+// price history.
+type GetProductUseCase struct {
+	alibabaGateway AlibabaGateway
+	llmGateway     LLMGateway
+	fx             IFXClient
+	cache          ICachePort
+	views          ViewTracker
+	deals          *DealAnalyzer
+	predictor      *PricePredictor
+	ttl            time.Duration
+}
+
+// ProductOptions tunes the product detail lookup.
+type ProductOptions struct {
+	// HorizonDays, when positive, attaches a prediction of whether the ETB
+	// price drops within that many days.
+	HorizonDays int
+}
+
+// NewGetProductUseCase creates a new GetProductUseCase. lg, fx, cache, views,
+// deals and predictor may be nil, in which case the corresponding step is
+// skipped.
+func NewGetProductUseCase(ag AlibabaGateway, lg LLMGateway, fx IFXClient, cache ICachePort, views ViewTracker, deals *DealAnalyzer, predictor *PricePredictor, ttl time.Duration) *GetProductUseCase {
+	return &GetProductUseCase{
+		alibabaGateway: ag,
+		llmGateway:     lg,
+		fx:             fx,
+		cache:          cache,
+		views:          views,
+		deals:          deals,
+		predictor:      predictor,
+		ttl:            ttl,
+	}
+}
+
+func productCacheKey(id string) string {
+	return "product:" + id
+}
+
+// GetProduct returns the enriched product or domain.ErrProductNotFound.
+func (uc *GetProductUseCase) GetProduct(ctx context.Context, id string, opts ProductOptions) (*domain.Product, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, domain.ErrProductNotFound
+	}
+	p, err := uc.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if uc.views != nil {
+		// View tracking only feeds price history; never fail the request on it
+		_ = uc.views.TrackView(ctx, id)
+	}
+	if uc.deals != nil {
+		if v, derr := uc.deals.Analyze(ctx, p); derr == nil {
+			p.Deal = v
+		}
+	}
+	if uc.predictor != nil && opts.HorizonDays > 0 {
+		// The prediction is advisory; serve the product even if it fails
+		if pred, perr := uc.predictor.Predict(ctx, p, opts.HorizonDays); perr == nil {
+			p.Prediction = pred
+		}
+	}
+	return p, nil
+}
+
+// load returns the enriched product from cache or from the gateway.
+func (uc *GetProductUseCase) load(ctx context.Context, id string) (*domain.Product, error) {
+	key := productCacheKey(id)
+
+	// 1) Try cache
+	if uc.cache != nil {
+		if val, ok, err := uc.cache.Get(ctx, key); err == nil && ok {
+			var p domain.Product
+			if jerr := json.Unmarshal([]byte(val), &p); jerr == nil {
+				return &p, nil
+			}
+			// fall through on decode error
+		}
+	}
+
+	// 2) Cache miss -> fetch and enrich
+	p, err := uc.alibabaGateway.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.convertPrices(ctx, p)
+	if uc.llmGateway != nil {
+		// Summaries are best effort; keep the gateway bullets on failure
+		if bullets, serr := uc.llmGateway.SummarizeProduct(ctx, p); serr == nil && len(bullets) > 0 {
+			p.SummaryBullets = bullets
+		}
+	}
+
+	// 3) Write-through
+	if uc.cache != nil {
+		if b, jerr := json.Marshal(p); jerr == nil {
+			_ = uc.cache.Set(ctx, key, string(b), uc.ttl)
+		}
+	}
+
+	return p, nil
+}
+
+// convertPrices refreshes the ETB amounts from the USD amounts using the
+// current USD->ETB rate. On FX failure the gateway-provided values are kept.
+func (uc *GetProductUseCase) convertPrices(ctx context.Context, p *domain.Product) {
+	if uc.fx == nil {
+		return
+	}
+	rate, err := uc.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil || rate <= 0 {
+		return
+	}
+	now := time.Now().UTC()
+	p.Price = convertPrice(p.Price.USD, rate, now)
+	for i := range p.Shipping {
+		p.Shipping[i].Cost = convertPrice(p.Shipping[i].Cost.USD, rate, now)
+	}
+}
+
+func convertPrice(usd, rate float64, at time.Time) domain.Price {
+	return domain.Price{USD: usd, ETB: roundCents(usd * rate), FXTimestamp: at}
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryCache struct {
+	values map[string]string
+	sets   int
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: map[string]string{}}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (string, bool, error) {
+	v, ok := c.values[key]
+	return v, ok, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key, val string, ttl time.Duration) error {
+	c.sets++
+	c.values[key] = val
+	return nil
+}
+
+type fixedFX struct {
+	rate  float64
+	err   error
+	calls int
+}
+
+func (f *fixedFX) GetRate(ctx context.Context, from, to string) (float64, error) {
+	f.calls++
+	return f.rate, f.err
+}
+
+func TestGetProductUseCase(t *testing.T) {
+	ag := &stubAlibabaGateway{products: []*domain.Product{{
+		ID:       "MOCK-1",
+		Price:    domain.Price{USD: 10, ETB: 1},
+		Shipping: []domain.ShippingOption{{Method: "Express", Cost: domain.Price{USD: 2.5}}},
+	}}}
+
+	t.Run("ConvertsSummarizesAndCaches", func(t *testing.T) {
+		cache := newMemoryCache()
+		fx := &fixedFX{rate: 56.5}
+		uc := NewGetProductUseCase(ag, &stubLLMGateway{}, fx, cache, nil, nil, nil, time.Minute)
+
+		p, err := uc.GetProduct(context.Background(), "MOCK-1", ProductOptions{})
+		if err != nil {
+			t.Fatalf("GetProduct failed: %v", err)
+		}
+		if p.Price.ETB != 565 || p.Shipping[0].Cost.ETB != 141.25 {
+			t.Errorf("prices not converted: %+v / %+v", p.Price, p.Shipping[0].Cost)
+		}
+		if p.Price.FXTimestamp.IsZero() {
+			t.Error("FX timestamp not set")
+		}
+		if len(p.SummaryBullets) != 1 || p.SummaryBullets[0] != "summary of MOCK-1" {
+			t.Errorf("unexpected summary: %v", p.SummaryBullets)
+		}
+		if _, ok := cache.values["product:MOCK-1"]; !ok {
+			t.Fatal("product was not cached")
+		}
+
+		// Second call is served from cache without touching FX again
+		again, err := uc.GetProduct(context.Background(), "MOCK-1", ProductOptions{})
+		if err != nil {
+			t.Fatalf("cached GetProduct failed: %v", err)
+		}
+		if again.Price.ETB != 565 || fx.calls != 1 || cache.sets != 1 {
+			t.Errorf("expected cache hit, fx calls=%d sets=%d", fx.calls, cache.sets)
+		}
+	})
+
+	t.Run("FXFailureKeepsGatewayPrices", func(t *testing.T) {
+		uc := NewGetProductUseCase(ag, nil, &fixedFX{err: errors.New("down")}, nil, nil, nil, nil, 0)
+
+		p, err := uc.GetProduct(context.Background(), "MOCK-1", ProductOptions{})
+		if err != nil {
+			t.Fatalf("GetProduct failed: %v", err)
+		}
+		if p.Price.ETB != 1 {
+			t.Errorf("expected gateway ETB price, got %v", p.Price.ETB)
+		}
+	})
+
+	t.Run("PredictsWithinTheHorizon", func(t *testing.T) {
+		history := dailyHistory("MOCK-1", 60, func(int) float64 { return 10 })
+		predictor := NewPricePredictor(history, &memoryFXHistory{}, &fixedFX{rate: 150}, 0)
+		uc := NewGetProductUseCase(ag, nil, nil, nil, nil, nil, predictor, 0)
+
+		p, err := uc.GetProduct(context.Background(), "MOCK-1", ProductOptions{HorizonDays: 7})
+		if err != nil {
+			t.Fatalf("GetProduct failed: %v", err)
+		}
+		if p.Prediction == nil || p.Prediction.HorizonDays != 7 {
+			t.Fatalf("expected a 7-day prediction, got %+v", p.Prediction)
+		}
+
+		p, err = uc.GetProduct(context.Background(), "MOCK-1", ProductOptions{})
+		if err != nil || p.Prediction != nil {
+			t.Fatalf("predicted without a horizon: %+v %v", p.Prediction, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		uc := NewGetProductUseCase(ag, nil, nil, nil, nil, nil, nil, 0)
+
+		_, err := uc.GetProduct(context.Background(), "missing", ProductOptions{})
+		if !errors.Is(err, domain.ErrProductNotFound) {
+			t.Fatalf("expected ErrProductNotFound, got %v", err)
+		}
+	})
+}
@@ -10,12 +10,17 @@ import (
 // AlibabaGateway defines the contract for fetching products from an external source.
 type AlibabaGateway interface {
 	FetchProducts(ctx context.Context, query string, filters map[string]interface{}) ([]*domain.Product, error)
+	// GetProduct returns the full product, including seller and shipping details.
+	// It returns domain.ErrProductNotFound when the ID is unknown.
+	GetProduct(ctx context.Context, id string) (*domain.Product, error)
 }
 
 // LLMGateway defines the contract for a Large Language Model service
 // to parse user intent from a search query.
 type LLMGateway interface {
 	ParseIntent(ctx context.Context, query string) (map[string]interface{}, error)
+	// SummarizeProduct returns short, shopper-facing bullets for a product.
+	SummarizeProduct(ctx context.Context, product *domain.Product) ([]string, error)
 }
 
 // CacheGateway defines the contract for a caching service.
@@ -39,4 +44,190 @@ type AlertRepository interface {
 	CreateAlert(alert *domain.Alert) error
 	GetAlert(alertID string) (*domain.Alert, error)
 	DeleteAlert(alertID string) error
+	ListActiveAlerts() ([]*domain.Alert, error)
+	// ListActiveUserAlerts returns the user's active alerts.
+	ListActiveUserAlerts(userID string) ([]*domain.Alert, error)
+	// UpdateAlert replaces a stored alert. A write under a fencing token older
+	// than one that already wrote the alert fails with
+	// domain.ErrStaleFencingToken.
+	UpdateAlert(ctx context.Context, alert *domain.Alert) error
+}
+
+// SavedSearchRepository stores users' saved searches.
+type SavedSearchRepository interface {
+	CreateSavedSearch(ctx context.Context, s *domain.SavedSearch) error
+	// ListSavedSearches returns the user's saved searches, or every saved
+	// search when userID is empty.
+	ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error)
+	// DeleteSavedSearch returns domain.ErrSavedSearchNotFound when the user has no such search.
+	DeleteSavedSearch(ctx context.Context, userID, id string) error
+	// SaveRunResults records the products the search has seen, newest first.
+	SaveRunResults(ctx context.Context, id string, productIDs []string, runAt time.Time) error
+}
+
+// WatchlistRepository stores each user's watchlist as one document.
+type WatchlistRepository interface {
+	// GetWatchlist returns nil, nil when the user has no watchlist.
+	GetWatchlist(ctx context.Context, userID string) (*domain.Watchlist, error)
+	// SaveWatchlist stores w and increments its Version, or returns
+	// domain.ErrWatchlistConflict when the stored watchlist is no longer at
+	// w.Version. Version 0 is a watchlist that was not stored yet.
+	SaveWatchlist(ctx context.Context, w *domain.Watchlist) error
+}
+
+// WebhookRepository stores API clients' webhooks.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, w *domain.Webhook) error
+	ListWebhooks(ctx context.Context, clientID string) ([]*domain.Webhook, error)
+	// GetWebhook returns domain.ErrWebhookNotFound for an unknown ID.
+	GetWebhook(ctx context.Context, id string) (*domain.Webhook, error)
+	// DeleteWebhook returns domain.ErrWebhookNotFound when the client has no such webhook.
+	DeleteWebhook(ctx context.Context, clientID, id string) error
+}
+
+// WebhookDeliveryRepository stores webhook deliveries and their attempt logs.
+type WebhookDeliveryRepository interface {
+	// CreateDelivery stores d unless a delivery with its ID exists. It
+	// reports whether d was stored.
+	CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) (bool, error)
+	// GetDelivery returns domain.ErrWebhookDeliveryNotFound for an unknown ID.
+	GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	SaveDelivery(ctx context.Context, d *domain.WebhookDelivery) error
+	// ListDeliveries returns the client's deliveries, newest first. An empty
+	// status lists deliveries in any status.
+	ListDeliveries(ctx context.Context, clientID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+// WebhookSender posts a webhook request and returns the response status code.
+type WebhookSender interface {
+	Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// PriceHistoryRepository stores product price snapshots.
+type PriceHistoryRepository interface {
+	// LatestSnapshot returns the most recent snapshot, or nil when none exists.
+	LatestSnapshot(ctx context.Context, productID string) (*domain.PriceSnapshot, error)
+	InsertSnapshot(ctx context.Context, snapshot *domain.PriceSnapshot) error
+	// TouchSnapshot marks an unchanged snapshot as still current at seenAt.
+	TouchSnapshot(ctx context.Context, id string, seenAt time.Time) error
+	// ListSnapshots returns snapshots still current at or after since, oldest first.
+	ListSnapshots(ctx context.Context, productID string, since time.Time) ([]*domain.PriceSnapshot, error)
+	// ListSnapshotsFor is ListSnapshots for several products in one query,
+	// keyed by product ID.
+	ListSnapshotsFor(ctx context.Context, productIDs []string, since time.Time) (map[string][]*domain.PriceSnapshot, error)
+}
+
+// FXHistoryRepository stores exchange rate observations.
+type FXHistoryRepository interface {
+	RecordRate(ctx context.Context, snapshot *domain.FXRateSnapshot) error
+	// ListRates returns observations recorded at or after since, oldest first.
+	ListRates(ctx context.Context, from, to string, since time.Time) ([]*domain.FXRateSnapshot, error)
+}
+
+// ViewTracker records which products were recently viewed.
+type ViewTracker interface {
+	TrackView(ctx context.Context, productID string) error
+	RecentlyViewed(ctx context.Context, since time.Time) ([]string, error)
+}
+
+// JobQueue enqueues background jobs. Delivery is at least once, so job
+// handlers must be idempotent.
+type JobQueue interface {
+	Enqueue(ctx context.Context, topic string, payload []byte) (string, error)
+}
+
+// NotificationOutbox persists notification intents until every channel has
+// delivered them.
+type NotificationOutbox interface {
+	// Enqueue stores n unless a notification with the same idempotency key
+	// exists. It reports whether n was stored.
+	Enqueue(ctx context.Context, n *domain.Notification) (bool, error)
+	// ClaimDue locks up to limit pending notifications due at now for the lease
+	// duration, so concurrent dispatchers do not send them twice.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Notification, error)
+	// Save stores the delivery state of a claimed notification and releases
+	// it. Stale fencing tokens are rejected as in AlertRepository.UpdateAlert.
+	Save(ctx context.Context, n *domain.Notification) error
+	// CountSent counts the user's notifications delivered on any channel since the given time.
+	CountSent(ctx context.Context, userID string, since time.Time) (int, error)
+}
+
+// NotificationChannel delivers notifications over one medium. Send returns an
+// error wrapping domain.ErrDeliveryRejected for permanent failures and
+// domain.ErrNoRecipient when the user cannot be reached on the channel.
+type NotificationChannel interface {
+	Kind() domain.ChannelKind
+	Send(ctx context.Context, n *domain.Notification, idempotencyKey string) (providerID string, err error)
+}
+
+// DeviceRepository stores push-capable devices keyed by FCM token.
+type DeviceRepository interface {
+	// UpsertDevice stores the device, moving the token to d.UserID if another
+	// user had it. CreatedAt is kept for known tokens.
+	UpsertDevice(ctx context.Context, d *domain.Device) error
+	// DeleteDevice removes the user's token or returns domain.ErrDeviceNotFound.
+	DeleteDevice(ctx context.Context, userID, token string) error
+	ListDevices(ctx context.Context, userID string) ([]*domain.Device, error)
+	// DeleteTokens removes tokens regardless of owner, e.g. when FCM rejects them.
+	DeleteTokens(ctx context.Context, tokens []string) error
+	// DeleteStale removes devices last seen before the given time.
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PreferencesRepository stores per-user notification preferences.
+type PreferencesRepository interface {
+	// GetPreferences returns nil, nil when the user has not saved any.
+	GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, p *domain.NotificationPreferences) error
+}
+
+// TelegramLinkRepository stores chat links and pending one-time link codes.
+type TelegramLinkRepository interface {
+	CreateLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error
+	// ConsumeLinkCode deletes and returns an unexpired code, or returns
+	// domain.ErrLinkCodeInvalid.
+	ConsumeLinkCode(ctx context.Context, code string, now time.Time) (*domain.TelegramLinkCode, error)
+	// SaveLink links the user, replacing any earlier link of the user or the chat.
+	SaveLink(ctx context.Context, link *domain.TelegramLink) error
+	// GetLinkByUser and GetLinkByChat return nil, nil when there is no link.
+	GetLinkByUser(ctx context.Context, userID string) (*domain.TelegramLink, error)
+	GetLinkByChat(ctx context.Context, chatID int64) (*domain.TelegramLink, error)
+	DeleteLinkByChat(ctx context.Context, chatID int64) error
+	// RecordLinkFailure counts a failed link attempt of the chat and returns
+	// its failures in the current window, which opens with the first failure
+	// and lasts window.
+	RecordLinkFailure(ctx context.Context, chatID int64, now time.Time, window time.Duration) (int, error)
+	// LinkFailures returns the chat's failures in its current window.
+	LinkFailures(ctx context.Context, chatID int64, now time.Time) (int, error)
+}
+
+// TelegramSender sends bot messages to a chat.
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, msg domain.TelegramMessage) (messageID int64, err error)
+}
+
+// SMSSender sends text messages through an SMS provider.
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, text string) (messageID string, err error)
+}
+
+// SMSSessionStore keeps the last search results sent to each phone.
+type SMSSessionStore interface {
+	SaveSession(ctx context.Context, s *domain.SMSSession) error
+	// LoadSession returns nil, nil when the phone has no live session.
+	LoadSession(ctx context.Context, phone string) (*domain.SMSSession, error)
+	// MarkReceived records an incoming message ID and reports whether it is
+	// the first time the ID was seen.
+	MarkReceived(ctx context.Context, messageID string) (bool, error)
+}
+
+// URLExpander follows a short link's redirects and returns the URL it
+// ends at.
+type URLExpander interface {
+	Expand(ctx context.Context, shortURL string) (string, error)
+}
+
+// LinkShortener turns long URLs into short links that fit in an SMS.
+type LinkShortener interface {
+	Shorten(ctx context.Context, longURL string) (string, error)
 }
@@ -1,24 +1,154 @@
 package usecase
 
-import "github.com/shopally-ai/pkg/domain"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
 
 type AlertManager struct {
-	repo AlertRepository
+	repo           AlertRepository
+	alibabaGateway AlibabaGateway
+	fx             IFXClient
+	maxActive      int
+	events         EventPublisher
 }
 
-func NewAlertManager(repo AlertRepository) *AlertManager {
+// NewAlertManager creates a new AlertManager. ag checks that an alert's
+// product exists, and with fx records the product's baseline when the alert
+// is created; without both only alerts that need no baseline can be created. maxActive caps each user's active alerts; 0
+// means no limit. events receives AlertCreated and may be nil.
+func NewAlertManager(repo AlertRepository, ag AlibabaGateway, fx IFXClient, maxActive int, events EventPublisher) *AlertManager {
 	return &AlertManager{
-		repo: repo,
+		repo:           repo,
+		alibabaGateway: ag,
+		fx:             fx,
+		maxActive:      maxActive,
+		events:         events,
+	}
+}
+
+// CreateAlert validates the alert's currency, condition and lifecycle,
+// records the product's current price, stock and delivery estimate as its
+// baseline and stores it. Alerts without a condition are target_price alerts
+// on TargetPrice. A user can have one active alert per product and at most
+// maxActive active alerts.
+func (m *AlertManager) CreateAlert(ctx context.Context, alert *domain.Alert) error {
+	if alert.UserID == "" || alert.ProductID == "" {
+		return fmt.Errorf("%w: userId and productId are required", domain.ErrInvalidInput)
+	}
+	alert.Currency = strings.ToUpper(strings.TrimSpace(alert.Currency))
+	if alert.Currency == "" {
+		alert.Currency = domain.CurrencyETB
+	}
+	if !domain.ValidAlertCurrency(alert.Currency) {
+		return fmt.Errorf("%w: unsupported currency %q", domain.ErrInvalidInput, alert.Currency)
+	}
+	alert.Condition = alert.EffectiveCondition()
+	if err := alert.Condition.Validate(); err != nil {
+		return err
+	}
+	if alert.Condition.Kind == domain.AlertTargetPrice {
+		alert.TargetPrice = alert.Condition.TargetPrice
+	} else {
+		alert.TargetPrice = 0
+	}
+
+	now := time.Now().UTC()
+	if err := alert.ValidateLifecycle(now); err != nil {
+		return err
+	}
+
+	active, err := m.repo.ListActiveUserAlerts(alert.UserID)
+	if err != nil {
+		return fmt.Errorf("list user alerts: %w", err)
+	}
+	for _, a := range active {
+		if a.ProductID == alert.ProductID {
+			return fmt.Errorf("%w: alert %s already watches product %s", domain.ErrAlertExists, a.ID, alert.ProductID)
+		}
+	}
+	if m.maxActive > 0 && len(active) >= m.maxActive {
+		return fmt.Errorf("%w: at most %d active alerts", domain.ErrAlertLimit, m.maxActive)
+	}
+
+	baseline, err := m.baseline(ctx, alert.ProductID)
+	if err != nil {
+		return err
+	}
+	if baseline == nil && alert.Condition.NeedsBaseline() {
+		return fmt.Errorf("%s alerts need the product's current state, which is unavailable", alert.Condition.Kind)
+	}
+	if baseline != nil && alert.Condition.Kind == domain.AlertBackInStock && baseline.InStock {
+		return fmt.Errorf("%w: the product is in stock", domain.ErrInvalidInput)
+	}
+
+	alert.Baseline = baseline
+	alert.IsActive = true
+	alert.CreatedAt = now
+	alert.Disarmed, alert.TriggerCount, alert.LastTriggeredAt, alert.EndReason = false, 0, nil, ""
+	if err := m.repo.CreateAlert(alert); err != nil {
+		return err
+	}
+	if m.events != nil {
+		// The alert is stored; a failing subscriber is the bus's to report
+		_ = m.events.Publish(ctx, AlertCreated{Alert: alert, At: now})
 	}
+	return nil
 }
-func (m *AlertManager) CreateAlert(alert *domain.Alert) error {
-	return m.repo.CreateAlert(alert)
+
+// baseline returns the product's current state, or nil when the manager has
+// no product source or FX rate. An unknown product is rejected whenever there
+// is a product source.
+func (m *AlertManager) baseline(ctx context.Context, productID string) (*domain.AlertBaseline, error) {
+	if m.alibabaGateway == nil {
+		return nil, nil
+	}
+	p, err := m.alibabaGateway.GetProduct(ctx, productID)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return nil, fmt.Errorf("%w: unknown product %q", domain.ErrInvalidInput, productID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("product %s: %w", productID, err)
+	}
+	if m.fx == nil {
+		return nil, nil
+	}
+	rate, err := m.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil {
+		return nil, fmt.Errorf("fx rate: %w", err)
+	}
+	now := time.Now().UTC()
+	return &domain.AlertBaseline{
+		Price:        convertPrice(p.Price.USD, rate, now),
+		FXRate:       rate,
+		InStock:      p.InStock(),
+		DeliveryDays: p.DeliveryDays(),
+		RecordedAt:   now,
+	}, nil
 }
 
-func (m *AlertManager) GetAlert(alertID string) (*domain.Alert, error) {
-	return m.repo.GetAlert(alertID)
+// GetAlert returns one of the user's alerts. Another user's alert is
+// reported as not found.
+func (m *AlertManager) GetAlert(userID, alertID string) (*domain.Alert, error) {
+	alert, err := m.repo.GetAlert(alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", domain.ErrAlertNotFound, alertID)
+	}
+	return alert, nil
 }
 
-func (m *AlertManager) DeleteAlert(alertID string) error {
+// DeleteAlert removes one of the user's alerts.
+func (m *AlertManager) DeleteAlert(userID, alertID string) error {
+	if _, err := m.GetAlert(userID, alertID); err != nil {
+		return err
+	}
 	return m.repo.DeleteAlert(alertID)
 }
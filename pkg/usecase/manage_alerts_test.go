@@ -1,10 +1,13 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/shopally-ai/pkg/domain"
 	"sync"
 	"testing"
+	"time"
 )
 
 type mockAlertRepository struct {
@@ -38,9 +41,39 @@ func (m *mockAlertRepository) DeleteAlert(alertID string) error {
 	return nil
 }
 
+func (m *mockAlertRepository) ListActiveAlerts() ([]*domain.Alert, error) {
+	var out []*domain.Alert
+	m.alerts.Range(func(_, value interface{}) bool {
+		if alert := value.(*domain.Alert); alert.IsActive {
+			out = append(out, alert)
+		}
+		return true
+	})
+	return out, nil
+}
+
+func (m *mockAlertRepository) ListActiveUserAlerts(userID string) ([]*domain.Alert, error) {
+	var out []*domain.Alert
+	m.alerts.Range(func(_, value interface{}) bool {
+		if alert := value.(*domain.Alert); alert.IsActive && alert.UserID == userID {
+			out = append(out, alert)
+		}
+		return true
+	})
+	return out, nil
+}
+
+func (m *mockAlertRepository) UpdateAlert(ctx context.Context, alert *domain.Alert) error {
+	if _, ok := m.alerts.Load(alert.ID); !ok {
+		return fmt.Errorf("alert with ID %s not found", alert.ID)
+	}
+	m.alerts.Store(alert.ID, alert)
+	return nil
+}
+
 func TestAlertManager_UseCases(t *testing.T) {
 	mockRepo := newMockAlertRepository()
-	alertManager := NewAlertManager(mockRepo)
+	alertManager := NewAlertManager(mockRepo, nil, nil, 0, nil)
 
 	sampleAlert := &domain.Alert{
 		UserID:      "user-123",
@@ -50,7 +83,7 @@ func TestAlertManager_UseCases(t *testing.T) {
 
 	var createdAlertID string
 	t.Run("CreateAlert_Success", func(t *testing.T) {
-		err := alertManager.CreateAlert(sampleAlert)
+		err := alertManager.CreateAlert(context.Background(), sampleAlert)
 		if err != nil {
 			t.Fatalf("CreateAlert failed: %v", err)
 		}
@@ -60,7 +93,7 @@ func TestAlertManager_UseCases(t *testing.T) {
 		createdAlertID = sampleAlert.ID
 	})
 	t.Run("GetAlert_Success", func(t *testing.T) {
-		retrievedAlert, err := alertManager.GetAlert(createdAlertID)
+		retrievedAlert, err := alertManager.GetAlert("user-123", createdAlertID)
 		if err != nil {
 			t.Fatalf("GetAlert failed: %v", err)
 		}
@@ -70,28 +103,140 @@ func TestAlertManager_UseCases(t *testing.T) {
 	})
 
 	t.Run("GetAlert_NotFound", func(t *testing.T) {
-		_, err := alertManager.GetAlert("non-existent-id")
+		_, err := alertManager.GetAlert("user-123", "non-existent-id")
 		if err == nil {
 			t.Fatal("GetAlert for non-existent ID did not return an error")
 		}
 	})
 
+	t.Run("OtherUsersAlert_NotFound", func(t *testing.T) {
+		if _, err := alertManager.GetAlert("user-456", createdAlertID); !errors.Is(err, domain.ErrAlertNotFound) {
+			t.Fatalf("GetAlert of another user's alert: got %v, want ErrAlertNotFound", err)
+		}
+		if err := alertManager.DeleteAlert("user-456", createdAlertID); !errors.Is(err, domain.ErrAlertNotFound) {
+			t.Fatalf("DeleteAlert of another user's alert: got %v, want ErrAlertNotFound", err)
+		}
+		if _, err := alertManager.GetAlert("user-123", createdAlertID); err != nil {
+			t.Fatalf("alert was deleted by another user: %v", err)
+		}
+	})
+
 	t.Run("DeleteAlert_Success", func(t *testing.T) {
-		err := alertManager.DeleteAlert(createdAlertID)
+		err := alertManager.DeleteAlert("user-123", createdAlertID)
 		if err != nil {
 			t.Fatalf("DeleteAlert failed: %v", err)
 		}
 
-		_, err = alertManager.GetAlert(createdAlertID)
+		_, err = alertManager.GetAlert("user-123", createdAlertID)
 		if err == nil {
 			t.Fatal("Alert was not deleted as expected")
 		}
 	})
 
 	t.Run("DeleteAlert_NotFound", func(t *testing.T) {
-		err := alertManager.DeleteAlert("non-existent-id")
+		err := alertManager.DeleteAlert("user-123", "non-existent-id")
 		if err == nil {
 			t.Fatal("DeleteAlert for non-existent ID did not return an error")
 		}
 	})
 }
+
+func TestAlertManager_Conditions(t *testing.T) {
+	ctx := context.Background()
+	soldOut := 0
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Price: domain.Price{USD: 10}, Delivery: &domain.DeliveryWindow{MinDays: 10, MaxDays: 20}},
+		{ID: "P2", Price: domain.Price{USD: 10}, Stock: &soldOut},
+	}}
+	m := NewAlertManager(newMockAlertRepository(), ag, &fixedFX{rate: 100}, 0, nil)
+
+	for _, bad := range []*domain.Alert{
+		{UserID: "U1", ProductID: "P1"},
+		{UserID: "U1", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertPercentDrop, DropPercent: 150}},
+		{UserID: "U1", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertBackInStock}},
+		{UserID: "U1", ProductID: "NOPE", Condition: domain.AlertCondition{Kind: domain.AlertAnyDrop}},
+		{UserID: "U1", ProductID: "P1", Currency: "EUR", TargetPrice: 10},
+	} {
+		if err := m.CreateAlert(ctx, bad); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%+v: expected invalid input, got %v", bad.Condition, err)
+		}
+	}
+
+	a := &domain.Alert{ID: "drop", UserID: "U1", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertPercentDrop, DropPercent: 10}}
+	if err := m.CreateAlert(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Baseline == nil || a.Baseline.Price.ETB != 1000 || a.Baseline.FXRate != 100 || a.Baseline.DeliveryDays != 20 ||
+		!a.IsActive || a.TargetPrice != 0 || a.Currency != domain.CurrencyETB {
+		t.Fatalf("baseline not recorded: %+v %+v", a, a.Baseline)
+	}
+	if a.Threshold() != 900 {
+		t.Fatalf("threshold = %v, want 900", a.Threshold())
+	}
+
+	restock := &domain.Alert{ID: "restock", UserID: "U1", ProductID: "P2", Condition: domain.AlertCondition{Kind: domain.AlertBackInStock}}
+	if err := m.CreateAlert(ctx, restock); err != nil || restock.Baseline.InStock {
+		t.Fatalf("back in stock: %v %+v", err, restock.Baseline)
+	}
+
+	legacy := &domain.Alert{ID: "legacy", UserID: "U2", ProductID: "P1", TargetPrice: 800}
+	if err := m.CreateAlert(ctx, legacy); err != nil || legacy.Condition.Kind != domain.AlertTargetPrice || legacy.Condition.TargetPrice != 800 {
+		t.Fatalf("legacy: %v %+v", err, legacy.Condition)
+	}
+
+	usd := &domain.Alert{ID: "usd", UserID: "U3", ProductID: "P1", Currency: " usd", TargetPrice: 9}
+	if err := m.CreateAlert(ctx, usd); err != nil || usd.Currency != domain.CurrencyUSD {
+		t.Fatalf("usd: %v %q", err, usd.Currency)
+	}
+
+	noFX := NewAlertManager(newMockAlertRepository(), ag, nil, 0, nil)
+	if err := noFX.CreateAlert(ctx, &domain.Alert{UserID: "U1", ProductID: "NOPE", TargetPrice: 10}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("unknown product without an FX rate: got %v, want invalid input", err)
+	}
+	if err := noFX.CreateAlert(ctx, &domain.Alert{UserID: "U1", ProductID: "P1", TargetPrice: 10}); err != nil {
+		t.Fatalf("known product without an FX rate: %v", err)
+	}
+
+	noSource := NewAlertManager(newMockAlertRepository(), nil, nil, 0, nil)
+	if err := noSource.CreateAlert(ctx, &domain.Alert{UserID: "U1", ProductID: "P1", Condition: domain.AlertCondition{Kind: domain.AlertAnyDrop}}); err == nil {
+		t.Fatal("any_drop alert created without a baseline")
+	}
+}
+
+func TestAlertManager_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAlertRepository()
+	m := NewAlertManager(repo, nil, nil, 2, nil)
+
+	past := time.Now().Add(-time.Hour)
+	for _, bad := range []*domain.Alert{
+		{ID: "a0", UserID: "U1", ProductID: "P1", TargetPrice: 100, ExpiresAt: &past},
+		{ID: "a0", UserID: "U1", ProductID: "P1", TargetPrice: 100, CooldownMinutes: -5},
+	} {
+		if err := m.CreateAlert(ctx, bad); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("expected invalid input, got %v", err)
+		}
+	}
+
+	if err := m.CreateAlert(ctx, &domain.Alert{ID: "a1", UserID: "U1", ProductID: "P1", TargetPrice: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateAlert(ctx, &domain.Alert{ID: "a2", UserID: "U1", ProductID: "P1", TargetPrice: 90}); !errors.Is(err, domain.ErrAlertExists) {
+		t.Fatalf("duplicate: expected ErrAlertExists, got %v", err)
+	}
+	if err := m.CreateAlert(ctx, &domain.Alert{ID: "a2", UserID: "U1", ProductID: "P2", TargetPrice: 90}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateAlert(ctx, &domain.Alert{ID: "a3", UserID: "U1", ProductID: "P3", TargetPrice: 90}); !errors.Is(err, domain.ErrAlertLimit) {
+		t.Fatalf("limit: expected ErrAlertLimit, got %v", err)
+	}
+	// Another user is not affected, and inactive alerts do not count
+	if err := m.CreateAlert(ctx, &domain.Alert{ID: "b1", UserID: "U2", ProductID: "P1", TargetPrice: 90}); err != nil {
+		t.Fatal(err)
+	}
+	a1, _ := repo.GetAlert("a1")
+	a1.IsActive = false
+	if err := m.CreateAlert(ctx, &domain.Alert{ID: "a4", UserID: "U1", ProductID: "P1", TargetPrice: 80}); err != nil {
+		t.Fatalf("replacing an inactive alert: %v", err)
+	}
+}
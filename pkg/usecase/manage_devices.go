@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// maxTokenLength bounds accepted FCM tokens; real tokens are ~160 characters.
+const maxTokenLength = 4096
+
+// DeviceManager registers the devices that receive a user's push notifications.
+type DeviceManager struct {
+	repo       DeviceRepository
+	staleAfter time.Duration
+}
+
+// NewDeviceManager creates a DeviceManager that prunes devices not seen for staleAfter.
+func NewDeviceManager(repo DeviceRepository, staleAfter time.Duration) *DeviceManager {
+	if staleAfter <= 0 {
+		staleAfter = 60 * 24 * time.Hour
+	}
+	return &DeviceManager{repo: repo, staleAfter: staleAfter}
+}
+
+// Register stores the device for the user, or refreshes its details and
+// last-seen time when the token is already known.
+func (m *DeviceManager) Register(ctx context.Context, userID string, d *domain.Device) (*domain.Device, error) {
+	if err := normalizeDevice(userID, d); err != nil {
+		return nil, err
+	}
+	return m.store(ctx, d)
+}
+
+func (m *DeviceManager) store(ctx context.Context, d *domain.Device) (*domain.Device, error) {
+	now := time.Now().UTC()
+	d.CreatedAt, d.LastSeenAt = now, now
+	if err := m.repo.UpsertDevice(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Refresh replaces a rotated FCM token with its successor, keeping the device
+// details. The successor is stored before the old token is deleted, so a
+// failed refresh never leaves the user without the device.
+func (m *DeviceManager) Refresh(ctx context.Context, userID, oldToken string, d *domain.Device) (*domain.Device, error) {
+	if err := normalizeDevice(userID, d); err != nil {
+		return nil, err
+	}
+	devices, err := m.repo.ListDevices(ctx, d.UserID)
+	if err != nil {
+		return nil, err
+	}
+	owned := false
+	for _, dev := range devices {
+		owned = owned || dev.Token == oldToken
+	}
+	if !owned {
+		return nil, domain.ErrDeviceNotFound
+	}
+
+	stored, err := m.store(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if oldToken != stored.Token {
+		// A token deleted meanwhile needs no cleanup
+		if err := m.repo.DeleteDevice(ctx, d.UserID, oldToken); err != nil && !errors.Is(err, domain.ErrDeviceNotFound) {
+			return nil, err
+		}
+	}
+	return stored, nil
+}
+
+// Unregister removes the user's device token.
+func (m *DeviceManager) Unregister(ctx context.Context, userID, token string) error {
+	return m.repo.DeleteDevice(ctx, userID, token)
+}
+
+// List returns the user's registered devices.
+func (m *DeviceManager) List(ctx context.Context, userID string) ([]*domain.Device, error) {
+	return m.repo.ListDevices(ctx, userID)
+}
+
+// PruneStale removes devices that have not checked in within the stale window.
+func (m *DeviceManager) PruneStale(ctx context.Context) (int64, error) {
+	return m.repo.DeleteStale(ctx, time.Now().UTC().Add(-m.staleAfter))
+}
+
+// normalizeDevice assigns the device to the user, tidies its fields and
+// validates it.
+func normalizeDevice(userID string, d *domain.Device) error {
+	d.UserID = strings.TrimSpace(userID)
+	d.Token = strings.TrimSpace(d.Token)
+	d.Platform = domain.DevicePlatform(strings.ToLower(string(d.Platform)))
+	return validateDevice(d)
+}
+
+func validateDevice(d *domain.Device) error {
+	switch {
+	case d.UserID == "":
+		return fmt.Errorf("%w: user is required", domain.ErrInvalidInput)
+	case d.Token == "" || len(d.Token) > maxTokenLength:
+		return fmt.Errorf("%w: token is required", domain.ErrInvalidInput)
+	case !d.Platform.Valid():
+		return fmt.Errorf("%w: platform must be android, ios or web", domain.ErrInvalidInput)
+	}
+	return nil
+}
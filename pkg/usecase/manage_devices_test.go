@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryDevices struct {
+	mu      sync.Mutex
+	devices map[string]*domain.Device
+}
+
+func newMemoryDevices() *memoryDevices {
+	return &memoryDevices{devices: map[string]*domain.Device{}}
+}
+
+func (m *memoryDevices) UpsertDevice(ctx context.Context, d *domain.Device) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.devices[d.Token]; ok {
+		d.CreatedAt = old.CreatedAt
+	}
+	cp := *d
+	m.devices[d.Token] = &cp
+	return nil
+}
+
+func (m *memoryDevices) DeleteDevice(ctx context.Context, userID, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.devices[token]; !ok || d.UserID != userID {
+		return domain.ErrDeviceNotFound
+	}
+	delete(m.devices, token)
+	return nil
+}
+
+func (m *memoryDevices) ListDevices(ctx context.Context, userID string) ([]*domain.Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []*domain.Device{}
+	for _, d := range m.devices {
+		if d.UserID == userID {
+			cp := *d
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Token < out[j].Token })
+	return out, nil
+}
+
+func (m *memoryDevices) DeleteTokens(ctx context.Context, tokens []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range tokens {
+		delete(m.devices, t)
+	}
+	return nil
+}
+
+func (m *memoryDevices) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for t, d := range m.devices {
+		if d.LastSeenAt.Before(before) {
+			delete(m.devices, t)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestDeviceManager(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryDevices()
+	m := NewDeviceManager(repo, 30*24*time.Hour)
+
+	t.Run("ValidatesInput", func(t *testing.T) {
+		for _, d := range []*domain.Device{
+			{Token: "", Platform: domain.PlatformAndroid},
+			{Token: "tok", Platform: "symbian"},
+		} {
+			if _, err := m.Register(ctx, "U1", d); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("expected invalid input, got %v", err)
+			}
+		}
+		if _, err := m.Register(ctx, " ", &domain.Device{Token: "tok", Platform: domain.PlatformIOS}); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("expected invalid input for missing user, got %v", err)
+		}
+	})
+
+	t.Run("RegisterRefreshUnregister", func(t *testing.T) {
+		d, err := m.Register(ctx, "U1", &domain.Device{Token: " tok-1 ", Platform: "Android", AppVersion: "1.0.0", Locale: "am-ET"})
+		if err != nil || d.Token != "tok-1" || d.Platform != domain.PlatformAndroid {
+			t.Fatalf("register: %+v %v", d, err)
+		}
+
+		if _, err := m.Refresh(ctx, "U2", "tok-1", &domain.Device{Token: "tok-2", Platform: domain.PlatformAndroid}); !errors.Is(err, domain.ErrDeviceNotFound) {
+			t.Fatalf("refresh of another user's token: %v", err)
+		}
+		// An invalid successor leaves the old token registered
+		for _, bad := range []*domain.Device{{Token: " ", Platform: domain.PlatformAndroid}, {Token: "tok-2", Platform: "symbian"}} {
+			if _, err := m.Refresh(ctx, "U1", "tok-1", bad); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("refresh with %+v: %v", bad, err)
+			}
+		}
+		if list, _ := m.List(ctx, "U1"); len(list) != 1 || list[0].Token != "tok-1" {
+			t.Fatalf("after invalid refresh: %+v", list)
+		}
+		if _, err := m.Refresh(ctx, "U1", "tok-1", &domain.Device{Token: "tok-2", Platform: domain.PlatformAndroid, AppVersion: "1.1.0"}); err != nil {
+			t.Fatal(err)
+		}
+		list, _ := m.List(ctx, "U1")
+		if len(list) != 1 || list[0].Token != "tok-2" || list[0].AppVersion != "1.1.0" {
+			t.Fatalf("after refresh: %+v", list)
+		}
+
+		if err := m.Unregister(ctx, "U1", "tok-2"); err != nil {
+			t.Fatal(err)
+		}
+		if list, _ := m.List(ctx, "U1"); len(list) != 0 {
+			t.Fatalf("after unregister: %+v", list)
+		}
+	})
+
+	t.Run("PrunesStale", func(t *testing.T) {
+		_, _ = m.Register(ctx, "U1", &domain.Device{Token: "fresh", Platform: domain.PlatformWeb})
+		_ = repo.UpsertDevice(ctx, &domain.Device{Token: "old", UserID: "U1", Platform: domain.PlatformWeb, LastSeenAt: time.Now().AddDate(0, -2, 0)})
+
+		pruned, err := m.PruneStale(ctx)
+		if err != nil || pruned != 1 {
+			t.Fatalf("pruned=%d err=%v", pruned, err)
+		}
+		if list, _ := m.List(ctx, "U1"); len(list) != 1 || list[0].Token != "fresh" {
+			t.Fatalf("after prune: %+v", list)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// PreferencesManager reads and updates users' notification preferences.
+type PreferencesManager struct {
+	repo PreferencesRepository
+}
+
+// NewPreferencesManager creates a new PreferencesManager.
+func NewPreferencesManager(repo PreferencesRepository) *PreferencesManager {
+	return &PreferencesManager{repo: repo}
+}
+
+// Get returns the user's preferences, or the defaults if none were saved.
+func (m *PreferencesManager) Get(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	return loadPreferences(ctx, m.repo, userID)
+}
+
+// Update validates and stores the user's preferences.
+func (m *PreferencesManager) Update(ctx context.Context, userID string, p *domain.NotificationPreferences) (*domain.NotificationPreferences, error) {
+	p.UserID = userID
+	if p.Channels == nil {
+		p.Channels = []domain.ChannelKind{}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = time.Now().UTC()
+	if err := m.repo.SavePreferences(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func loadPreferences(ctx context.Context, repo PreferencesRepository, userID string) (*domain.NotificationPreferences, error) {
+	if repo == nil {
+		return domain.DefaultPreferences(userID), nil
+	}
+	p, err := repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return domain.DefaultPreferences(userID), nil
+	}
+	return p, nil
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryPreferences struct {
+	mu       sync.Mutex
+	prefs    map[string]*domain.NotificationPreferences
+	fallback *domain.NotificationPreferences // returned for unknown users when set
+}
+
+func (m *memoryPreferences) GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.prefs[userID]; ok {
+		cp := *p
+		return &cp, nil
+	}
+	if m.fallback != nil {
+		cp := *m.fallback
+		cp.UserID = userID
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryPreferences) SavePreferences(ctx context.Context, p *domain.NotificationPreferences) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.prefs == nil {
+		m.prefs = map[string]*domain.NotificationPreferences{}
+	}
+	cp := *p
+	m.prefs[p.UserID] = &cp
+	return nil
+}
+
+// anytimePreferences lets every user receive everything at any time, so
+// dispatcher tests do not depend on the time of day.
+func anytimePreferences() *memoryPreferences {
+	p := domain.DefaultPreferences("")
+	p.QuietHours.Enabled = false
+	p.MaxPerDay = 0
+	return &memoryPreferences{fallback: p}
+}
+
+func TestPreferencesManager(t *testing.T) {
+	ctx := context.Background()
+	m := NewPreferencesManager(&memoryPreferences{})
+
+	p, err := m.Get(ctx, "U1")
+	if err != nil || p.TimeZone != domain.DefaultTimeZone || !p.QuietHours.Enabled || p.Mode != domain.DeliveryInstant {
+		t.Fatalf("defaults: %+v %v", p, err)
+	}
+
+	for _, bad := range []*domain.NotificationPreferences{
+		{TimeZone: "Mars/Olympus"},
+		{Mode: "hourly"},
+		{QuietHours: domain.QuietHours{Enabled: true, Start: "25:00", End: "07:00"}},
+		{MaxPerDay: -1},
+		{Channels: []domain.ChannelKind{"fax"}},
+		{Email: "not-an-address"},
+		{Locale: "fr"},
+	} {
+		if _, err := m.Update(ctx, "U1", bad); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Fatalf("%+v: expected invalid input, got %v", bad, err)
+		}
+	}
+
+	saved, err := m.Update(ctx, "U1", &domain.NotificationPreferences{
+		Channels: []domain.ChannelKind{domain.ChannelTelegram},
+		Mode:     domain.DeliveryDigest,
+		Email:    "Abebe <abebe@example.et>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.TimeZone != domain.DefaultTimeZone || saved.DigestTime != "18:00" || saved.UpdatedAt.IsZero() ||
+		saved.Email != "abebe@example.et" || saved.Locale != domain.DefaultLocale {
+		t.Fatalf("defaults not filled: %+v", saved)
+	}
+	if got, _ := m.Get(ctx, "U1"); !got.ChannelEnabled(domain.ChannelTelegram) || got.ChannelEnabled(domain.ChannelPush) {
+		t.Fatalf("stored channels: %+v", got.Channels)
+	}
+}
+
+// clockAround returns an HH:MM offset from now in UTC.
+func clockAround(d time.Duration) string {
+	return time.Now().UTC().Add(d).Format("15:04")
+}
+
+func TestNotificationDispatcher_RespectsPreferences(t *testing.T) {
+	ctx := context.Background()
+	base := func() *domain.NotificationPreferences {
+		p := domain.DefaultPreferences("U1")
+		p.TimeZone = "UTC"
+		p.QuietHours.Enabled = false
+		p.MaxPerDay = 0
+		return p
+	}
+	setup := func(p *domain.NotificationPreferences) (*memoryOutbox, *stubChannel, *NotificationDispatcher) {
+		outbox := newMemoryOutbox()
+		_, _ = NewNotificationService(outbox, domain.ChannelPush, domain.ChannelSMS).
+			Notify(ctx, &domain.Notification{IdempotencyKey: "k", UserID: "U1"})
+		push := &stubChannel{kind: domain.ChannelPush}
+		repo := &memoryPreferences{}
+		_ = repo.SavePreferences(ctx, p)
+		return outbox, push, NewNotificationDispatcher(outbox, repo, []NotificationChannel{push, &stubChannel{kind: domain.ChannelSMS}}, 3, time.Minute)
+	}
+
+	t.Run("QuietHoursDefer", func(t *testing.T) {
+		p := base()
+		p.QuietHours = domain.QuietHours{Enabled: true, Start: clockAround(-time.Hour), End: clockAround(time.Hour)}
+		outbox, push, d := setup(p)
+		if sent, _ := d.DispatchDue(ctx); sent != 0 || push.calls != 0 {
+			t.Fatalf("sent during quiet hours: %d", sent)
+		}
+		del := deliveryOf(outbox.only(t), domain.ChannelPush)
+		if del.State != domain.DeliveryPending || del.Attempts != 0 || time.Until(del.NextAttemptAt) < 30*time.Minute {
+			t.Fatalf("not deferred to quiet end: %+v", del)
+		}
+	})
+
+	t.Run("DisabledChannelSkipped", func(t *testing.T) {
+		p := base()
+		p.Channels = []domain.ChannelKind{domain.ChannelPush}
+		outbox, push, d := setup(p)
+		if sent, _ := d.DispatchDue(ctx); sent != 1 || push.calls != 1 {
+			t.Fatalf("sent=%d", sent)
+		}
+		if del := deliveryOf(outbox.only(t), domain.ChannelSMS); del.State != domain.DeliverySkipped {
+			t.Fatalf("sms: %+v", del)
+		}
+	})
+
+	t.Run("DigestModeHoldsUntilDigestTime", func(t *testing.T) {
+		p := base()
+		p.Mode = domain.DeliveryDigest
+		p.DigestTime = clockAround(3 * time.Hour)
+		outbox, push, d := setup(p)
+		if _, _ = d.DispatchDue(ctx); push.calls != 0 {
+			t.Fatal("digest-mode notification sent outside the digest window")
+		}
+		if del := deliveryOf(outbox.only(t), domain.ChannelPush); time.Until(del.NextAttemptAt) < 2*time.Hour {
+			t.Fatalf("not deferred to digest time: %+v", del)
+		}
+
+		p.DigestTime = clockAround(-10 * time.Minute)
+		outbox, push, d = setup(p)
+		if _, _ = d.DispatchDue(ctx); push.calls != 1 {
+			t.Fatal("digest-mode notification held inside the digest window")
+		}
+	})
+
+	t.Run("DailyCapDefersToNextDay", func(t *testing.T) {
+		p := base()
+		p.MaxPerDay = 1
+		outbox, push, d := setup(p)
+		_, _ = NewNotificationService(outbox, domain.ChannelPush).Notify(ctx, &domain.Notification{IdempotencyKey: "k2", UserID: "U1"})
+
+		_, _ = d.DispatchDue(ctx)
+		if push.calls != 1 {
+			t.Fatalf("push calls = %d, want 1", push.calls)
+		}
+		tomorrow := p.DayStart(time.Now()).AddDate(0, 0, 1)
+		var deferred int
+		for _, n := range outbox.items {
+			if del := deliveryOf(n, domain.ChannelPush); del.State == domain.DeliveryPending && del.NextAttemptAt.Equal(tomorrow) {
+				deferred++
+			}
+		}
+		if deferred != 1 {
+			t.Fatalf("deferred = %d, want 1", deferred)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// NotificationService writes notification intents to the outbox. Delivery
+// happens asynchronously in the NotificationDispatcher.
+type NotificationService struct {
+	outbox   NotificationOutbox
+	channels []domain.ChannelKind
+}
+
+// NewNotificationService creates a NotificationService that addresses every
+// notification to the given channels.
+func NewNotificationService(outbox NotificationOutbox, channels ...domain.ChannelKind) *NotificationService {
+	return &NotificationService{outbox: outbox, channels: channels}
+}
+
+// Notify stores the notification with a pending delivery per channel. The
+// idempotency key must be set; a second notification with the same key is
+// dropped, so it is delivered at most once per channel. It reports whether
+// the notification was new.
+func (s *NotificationService) Notify(ctx context.Context, n *domain.Notification) (bool, error) {
+	if n.IdempotencyKey == "" || n.UserID == "" {
+		return false, errors.New("notification needs a user and an idempotency key")
+	}
+	now := time.Now().UTC()
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	n.CreatedAt, n.UpdatedAt = now, now
+	n.Deliveries = make([]domain.ChannelDelivery, 0, len(s.channels))
+	for _, ch := range s.channels {
+		n.Deliveries = append(n.Deliveries, domain.ChannelDelivery{Channel: ch, State: domain.DeliveryPending, NextAttemptAt: now})
+	}
+	n.Refresh()
+	return s.outbox.Enqueue(ctx, n)
+}
+
+// NotifyAlertTriggered tells the alert's owner that its condition was met.
+// Each trigger of an alert yields at most one notification.
+func (s *NotificationService) NotifyAlertTriggered(ctx context.Context, t *domain.AlertTrigger) (bool, error) {
+	a := t.Alert
+	cond := a.EffectiveCondition()
+	cur := a.TargetCurrency()
+	price := t.Price.Amount(cur)
+	name := "A product you are watching"
+	data := map[string]string{
+		"alertId":   a.ID,
+		"alertKind": string(cond.Kind),
+		"productId": a.ProductID,
+		"currency":  cur,
+		"price":     fmt.Sprintf("%.2f", price),
+		"priceEtb":  fmt.Sprintf("%.2f", t.Price.ETB),
+		"priceUsd":  fmt.Sprintf("%.2f", t.Price.USD),
+	}
+	if t.FXRate > 0 {
+		data["fxRate"] = fmt.Sprintf("%.4f", t.FXRate)
+	}
+	if t.Cause != "" {
+		data["dropCause"] = string(t.Cause)
+	}
+	if p := t.Product; p != nil {
+		if p.Title != "" {
+			name = p.Title
+			data["productTitle"] = p.Title
+		}
+		if isWebURL(p.DeeplinkURL) {
+			data["url"] = p.DeeplinkURL
+		}
+	}
+
+	n := &domain.Notification{
+		IdempotencyKey: alertIdempotencyKey(a),
+		UserID:         a.UserID,
+		Kind:           domain.NotificationPriceAlert,
+		Title:          "Price drop alert",
+		Data:           data,
+	}
+	if threshold := a.Threshold(); threshold > 0 {
+		data["target"] = fmt.Sprintf("%.2f", threshold)
+		if cur == domain.CurrencyETB {
+			data["targetEtb"] = data["target"]
+		}
+	}
+	switch cond.Kind {
+	case domain.AlertPercentDrop:
+		n.Body = fmt.Sprintf("%s is now %.2f %s, down at least %.0f%% from %.2f %s.", name, price, cur, cond.DropPercent, a.Baseline.Price.Amount(cur), cur)
+	case domain.AlertAnyDrop:
+		n.Body = fmt.Sprintf("%s is now %.2f %s, down from %.2f %s.", name, price, cur, a.Baseline.Price.Amount(cur), cur)
+	case domain.AlertBackInStock:
+		n.Kind, n.Title = domain.NotificationBackInStock, "Back in stock"
+		n.Body = fmt.Sprintf("%s is back in stock at %.2f ETB.", name, t.Price.ETB)
+	case domain.AlertFastDelivery:
+		n.Kind, n.Title = domain.NotificationDeliveryAlert, "Faster delivery"
+		days := 0
+		if t.Product != nil {
+			days = t.Product.DeliveryDays()
+		}
+		data["deliveryDays"] = fmt.Sprintf("%d", days)
+		n.Body = fmt.Sprintf("%s now arrives within %d days (you asked for %d or less).", name, days, cond.MaxDeliveryDays)
+	default:
+		n.Body = fmt.Sprintf("%s is now %.2f %s (your target: %.2f %s).", name, price, cur, cond.TargetPrice, cur)
+	}
+	if n.Kind == domain.NotificationPriceAlert {
+		n.Body += dropCauseNote(t.Cause)
+	}
+	return s.Notify(ctx, n)
+}
+
+// dropCauseNote explains where a price drop came from.
+func dropCauseNote(c domain.DropCause) string {
+	switch c {
+	case domain.DropCauseSeller:
+		return " The seller lowered the price."
+	case domain.DropCauseFX:
+		return " The dollar price is unchanged; the drop comes from the exchange rate."
+	case domain.DropCauseSellerAndFX:
+		return " The seller lowered the price and the exchange rate moved in your favour."
+	}
+	return ""
+}
+
+// alertIdempotencyKey identifies one trigger of an alert; re-arming alerts
+// get a key per trigger after the first.
+func alertIdempotencyKey(a *domain.Alert) string {
+	if a.TriggerCount > 1 {
+		return fmt.Sprintf("alert:%s:%d", a.ID, a.TriggerCount)
+	}
+	return "alert:" + a.ID
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryOutbox struct {
+	mu    sync.Mutex
+	items map[string]*domain.Notification
+	keys  map[string]bool
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{items: map[string]*domain.Notification{}, keys: map[string]bool{}}
+}
+
+func (m *memoryOutbox) Enqueue(ctx context.Context, n *domain.Notification) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.keys[n.IdempotencyKey] {
+		return false, nil
+	}
+	m.keys[n.IdempotencyKey] = true
+	cp := *n
+	cp.Deliveries = append([]domain.ChannelDelivery(nil), n.Deliveries...)
+	m.items[n.ID] = &cp
+	return true, nil
+}
+
+func (m *memoryOutbox) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*domain.Notification
+	for _, n := range m.items {
+		if len(out) < limit && n.Status == domain.NotificationPending && !n.NextAttemptAt.After(now) {
+			cp := *n
+			cp.Deliveries = append([]domain.ChannelDelivery(nil), n.Deliveries...)
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryOutbox) Save(ctx context.Context, n *domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[n.ID] = n
+	return nil
+}
+
+func (m *memoryOutbox) CountSent(ctx context.Context, userID string, since time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, n := range m.items {
+		for _, d := range n.Deliveries {
+			if n.UserID == userID && d.SentAt != nil && !d.SentAt.Before(since) {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryOutbox) only(t *testing.T) *domain.Notification {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.items) != 1 {
+		t.Fatalf("outbox has %d notifications, want 1", len(m.items))
+	}
+	for _, n := range m.items {
+		return n
+	}
+	return nil
+}
+
+// forceDue makes every pending delivery due now, skipping the backoff wait.
+func (m *memoryOutbox) forceDue() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, n := range m.items {
+		for i := range n.Deliveries {
+			n.Deliveries[i].NextAttemptAt = time.Time{}
+		}
+		n.Refresh()
+	}
+}
+
+type stubChannel struct {
+	kind  domain.ChannelKind
+	errs  []error // returned by successive sends; nil afterwards
+	sent  []string
+	calls int
+}
+
+func (c *stubChannel) Kind() domain.ChannelKind { return c.kind }
+
+func (c *stubChannel) Send(ctx context.Context, n *domain.Notification, key string) (string, error) {
+	c.calls++
+	if c.calls <= len(c.errs) && c.errs[c.calls-1] != nil {
+		return "", c.errs[c.calls-1]
+	}
+	c.sent = append(c.sent, key)
+	return fmt.Sprintf("%s-%d", c.kind, c.calls), nil
+}
+
+func deliveryOf(n *domain.Notification, kind domain.ChannelKind) domain.ChannelDelivery {
+	for _, d := range n.Deliveries {
+		if d.Channel == kind {
+			return d
+		}
+	}
+	return domain.ChannelDelivery{}
+}
+
+func TestNotificationService_AlertIsIdempotent(t *testing.T) {
+	outbox := newMemoryOutbox()
+	svc := NewNotificationService(outbox, domain.ChannelPush, domain.ChannelEmail)
+	trigger := &domain.AlertTrigger{
+		Alert: &domain.Alert{ID: "A1", UserID: "U1", ProductID: "P1", TargetPrice: 1500},
+		Price: domain.Price{USD: 10, ETB: 1400},
+	}
+
+	created, err := svc.NotifyAlertTriggered(context.Background(), trigger)
+	if err != nil || !created {
+		t.Fatalf("first notify: created=%v err=%v", created, err)
+	}
+	created, err = svc.NotifyAlertTriggered(context.Background(), trigger)
+	if err != nil || created {
+		t.Fatalf("second notify: created=%v err=%v", created, err)
+	}
+
+	n := outbox.only(t)
+	if n.UserID != "U1" || n.Data["productId"] != "P1" || len(n.Deliveries) != 2 || n.Status != domain.NotificationPending {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+}
+
+func TestNotificationService_AlertKinds(t *testing.T) {
+	product := &domain.Product{ID: "P1", Title: "Kettle", DeeplinkURL: "https://example.com/p1", Delivery: &domain.DeliveryWindow{MaxDays: 9}}
+	baseline := &domain.AlertBaseline{Price: domain.Price{ETB: 2000}}
+	cases := []struct {
+		cond   domain.AlertCondition
+		kind   domain.NotificationKind
+		target string
+	}{
+		{domain.AlertCondition{Kind: domain.AlertPercentDrop, DropPercent: 25}, domain.NotificationPriceAlert, "1500.00"},
+		{domain.AlertCondition{Kind: domain.AlertAnyDrop}, domain.NotificationPriceAlert, "2000.00"},
+		{domain.AlertCondition{Kind: domain.AlertBackInStock}, domain.NotificationBackInStock, ""},
+		{domain.AlertCondition{Kind: domain.AlertFastDelivery, MaxDeliveryDays: 10}, domain.NotificationDeliveryAlert, ""},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.cond.Kind), func(t *testing.T) {
+			outbox := newMemoryOutbox()
+			trigger := &domain.AlertTrigger{
+				Alert:   &domain.Alert{ID: "A1", UserID: "U1", ProductID: "P1", Condition: tc.cond, Baseline: baseline},
+				Product: product,
+				Price:   domain.Price{USD: 10, ETB: 1400},
+			}
+			if _, err := NewNotificationService(outbox, domain.ChannelPush).NotifyAlertTriggered(context.Background(), trigger); err != nil {
+				t.Fatal(err)
+			}
+			n := outbox.only(t)
+			if n.Kind != tc.kind || n.Data["targetEtb"] != tc.target || n.Data["alertKind"] != string(tc.cond.Kind) {
+				t.Fatalf("unexpected notification: %+v", n)
+			}
+			if n.Data["productTitle"] != "Kettle" || n.Data["url"] != product.DeeplinkURL || !strings.Contains(n.Body, "Kettle") {
+				t.Fatalf("product details missing: %+v", n)
+			}
+		})
+	}
+}
+
+func TestNotificationService_AlertCurrencyAndCause(t *testing.T) {
+	outbox := newMemoryOutbox()
+	trigger := &domain.AlertTrigger{
+		Alert:  &domain.Alert{ID: "A1", UserID: "U1", ProductID: "P1", Currency: domain.CurrencyUSD, TargetPrice: 9.5},
+		Price:  domain.Price{USD: 9, ETB: 1080},
+		FXRate: 120,
+		Cause:  domain.DropCauseSeller,
+	}
+	if _, err := NewNotificationService(outbox, domain.ChannelPush).NotifyAlertTriggered(context.Background(), trigger); err != nil {
+		t.Fatal(err)
+	}
+	n := outbox.only(t)
+	if n.Data["currency"] != "USD" || n.Data["price"] != "9.00" || n.Data["target"] != "9.50" || n.Data["targetEtb"] != "" ||
+		n.Data["fxRate"] != "120.0000" || n.Data["dropCause"] != "seller" {
+		t.Fatalf("unexpected data: %v", n.Data)
+	}
+	if !strings.Contains(n.Body, "9.00 USD (your target: 9.50 USD). The seller lowered the price.") {
+		t.Fatalf("body: %q", n.Body)
+	}
+}
+
+func TestNotificationDispatcher_PerChannelDelivery(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox()
+	svc := NewNotificationService(outbox, domain.ChannelPush, domain.ChannelEmail, domain.ChannelTelegram, domain.ChannelSMS)
+	_, _ = svc.Notify(ctx, &domain.Notification{IdempotencyKey: "k1", UserID: "U1", Title: "t", Body: "b"})
+
+	push := &stubChannel{kind: domain.ChannelPush}
+	email := &stubChannel{kind: domain.ChannelEmail, errs: []error{errors.New("smtp timeout")}}
+	telegram := &stubChannel{kind: domain.ChannelTelegram, errs: []error{domain.ErrNoRecipient}}
+	sms := &stubChannel{kind: domain.ChannelSMS, errs: []error{fmt.Errorf("invalid number: %w", domain.ErrDeliveryRejected)}}
+	d := NewNotificationDispatcher(outbox, anytimePreferences(), []NotificationChannel{push, email, telegram, sms}, 3, time.Minute)
+
+	sent, err := d.DispatchDue(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("first pass: sent=%d err=%v", sent, err)
+	}
+	n := outbox.only(t)
+	if got := deliveryOf(n, domain.ChannelPush); got.State != domain.DeliverySent || got.ProviderID != "push-1" {
+		t.Fatalf("push: %+v", got)
+	}
+	if got := deliveryOf(n, domain.ChannelTelegram); got.State != domain.DeliverySkipped {
+		t.Fatalf("telegram: %+v", got)
+	}
+	if got := deliveryOf(n, domain.ChannelSMS); got.State != domain.DeliveryFailed || sms.calls != 1 {
+		t.Fatalf("sms: %+v", got)
+	}
+	emailDel := deliveryOf(n, domain.ChannelEmail)
+	if emailDel.State != domain.DeliveryPending || emailDel.Attempts != 1 || time.Until(emailDel.NextAttemptAt) < 50*time.Second {
+		t.Fatalf("email should back off: %+v", emailDel)
+	}
+
+	// Not due yet: nothing is resent
+	if sent, _ := d.DispatchDue(ctx); sent != 0 || push.calls != 1 || email.calls != 1 {
+		t.Fatalf("early pass resent: sent=%d push=%d email=%d", sent, push.calls, email.calls)
+	}
+
+	outbox.forceDue()
+	if sent, _ := d.DispatchDue(ctx); sent != 1 {
+		t.Fatalf("retry pass: sent=%d", sent)
+	}
+	n = outbox.only(t)
+	if n.Status != domain.NotificationDone || push.calls != 1 || len(email.sent) != 1 || email.sent[0] != "k1:email" {
+		t.Fatalf("after retry: status=%s push=%d email=%v", n.Status, push.calls, email.sent)
+	}
+}
+
+func TestNotificationDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox()
+	_, _ = NewNotificationService(outbox, domain.ChannelEmail).Notify(ctx, &domain.Notification{IdempotencyKey: "k", UserID: "U"})
+	fail := errors.New("down")
+	email := &stubChannel{kind: domain.ChannelEmail, errs: []error{fail, fail, fail, fail}}
+	d := NewNotificationDispatcher(outbox, anytimePreferences(), []NotificationChannel{email}, 2, time.Second)
+
+	_, _ = d.DispatchDue(ctx)
+	outbox.forceDue()
+	_, _ = d.DispatchDue(ctx)
+	outbox.forceDue()
+	_, _ = d.DispatchDue(ctx)
+
+	n := outbox.only(t)
+	if got := deliveryOf(n, domain.ChannelEmail); got.State != domain.DeliveryFailed || got.Attempts != 2 || email.calls != 2 {
+		t.Fatalf("unexpected delivery: %+v calls=%d", got, email.calls)
+	}
+	if n.Status != domain.NotificationDone {
+		t.Fatalf("status = %s", n.Status)
+	}
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// minPredictionDays is the price history needed before predicting.
+	minPredictionDays = 14
+	// minCyclePeriod and maxCyclePeriod bound the price cycles searched for.
+	minCyclePeriod = 7
+	maxCyclePeriod = 45
+	// minCycleCorrelation is the autocorrelation a cycle needs to be used.
+	minCycleCorrelation = 0.3
+	// fxTrendWindow is how much FX history the FX trend is fitted on.
+	fxTrendWindow = 30 * 24 * time.Hour
+	// dropMargin is the relative drop that counts as "the price dropped".
+	dropMargin = 0.02
+	// waitProbability and waitMinSaving gate a "wait" recommendation.
+	waitProbability = 0.6
+	waitMinSaving   = 0.03
+)
+
+// PricePredictor estimates whether a product's ETB price will drop within a
+// horizon. The model is deliberately simple and explainable: the USD price is
+// a linear trend plus an optional repeating cycle (seasonal baseline), the
+// USD->ETB rate is a linear trend, and the ETB forecast is their product.
+type PricePredictor struct {
+	history   PriceHistoryRepository
+	fxHistory FXHistoryRepository
+	fx        IFXClient
+	lookback  time.Duration
+}
+
+// NewPricePredictor creates a new PricePredictor fitted on the given lookback.
+func NewPricePredictor(history PriceHistoryRepository, fxHistory FXHistoryRepository, fx IFXClient, lookback time.Duration) *PricePredictor {
+	if lookback <= 0 {
+		lookback = 120 * 24 * time.Hour
+	}
+	return &PricePredictor{history: history, fxHistory: fxHistory, fx: fx, lookback: lookback}
+}
+
+// Predict returns a buy-now-or-wait recommendation for the product, or nil
+// when there is not enough price history.
+func (p *PricePredictor) Predict(ctx context.Context, product *domain.Product, horizonDays int) (*domain.PricePrediction, error) {
+	if horizonDays <= 0 {
+		horizonDays = 14
+	}
+	end := time.Now().UTC()
+	start := end.Add(-p.lookback)
+
+	snapshots, err := p.history.ListSnapshots(ctx, product.ID, start)
+	if err != nil {
+		return nil, err
+	}
+	series := dailySeries(snapshots, start, end)
+	if len(series) < minPredictionDays || product.Price.USD <= 0 {
+		return nil, nil
+	}
+
+	// Fit USD trend and cycle on day offsets so gaps in the history are respected
+	origin := series[0].Day
+	xs := make([]float64, len(series))
+	ys := make([]float64, len(series))
+	for i, s := range series {
+		xs[i] = s.Day.Sub(origin).Hours() / 24
+		ys[i] = s.USD
+	}
+	intercept, slope := linearFit(xs, ys)
+	residuals := make([]float64, len(ys))
+	for i := range ys {
+		residuals[i] = ys[i] - (intercept + slope*xs[i])
+	}
+	period, seasonal := detectCycle(xs, residuals)
+	sigma := residualSpread(xs, residuals, period, seasonal)
+
+	rate, fxSlope, err := p.fxTrend(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+
+	today := int(math.Floor(end.Sub(origin).Hours() / 24))
+	current := product.Price.USD
+	currentETB := current * rate
+
+	pred := &domain.PricePrediction{
+		HorizonDays: horizonDays,
+		CurrentETB:  roundCents(currentETB),
+		Model: domain.PredictionModel{
+			SampleDays:      len(series),
+			TrendUSDPerDay:  math.Round(slope*1e4) / 1e4,
+			CyclePeriodDays: period,
+			ResidualUSD:     roundCents(sigma),
+			FXRate:          rate,
+			FXTrendPerDay:   math.Round(fxSlope*1e4) / 1e4,
+		},
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	bestRate := rate
+	for h := 1; h <= horizonDays; h++ {
+		usd := current + slope*float64(h) + seasonalAt(seasonal, period, today+h) - seasonalAt(seasonal, period, today)
+		usd = math.Max(usd, 0.01)
+		fxh := rate + fxSlope*float64(h)
+		etb := usd * fxh
+		if etb < low {
+			low, pred.BestDay, bestRate = etb, h, fxh
+		}
+		high = math.Max(high, etb)
+	}
+
+	// Probability that the best day's price lands below the drop threshold
+	threshold := currentETB * (1 - dropMargin)
+	spread := sigma * bestRate
+	if spread > 0 {
+		pred.DropProbability = normalCDF((threshold - low) / spread)
+	} else if low < threshold {
+		pred.DropProbability = 1
+	}
+	pred.DropProbability = math.Round(pred.DropProbability*100) / 100
+	pred.ExpectedLowETB = roundCents(math.Max(0, low-spread))
+	pred.ExpectedHighETB = roundCents(high + sigma*rate)
+
+	saving := (currentETB - low) / currentETB
+	pred.Recommendation = domain.RecommendBuyNow
+	if pred.DropProbability >= waitProbability && saving >= waitMinSaving {
+		pred.Recommendation = domain.RecommendWait
+	}
+	pred.Rationale = predictionRationale(pred, saving)
+	return pred, nil
+}
+
+// fxTrend returns the current USD->ETB rate and its daily trend from FX history.
+func (p *PricePredictor) fxTrend(ctx context.Context, end time.Time) (float64, float64, error) {
+	rates, err := p.fxHistory.ListRates(ctx, "USD", "ETB", end.Add(-fxTrendWindow))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var rate float64
+	if p.fx != nil {
+		if r, ferr := p.fx.GetRate(ctx, "USD", "ETB"); ferr == nil {
+			rate = r
+		}
+	}
+	if rate <= 0 && len(rates) > 0 {
+		rate = rates[len(rates)-1].Rate
+	}
+	if rate <= 0 {
+		return 0, 0, fmt.Errorf("no USD->ETB rate available")
+	}
+	if len(rates) < 2 {
+		return rate, 0, nil
+	}
+
+	xs := make([]float64, len(rates))
+	ys := make([]float64, len(rates))
+	for i, r := range rates {
+		xs[i] = r.RecordedAt.Sub(rates[0].RecordedAt).Hours() / 24
+		ys[i] = r.Rate
+	}
+	_, slope := linearFit(xs, ys)
+	return rate, slope, nil
+}
+
+func predictionRationale(pred *domain.PricePrediction, saving float64) []string {
+	m := pred.Model
+	out := []string{fmt.Sprintf("USD price trend is %+.2f USD/day over %d days of history", m.TrendUSDPerDay, m.SampleDays)}
+	if m.CyclePeriodDays > 0 {
+		out = append(out, fmt.Sprintf("price follows a %d-day cycle; the cheapest expected day is in %d days", m.CyclePeriodDays, pred.BestDay))
+	} else {
+		out = append(out, "no repeating price cycle detected")
+	}
+	out = append(out, fmt.Sprintf("USD->ETB rate is %.2f and moving %+.4f birr/day", m.FXRate, m.FXTrendPerDay))
+	out = append(out, fmt.Sprintf("%.0f%% chance the ETB price drops at least %.0f%% within %d days (expected saving %.1f%%)",
+		pred.DropProbability*100, dropMargin*100, pred.HorizonDays, math.Max(0, saving)*100))
+	return out
+}
+
+// linearFit returns the least-squares intercept and slope of ys over xs.
+func linearFit(xs, ys []float64) (float64, float64) {
+	n := float64(len(xs))
+	if n == 0 {
+		return 0, 0
+	}
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return sy / n, 0
+	}
+	slope := (n*sxy - sx*sy) / den
+	return (sy - slope*sx) / n, slope
+}
+
+// detectCycle finds the period with the strongest autocorrelation in the
+// detrended residuals and returns it with the mean residual per phase.
+// It returns period 0 when no cycle is strong enough.
+func detectCycle(xs, residuals []float64) (int, []float64) {
+	span := int(math.Round(xs[len(xs)-1])) + 1
+	byDay := make([]float64, span)
+	present := make([]bool, span)
+	for i, x := range xs {
+		d := int(math.Round(x))
+		byDay[d], present[d] = residuals[i], true
+	}
+
+	var variance float64
+	for _, r := range residuals {
+		variance += r * r
+	}
+	variance /= float64(len(residuals))
+	if variance == 0 {
+		return 0, nil
+	}
+
+	best, bestCorr := 0, minCycleCorrelation
+	for lag := minCyclePeriod; lag <= maxCyclePeriod && lag <= span/2; lag++ {
+		var sum float64
+		var pairs int
+		for d := 0; d+lag < span; d++ {
+			if present[d] && present[d+lag] {
+				sum += byDay[d] * byDay[d+lag]
+				pairs++
+			}
+		}
+		if pairs < lag {
+			continue
+		}
+		if corr := sum / float64(pairs) / variance; corr > bestCorr {
+			best, bestCorr = lag, corr
+		}
+	}
+	if best == 0 {
+		return 0, nil
+	}
+
+	seasonal := make([]float64, best)
+	counts := make([]int, best)
+	for d := range byDay {
+		if present[d] {
+			seasonal[d%best] += byDay[d]
+			counts[d%best]++
+		}
+	}
+	for i := range seasonal {
+		if counts[i] > 0 {
+			seasonal[i] /= float64(counts[i])
+		}
+	}
+	return best, seasonal
+}
+
+func seasonalAt(seasonal []float64, period, day int) float64 {
+	if period == 0 {
+		return 0
+	}
+	return seasonal[day%period]
+}
+
+// residualSpread is the standard deviation left after trend and cycle.
+func residualSpread(xs, residuals []float64, period int, seasonal []float64) float64 {
+	var ss float64
+	for i, r := range residuals {
+		e := r - seasonalAt(seasonal, period, int(math.Round(xs[i])))
+		ss += e * e
+	}
+	return math.Sqrt(ss / float64(len(residuals)))
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
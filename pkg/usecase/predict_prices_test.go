@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryFXHistory struct {
+	rates []*domain.FXRateSnapshot
+}
+
+func (m *memoryFXHistory) RecordRate(ctx context.Context, snapshot *domain.FXRateSnapshot) error {
+	m.rates = append(m.rates, snapshot)
+	return nil
+}
+
+func (m *memoryFXHistory) ListRates(ctx context.Context, from, to string, since time.Time) ([]*domain.FXRateSnapshot, error) {
+	var out []*domain.FXRateSnapshot
+	for _, r := range m.rates {
+		if r.From == from && r.To == to && !r.RecordedAt.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// dailyHistory builds one snapshot per day ending today, priced by priceOn(dayIndex).
+func dailyHistory(productID string, days int, priceOn func(i int) float64) *memoryPriceHistory {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	h := &memoryPriceHistory{}
+	for i := 0; i < days; i++ {
+		day := today.AddDate(0, 0, i-days+1)
+		h.snapshots = append(h.snapshots, &domain.PriceSnapshot{
+			ID:         productID + day.Format(time.DateOnly),
+			ProductID:  productID,
+			Price:      domain.Price{USD: priceOn(i)},
+			RecordedAt: day,
+			LastSeenAt: day.Add(23 * time.Hour),
+		})
+	}
+	return h
+}
+
+func TestPricePredictor(t *testing.T) {
+	ctx := context.Background()
+	fxHistory := &memoryFXHistory{}
+	product := &domain.Product{ID: "p", Price: domain.Price{USD: 100}}
+
+	t.Run("CycleDipAheadRecommendsWait", func(t *testing.T) {
+		// 10-day cycle with a two-day sale; today is the last day before the next sale
+		history := dailyHistory("p", 90, func(i int) float64 {
+			if i%10 < 2 {
+				return 80
+			}
+			return 100
+		})
+		predictor := NewPricePredictor(history, fxHistory, &fixedFX{rate: 150}, 0)
+
+		pred, err := predictor.Predict(ctx, product, 7)
+		if err != nil {
+			t.Fatalf("Predict failed: %v", err)
+		}
+		if pred == nil {
+			t.Fatal("expected a prediction")
+		}
+		if pred.Model.CyclePeriodDays != 10 {
+			t.Errorf("expected a 10-day cycle, got %d", pred.Model.CyclePeriodDays)
+		}
+		if pred.Recommendation != domain.RecommendWait {
+			t.Errorf("expected wait, got %s (%v)", pred.Recommendation, pred.Rationale)
+		}
+		if pred.BestDay != 1 {
+			t.Errorf("expected the dip tomorrow, got day %d", pred.BestDay)
+		}
+		if pred.CurrentETB != 15000 || pred.ExpectedLowETB >= pred.CurrentETB || pred.ExpectedHighETB < pred.ExpectedLowETB {
+			t.Errorf("unexpected range: current=%v low=%v high=%v", pred.CurrentETB, pred.ExpectedLowETB, pred.ExpectedHighETB)
+		}
+		if len(pred.Rationale) == 0 {
+			t.Error("expected a rationale")
+		}
+	})
+
+	t.Run("FlatPriceRecommendsBuyNow", func(t *testing.T) {
+		history := dailyHistory("p", 60, func(int) float64 { return 100 })
+		predictor := NewPricePredictor(history, fxHistory, &fixedFX{rate: 150}, 0)
+
+		pred, err := predictor.Predict(ctx, product, 14)
+		if err != nil {
+			t.Fatalf("Predict failed: %v", err)
+		}
+		if pred.Recommendation != domain.RecommendBuyNow || pred.DropProbability != 0 {
+			t.Errorf("expected buy now with no drop chance, got %s / %v", pred.Recommendation, pred.DropProbability)
+		}
+		if pred.Model.CyclePeriodDays != 0 {
+			t.Errorf("expected no cycle, got %d", pred.Model.CyclePeriodDays)
+		}
+	})
+
+	t.Run("FXTrendMovesETBForecast", func(t *testing.T) {
+		now := time.Now().UTC()
+		rising := &memoryFXHistory{}
+		for d := 20; d >= 0; d-- {
+			rising.rates = append(rising.rates, &domain.FXRateSnapshot{
+				From: "USD", To: "ETB", Rate: 150 - float64(d), RecordedAt: now.AddDate(0, 0, -d),
+			})
+		}
+		history := dailyHistory("p", 60, func(int) float64 { return 100 })
+		predictor := NewPricePredictor(history, rising, nil, 0)
+
+		pred, err := predictor.Predict(ctx, product, 14)
+		if err != nil {
+			t.Fatalf("Predict failed: %v", err)
+		}
+		if pred.Model.FXTrendPerDay <= 0 {
+			t.Errorf("expected a rising FX trend, got %v", pred.Model.FXTrendPerDay)
+		}
+		if pred.ExpectedHighETB <= pred.CurrentETB || pred.Recommendation != domain.RecommendBuyNow {
+			t.Errorf("rising FX should raise the ETB forecast: %+v", pred)
+		}
+	})
+
+	t.Run("NotEnoughHistory", func(t *testing.T) {
+		history := dailyHistory("p", 5, func(int) float64 { return 100 })
+		predictor := NewPricePredictor(history, fxHistory, &fixedFX{rate: 150}, 0)
+
+		pred, err := predictor.Predict(ctx, product, 14)
+		if err != nil {
+			t.Fatalf("Predict failed: %v", err)
+		}
+		if pred != nil {
+			t.Errorf("expected no prediction, got %+v", pred)
+		}
+	})
+}
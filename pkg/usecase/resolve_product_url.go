@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// ResolvedProduct is the product behind a pasted link.
+type ResolvedProduct struct {
+	domain.ProductRef
+	Product *domain.Product `json:"product"`
+	// LandedCost prices the product delivered to Ethiopia, customs included.
+	// It is omitted when it cannot be estimated.
+	LandedCost *domain.CartEstimate `json:"landedCost,omitempty"`
+}
+
+// ProductURLResolver turns AliExpress and Alibaba links users paste into
+// products.
+type ProductURLResolver struct {
+	expander URLExpander
+	products *GetProductUseCase
+	landed   *CartEstimator
+}
+
+// NewProductURLResolver creates a new ProductURLResolver. expander follows
+// short links and landed estimates the landed cost; either may be nil to
+// skip that step.
+func NewProductURLResolver(expander URLExpander, products *GetProductUseCase, landed *CartEstimator) *ProductURLResolver {
+	return &ProductURLResolver{expander: expander, products: products, landed: landed}
+}
+
+// Resolve extracts the product ID from the link, expanding short links
+// first, and returns the enriched product with its landed cost. It returns
+// domain.ErrInvalidInput for links that are not product links and
+// domain.ErrProductNotFound for products the gateway does not know.
+func (r *ProductURLResolver) Resolve(ctx context.Context, rawURL string) (*ResolvedProduct, error) {
+	ref, ok := domain.ParseProductURL(rawURL)
+	if !ok && r.expander != nil && domain.IsShortProductLink(rawURL) {
+		expanded, err := r.expander.Expand(ctx, rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("expand link: %w", err)
+		}
+		ref, ok = domain.ParseProductURL(expanded)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: not an AliExpress or Alibaba product link", domain.ErrInvalidInput)
+	}
+
+	p, err := r.products.GetProduct(ctx, ref.ProductID, ProductOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resolved := &ResolvedProduct{ProductRef: ref, Product: p}
+	if r.landed != nil {
+		// The product is useful without its landed cost
+		if est, err := r.landed.Estimate(ctx, []domain.CartItem{{ProductID: ref.ProductID, Quantity: 1}}); err == nil {
+			resolved.LandedCost = est
+		}
+	}
+	return resolved, nil
+}
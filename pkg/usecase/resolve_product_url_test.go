@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type stubExpander struct {
+	to    string
+	err   error
+	calls int
+}
+
+func (s *stubExpander) Expand(ctx context.Context, shortURL string) (string, error) {
+	s.calls++
+	return s.to, s.err
+}
+
+func TestProductURLResolver_Resolve(t *testing.T) {
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "1005004567890123", Title: "Earbuds", Price: domain.Price{USD: 12},
+			Shipping: []domain.ShippingOption{{Method: "standard", Cost: domain.Price{USD: 3}}}},
+	}}
+	fx := &fixedFX{rate: 100}
+	products := NewGetProductUseCase(ag, nil, fx, nil, nil, nil, nil, 0)
+	expander := &stubExpander{to: "https://m.aliexpress.com/i/1005004567890123.html?sourceType=1"}
+	r := NewProductURLResolver(expander, products, NewCartEstimator(ag, fx, domain.DefaultCustomsRules()))
+	ctx := context.Background()
+
+	t.Run("direct link", func(t *testing.T) {
+		got, err := r.Resolve(ctx, "https://www.aliexpress.com/item/1005004567890123.html?spm=x")
+		if err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		if got.ProductID != "1005004567890123" || got.Source != domain.SourceAliExpress || got.Product.Title != "Earbuds" {
+			t.Errorf("unexpected product %+v", got)
+		}
+		if got.Product.Price.ETB != 1200 {
+			t.Errorf("expected ETB pricing, got %+v", got.Product.Price)
+		}
+		if got.LandedCost == nil || got.LandedCost.Total.USD != 15 {
+			t.Errorf("expected a landed cost of 15 USD, got %+v", got.LandedCost)
+		}
+		if expander.calls != 0 {
+			t.Errorf("direct links must not be expanded")
+		}
+	})
+
+	t.Run("short link", func(t *testing.T) {
+		got, err := r.Resolve(ctx, "https://a.aliexpress.com/_mK8abcd")
+		if err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		if got.ProductID != "1005004567890123" || got.CanonicalURL != "https://www.aliexpress.com/item/1005004567890123.html" {
+			t.Errorf("unexpected ref %+v", got.ProductRef)
+		}
+		if expander.calls != 1 {
+			t.Errorf("expected one expansion, got %d", expander.calls)
+		}
+	})
+
+	t.Run("invalid links", func(t *testing.T) {
+		for _, raw := range []string{"", "https://example.com/item/1005004567890123.html", "https://bit.ly/abc"} {
+			if _, err := r.Resolve(ctx, raw); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("Resolve(%q) error = %v; want ErrInvalidInput", raw, err)
+			}
+		}
+		failing := NewProductURLResolver(&stubExpander{err: errors.New("timeout")}, products, nil)
+		if _, err := failing.Resolve(ctx, "https://a.aliexpress.com/_mK8abcd"); err == nil || errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected the expansion error, got %v", err)
+		}
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "https://www.alibaba.com/product-detail/Solar_1600123456789.html")
+		if !errors.Is(err, domain.ErrProductNotFound) {
+			t.Errorf("expected ErrProductNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// savedSearchItemLimit is how many new products one saved-search
+	// notification lists.
+	savedSearchItemLimit = 5
+	// savedSearchSeenLimit bounds the products a saved search remembers.
+	savedSearchSeenLimit = 500
+)
+
+// SavedSearchManager manages users' saved searches.
+type SavedSearchManager struct {
+	repo   SavedSearchRepository
+	search *SearchProductsUseCase
+}
+
+// NewSavedSearchManager creates a new SavedSearchManager.
+func NewSavedSearchManager(repo SavedSearchRepository, search *SearchProductsUseCase) *SavedSearchManager {
+	return &SavedSearchManager{repo: repo, search: search}
+}
+
+// Create parses the query, takes the price constraint from the intent unless
+// one is given, and stores the search with its current results, so that
+// only products appearing later are reported.
+func (m *SavedSearchManager) Create(ctx context.Context, userID string, s *domain.SavedSearch) (*domain.SavedSearch, error) {
+	query := strings.Join(strings.Fields(s.Query), " ")
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is required", domain.ErrInvalidInput)
+	}
+	if s.MaxPriceETB < 0 {
+		return nil, fmt.Errorf("%w: maxPriceEtb must not be negative", domain.ErrInvalidInput)
+	}
+
+	now := time.Now().UTC()
+	saved := &domain.SavedSearch{
+		UserID:      userID,
+		Query:       query,
+		Intent:      m.search.ParseIntent(ctx, query),
+		MaxPriceETB: s.MaxPriceETB,
+		CreatedAt:   now,
+	}
+	if saved.MaxPriceETB == 0 {
+		if ceiling, ok := intentNumber(saved.Intent, intentPriceMaxETB); ok && ceiling > 0 {
+			saved.MaxPriceETB = ceiling
+		}
+	}
+	// Without a first run the watcher's first run records the results instead
+	if products, err := m.search.SearchProducts(ctx, query, SearchOptions{Intent: saved.Intent}); err == nil {
+		saved.LastResultIDs = productIDs(products)
+		saved.LastRunAt = &now
+	}
+	if err := m.repo.CreateSavedSearch(ctx, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// List returns the user's saved searches.
+func (m *SavedSearchManager) List(ctx context.Context, userID string) ([]*domain.SavedSearch, error) {
+	return m.repo.ListSavedSearches(ctx, userID)
+}
+
+// Delete removes one of the user's saved searches.
+func (m *SavedSearchManager) Delete(ctx context.Context, userID, id string) error {
+	return m.repo.DeleteSavedSearch(ctx, userID, id)
+}
+
+// SavedSearchWatcher re-runs saved searches and notifies their owners about
+// products that recent runs did not return and that match the price constraint.
+type SavedSearchWatcher struct {
+	repo     SavedSearchRepository
+	search   *SearchProductsUseCase
+	fx       IFXClient
+	notifier *NotificationService
+}
+
+// NewSavedSearchWatcher creates a new SavedSearchWatcher. fx prices products
+// that come without an ETB price; without it, new products that have no ETB
+// price hold their search back.
+func NewSavedSearchWatcher(repo SavedSearchRepository, search *SearchProductsUseCase, fx IFXClient, notifier *NotificationService) *SavedSearchWatcher {
+	return &SavedSearchWatcher{repo: repo, search: search, fx: fx, notifier: notifier}
+}
+
+// Run re-runs every saved search and returns how many notifications were
+// queued. A search's first run only records its results. Errors for
+// individual searches are collected and do not stop the run. A search with
+// new products that can only be priced through an unavailable FX rate is
+// skipped until the next run, so they are not reported at 0 ETB.
+func (w *SavedSearchWatcher) Run(ctx context.Context) (int, error) {
+	searches, err := w.repo.ListSavedSearches(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("list saved searches: %w", err)
+	}
+
+	var rate float64
+	var rateErr error
+	var rateTried bool
+	var errs []error
+	usdRate := func() (float64, bool) {
+		if w.fx == nil {
+			return 0, false
+		}
+		if !rateTried {
+			rateTried = true
+			if rate, rateErr = w.fx.GetRate(ctx, "USD", "ETB"); rateErr != nil {
+				errs = append(errs, fmt.Errorf("fx rate: %w", rateErr))
+			}
+		}
+		return rate, rateErr == nil && rate > 0
+	}
+
+	queued := 0
+	for _, s := range searches {
+		products, err := w.search.SearchProducts(ctx, s.Query, SearchOptions{Intent: s.Intent})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saved search %s: %w", s.ID, err))
+			continue
+		}
+
+		var fresh []domain.NotificationItem
+		unpriced := false
+		if s.LastRunAt != nil {
+			for _, p := range products {
+				if p == nil || containsString(s.LastResultIDs, p.ID) {
+					continue
+				}
+				etb := p.Price.ETB
+				if etb == 0 && p.Price.USD > 0 {
+					r, ok := usdRate()
+					if !ok {
+						unpriced = true
+						break
+					}
+					etb = p.Price.USD * r
+				}
+				if !s.Matches(etb) {
+					continue
+				}
+				item := domain.NotificationItem{ProductID: p.ID, Title: p.Title, ImageURL: p.ImageURL, PriceETB: etb}
+				if isWebURL(p.DeeplinkURL) {
+					item.URL = p.DeeplinkURL
+				}
+				fresh = append(fresh, item)
+			}
+		}
+		if unpriced {
+			// Leave the results alone so the products are still new next run
+			continue
+		}
+
+		if len(fresh) > 0 {
+			created, err := w.notifier.Notify(ctx, savedSearchNotification(s, fresh))
+			if err != nil {
+				// Keep the previous results so the next run finds them new again
+				errs = append(errs, fmt.Errorf("saved search %s: %w", s.ID, err))
+				continue
+			}
+			if created {
+				queued++
+			}
+		}
+		seen := mergeSeen(productIDs(products), s.LastResultIDs, savedSearchSeenLimit)
+		if err := w.repo.SaveRunResults(ctx, s.ID, seen, time.Now().UTC()); err != nil {
+			errs = append(errs, fmt.Errorf("saved search %s: %w", s.ID, err))
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// savedSearchNotification lists the cheapest new products. It is keyed by
+// the new products, so a run repeated after a failed update queues nothing.
+func savedSearchNotification(s *domain.SavedSearch, items []domain.NotificationItem) *domain.Notification {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].PriceETB < items[j].PriceETB })
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.ProductID
+	}
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(strings.Join(ids, ",")))
+
+	total := len(items)
+	if len(items) > savedSearchItemLimit {
+		items = items[:savedSearchItemLimit]
+	}
+	body := fmt.Sprintf("%d new products match \"%s\", from %.2f ETB.", total, s.Query, items[0].PriceETB)
+	if total == 1 {
+		body = fmt.Sprintf("%s matches \"%s\" at %.2f ETB.", items[0].Title, s.Query, items[0].PriceETB)
+	}
+	data := map[string]string{"savedSearchId": s.ID, "query": s.Query, "count": fmt.Sprintf("%d", total)}
+	if s.MaxPriceETB > 0 {
+		data["maxPriceEtb"] = fmt.Sprintf("%.2f", s.MaxPriceETB)
+	}
+	return &domain.Notification{
+		IdempotencyKey: "search:" + s.ID + ":" + hex.EncodeToString(sum[:8]),
+		UserID:         s.UserID,
+		Kind:           domain.NotificationSavedSearch,
+		Title:          "New matches for your search",
+		Body:           body,
+		Data:           data,
+		Items:          items,
+	}
+}
+
+// mergeSeen puts the latest IDs before the previously seen ones, without
+// duplicates, and keeps at most limit of them.
+func mergeSeen(latest, previous []string, limit int) []string {
+	seen := make(map[string]bool, len(latest)+len(previous))
+	out := make([]string, 0, len(latest)+len(previous))
+	for _, ids := range [][]string{latest, previous} {
+		for _, id := range ids {
+			if len(out) == limit {
+				return out
+			}
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+	}
+	return out
+}
+
+func productIDs(products []*domain.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		if p != nil {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memorySavedSearches struct {
+	searches map[string]*domain.SavedSearch
+}
+
+func (m *memorySavedSearches) CreateSavedSearch(ctx context.Context, s *domain.SavedSearch) error {
+	if m.searches == nil {
+		m.searches = map[string]*domain.SavedSearch{}
+	}
+	if s.ID == "" {
+		s.ID = fmt.Sprintf("S%d", len(m.searches)+1)
+	}
+	m.searches[s.ID] = s
+	return nil
+}
+
+func (m *memorySavedSearches) ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error) {
+	var out []*domain.SavedSearch
+	for _, s := range m.searches {
+		if userID == "" || s.UserID == userID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *memorySavedSearches) DeleteSavedSearch(ctx context.Context, userID, id string) error {
+	if s, ok := m.searches[id]; !ok || s.UserID != userID {
+		return domain.ErrSavedSearchNotFound
+	}
+	delete(m.searches, id)
+	return nil
+}
+
+func (m *memorySavedSearches) SaveRunResults(ctx context.Context, id string, productIDs []string, runAt time.Time) error {
+	s := m.searches[id]
+	s.LastResultIDs, s.LastRunAt = productIDs, &runAt
+	return nil
+}
+
+func TestSavedSearches_NotifiesNewMatches(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Title: "Phone 8GB", Price: domain.Price{ETB: 5500}},
+	}}
+	llm := &stubLLMGateway{intent: map[string]interface{}{"ram": "8GB", "price_max_ETB": float64(6000)}}
+	search := NewSearchProductsUseCase(ag, llm, nil, nil)
+	repo := &memorySavedSearches{}
+	m := NewSavedSearchManager(repo, search)
+
+	if _, err := m.Create(ctx, "U1", &domain.SavedSearch{Query: "   "}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+	s, err := m.Create(ctx, "U1", &domain.SavedSearch{Query: " phone  with 8GB RAM under 6000 birr "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Query != "phone with 8GB RAM under 6000 birr" || s.MaxPriceETB != 6000 || s.Intent["ram"] != "8GB" ||
+		len(s.LastResultIDs) != 1 || s.LastRunAt == nil {
+		t.Fatalf("unexpected saved search: %+v", s)
+	}
+
+	outbox := newMemoryOutbox()
+	w := NewSavedSearchWatcher(repo, search, &fixedFX{rate: 100}, NewNotificationService(outbox, domain.ChannelPush))
+
+	// Nothing new yet
+	if queued, err := w.Run(ctx); err != nil || queued != 0 {
+		t.Fatalf("queued=%d err=%v", queued, err)
+	}
+
+	// The stored intent is reused rather than the query parsed again
+	llm.intent = map[string]interface{}{}
+	ag.products = append(ag.products,
+		&domain.Product{ID: "P2", Title: "Phone 8GB Pro", Price: domain.Price{ETB: 5800}, DeeplinkURL: "https://example.com/p2"},
+		&domain.Product{ID: "P3", Title: "Phone 8GB Max", Price: domain.Price{ETB: 7000}},
+		&domain.Product{ID: "P4", Title: "Phone 8GB Lite", Price: domain.Price{USD: 45}},
+	)
+	queued, err := w.Run(ctx)
+	if err != nil || queued != 1 {
+		t.Fatalf("queued=%d err=%v", queued, err)
+	}
+	if ag.lastFilters["ram"] != "8GB" {
+		t.Fatalf("filters = %v", ag.lastFilters)
+	}
+	n := outbox.only(t)
+	if n.Kind != domain.NotificationSavedSearch || n.UserID != "U1" || len(n.Items) != 2 ||
+		n.Items[0].ProductID != "P4" || n.Items[0].PriceETB != 4500 || n.Items[1].URL != "https://example.com/p2" {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+	if !strings.Contains(n.Body, "2 new products") || !strings.HasPrefix(n.IdempotencyKey, "search:"+s.ID+":") {
+		t.Fatalf("unexpected body or key: %q %q", n.Body, n.IdempotencyKey)
+	}
+
+	// The new products are now known
+	if queued, _ := w.Run(ctx); queued != 0 {
+		t.Fatalf("re-notified known products: %d", queued)
+	}
+
+	if err := m.Delete(ctx, "U2", s.ID); !errors.Is(err, domain.ErrSavedSearchNotFound) {
+		t.Fatalf("deleted another user's search: %v", err)
+	}
+	if err := m.Delete(ctx, "U1", s.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSavedSearches_FirstRunRecordsResults(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Price: domain.Price{ETB: 100}}}}
+	repo := &memorySavedSearches{}
+	_ = repo.CreateSavedSearch(ctx, &domain.SavedSearch{ID: "S1", UserID: "U1", Query: "cable"})
+	outbox := newMemoryOutbox()
+	w := NewSavedSearchWatcher(repo, NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil), nil, NewNotificationService(outbox, domain.ChannelPush))
+
+	if queued, err := w.Run(ctx); err != nil || queued != 0 || len(outbox.items) != 0 {
+		t.Fatalf("first run notified: queued=%d err=%v", queued, err)
+	}
+	if s := repo.searches["S1"]; s.LastRunAt == nil || len(s.LastResultIDs) != 1 {
+		t.Fatalf("results not recorded: %+v", s)
+	}
+}
+
+func TestSavedSearches_WaitsForTheFXRate(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Price: domain.Price{ETB: 100}},
+		{ID: "P2", Title: "Cable USB-C", Price: domain.Price{USD: 2}},
+	}}
+	repo := &memorySavedSearches{}
+	ran := time.Now().UTC()
+	_ = repo.CreateSavedSearch(ctx, &domain.SavedSearch{ID: "S1", UserID: "U1", Query: "cable", LastResultIDs: []string{"P1"}, LastRunAt: &ran})
+	outbox := newMemoryOutbox()
+	fx := &fixedFX{err: errors.New("fx down")}
+	w := NewSavedSearchWatcher(repo, NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil), fx, NewNotificationService(outbox, domain.ChannelPush))
+
+	if queued, err := w.Run(ctx); err == nil || queued != 0 || len(outbox.items) != 0 {
+		t.Fatalf("notified without a rate: queued=%d err=%v", queued, err)
+	}
+	if s := repo.searches["S1"]; len(s.LastResultIDs) != 1 || !s.LastRunAt.Equal(ran) {
+		t.Fatalf("results recorded without a rate: %+v", s)
+	}
+
+	fx.rate, fx.err = 100, nil
+	if queued, err := w.Run(ctx); err != nil || queued != 1 {
+		t.Fatalf("queued=%d err=%v", queued, err)
+	}
+	if n := outbox.only(t); n.Items[0].ProductID != "P2" || n.Items[0].PriceETB != 200 {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+}
+
+func TestSavedSearches_ReturningProductsAreNotNew(t *testing.T) {
+	ctx := context.Background()
+	p1 := &domain.Product{ID: "P1", Price: domain.Price{ETB: 100}}
+	ag := &stubAlibabaGateway{products: []*domain.Product{p1}}
+	repo := &memorySavedSearches{}
+	ran := time.Now().UTC()
+	_ = repo.CreateSavedSearch(ctx, &domain.SavedSearch{ID: "S1", UserID: "U1", Query: "cable", LastResultIDs: []string{"P1"}, LastRunAt: &ran})
+	outbox := newMemoryOutbox()
+	w := NewSavedSearchWatcher(repo, NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil), nil, NewNotificationService(outbox, domain.ChannelPush))
+
+	// P1 drops out of a run, then comes back
+	ag.products = []*domain.Product{{ID: "P2", Price: domain.Price{ETB: 90}}}
+	if queued, err := w.Run(ctx); err != nil || queued != 1 {
+		t.Fatalf("queued=%d err=%v", queued, err)
+	}
+	ag.products = append(ag.products, p1)
+	if queued, err := w.Run(ctx); err != nil || queued != 0 {
+		t.Fatalf("returning product reported as new: queued=%d err=%v", queued, err)
+	}
+	if s := repo.searches["S1"]; strings.Join(s.LastResultIDs, ",") != "P2,P1" {
+		t.Fatalf("seen = %v", s.LastResultIDs)
+	}
+}
+
+func TestMergeSeen(t *testing.T) {
+	got := mergeSeen([]string{"C", "A"}, []string{"A", "B", "D"}, 3)
+	if strings.Join(got, ",") != "C,A,B" {
+		t.Fatalf("mergeSeen = %v", got)
+	}
+}
@@ -2,32 +2,77 @@ package usecase
 
 import (
 	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
 )
 
+// intentArrivesBefore is the intent key the LLM uses for a delivery deadline,
+// e.g. "need it before Timkat" -> "2026-01-19".
+const intentArrivesBefore = "arrives_before"
+
+// intentPriceMaxETB is the intent key the LLM uses for a price ceiling in birr,
+// e.g. "under 6000 birr" -> 6000.
+const intentPriceMaxETB = "price_max_ETB"
+
+// SearchOptions carries explicit search constraints supplied by the caller.
+// Non-zero values take precedence over the equivalent parsed intent.
+type SearchOptions struct {
+	// ArrivesBefore keeps only products whose latest arrival date is before it.
+	ArrivesBefore time.Time
+	// Intent, when set, is used instead of parsing the query.
+	Intent map[string]interface{}
+}
+
 // SearchProductsUseCase contains the business logic for searching products.
 // It orchestrates calls to external gateways (LLM, Alibaba, Cache).
 type SearchProductsUseCase struct {
 	alibabaGateway AlibabaGateway
 	llmGateway     LLMGateway
 	cacheGateway   CacheGateway
+	deals          *DealAnalyzer
 }
 
-// NewSearchProductsUseCase creates a new SearchProductsUseCase.
-func NewSearchProductsUseCase(ag AlibabaGateway, lg LLMGateway, cg CacheGateway) *SearchProductsUseCase {
+// NewSearchProductsUseCase creates a new SearchProductsUseCase. deals may be nil
+// to skip attaching deal verdicts.
+func NewSearchProductsUseCase(ag AlibabaGateway, lg LLMGateway, cg CacheGateway, deals *DealAnalyzer) *SearchProductsUseCase {
 	return &SearchProductsUseCase{
 		alibabaGateway: ag,
 		llmGateway:     lg,
 		cacheGateway:   cg,
+		deals:          deals,
 	}
 }
 
 // Search runs the mocked search pipeline: Parse -> Fetch (using intent as filters).
-func (uc *SearchProductsUseCase) Search(ctx context.Context, query string) (interface{}, error) {
-	// Parse intent via LLM
-	intent, err := uc.llmGateway.ParseIntent(ctx, query)
+func (uc *SearchProductsUseCase) Search(ctx context.Context, query string, opts SearchOptions) (interface{}, error) {
+	products, err := uc.SearchProducts(ctx, query, opts)
 	if err != nil {
-		// For V1 mock, fail soft by using empty filters
-		intent = map[string]interface{}{}
+		return nil, err
+	}
+	// Return the envelope-compatible data payload
+	return map[string]interface{}{"products": products}, nil
+}
+
+// SearchProducts runs the search pipeline and returns the typed product list.
+func (uc *SearchProductsUseCase) SearchProducts(ctx context.Context, query string, opts SearchOptions) ([]*domain.Product, error) {
+	intent := make(map[string]interface{}, len(opts.Intent))
+	for k, v := range opts.Intent {
+		intent[k] = v
+	}
+	if opts.Intent == nil {
+		intent = uc.ParseIntent(ctx, query)
+	}
+
+	deadline := opts.ArrivesBefore
+	if deadline.IsZero() {
+		deadline, _ = intentDate(intent, intentArrivesBefore)
+	}
+	if !deadline.IsZero() {
+		intent[intentArrivesBefore] = deadline.Format(time.RFC3339)
 	}
 
 	// Fetch products from the gateway
@@ -35,6 +80,78 @@ func (uc *SearchProductsUseCase) Search(ctx context.Context, query string) (inte
 	if err != nil {
 		return nil, err
 	}
-	// Return the envelope-compatible data payload
-	return map[string]interface{}{"products": products}, nil
+
+	if !deadline.IsZero() {
+		products = filterArrivesBefore(products, deadline)
+	}
+	if uc.deals != nil {
+		uc.deals.Annotate(ctx, products)
+	}
+	return products, nil
+}
+
+// ParseIntent parses the query into search filters via the LLM. It fails
+// soft: an unparseable query yields empty filters.
+func (uc *SearchProductsUseCase) ParseIntent(ctx context.Context, query string) map[string]interface{} {
+	intent, err := uc.llmGateway.ParseIntent(ctx, query)
+	if err != nil || intent == nil {
+		return map[string]interface{}{}
+	}
+	return intent
+}
+
+// filterArrivesBefore keeps products guaranteed to arrive before the deadline,
+// ordered by latest arrival. Products without a structured window are dropped
+// because their arrival cannot be promised.
+func filterArrivesBefore(products []*domain.Product, deadline time.Time) []*domain.Product {
+	out := make([]*domain.Product, 0, len(products))
+	for _, p := range products {
+		if p != nil && p.Delivery != nil && p.Delivery.ArrivesBefore(deadline) {
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Delivery.LatestArrival.Before(out[j].Delivery.LatestArrival)
+	})
+	return out
+}
+
+// intentDate reads a date value from the parsed intent. It accepts
+// RFC 3339 timestamps and plain YYYY-MM-DD dates.
+func intentDate(intent map[string]interface{}, key string) (time.Time, bool) {
+	s, ok := intent[key].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// intentNumber reads a numeric value from the parsed intent, whether the LLM
+// returned it as a number or a numeric string.
+func intentNumber(intent map[string]interface{}, key string) (float64, bool) {
+	switch v := intent[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ParseDate parses an RFC 3339 timestamp or a YYYY-MM-DD date (UTC midnight).
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
 }
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type stubAlibabaGateway struct {
+	products    []*domain.Product
+	lastFilters map[string]interface{}
+}
+
+func (s *stubAlibabaGateway) FetchProducts(ctx context.Context, query string, filters map[string]interface{}) ([]*domain.Product, error) {
+	s.lastFilters = filters
+	return s.products, nil
+}
+
+func (s *stubAlibabaGateway) GetProduct(ctx context.Context, id string) (*domain.Product, error) {
+	for _, p := range s.products {
+		if p.ID == id {
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrProductNotFound
+}
+
+type stubLLMGateway struct {
+	intent map[string]interface{}
+}
+
+func (s *stubLLMGateway) ParseIntent(ctx context.Context, query string) (map[string]interface{}, error) {
+	return s.intent, nil
+}
+
+func (s *stubLLMGateway) SummarizeProduct(ctx context.Context, product *domain.Product) ([]string, error) {
+	return []string{"summary of " + product.ID}, nil
+}
+
+func productArriving(id string, minDays, maxDays int, from time.Time) *domain.Product {
+	w := domain.NewDeliveryWindow(minDays, maxDays, from)
+	return &domain.Product{ID: id, Delivery: &w}
+}
+
+func TestSearchProducts_ArrivesBefore(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		productArriving("slow", 15, 30, now),
+		productArriving("fast", 7, 10, now),
+		productArriving("medium", 10, 14, now),
+		{ID: "unknown"},
+	}}
+
+	t.Run("FromQueryOption", func(t *testing.T) {
+		uc := NewSearchProductsUseCase(ag, &stubLLMGateway{intent: map[string]interface{}{}}, nil, nil)
+
+		products, err := uc.SearchProducts(context.Background(), "phone", SearchOptions{ArrivesBefore: now.AddDate(0, 0, 20)})
+		if err != nil {
+			t.Fatalf("SearchProducts failed: %v", err)
+		}
+		if len(products) != 2 || products[0].ID != "fast" || products[1].ID != "medium" {
+			t.Fatalf("unexpected products: %+v", products)
+		}
+		if _, ok := ag.lastFilters[intentArrivesBefore]; !ok {
+			t.Errorf("deadline was not passed to the gateway filters")
+		}
+	})
+
+	t.Run("FromIntent", func(t *testing.T) {
+		lg := &stubLLMGateway{intent: map[string]interface{}{intentArrivesBefore: "2026-01-12"}}
+		uc := NewSearchProductsUseCase(ag, lg, nil, nil)
+
+		products, err := uc.SearchProducts(context.Background(), "need it before Timkat", SearchOptions{})
+		if err != nil {
+			t.Fatalf("SearchProducts failed: %v", err)
+		}
+		if len(products) != 1 || products[0].ID != "fast" {
+			t.Fatalf("unexpected products: %+v", products)
+		}
+	})
+
+	t.Run("NoDeadlineKeepsEverything", func(t *testing.T) {
+		uc := NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil)
+
+		products, err := uc.SearchProducts(context.Background(), "phone", SearchOptions{})
+		if err != nil {
+			t.Fatalf("SearchProducts failed: %v", err)
+		}
+		if len(products) != 4 {
+			t.Fatalf("expected all products, got %d", len(products))
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// smsResultLimit is how many search results an SMS reply lists.
+	smsResultLimit = 3
+	// smsTitleRunes is how long a product title may be in an SMS reply.
+	smsTitleRunes = 28
+	// smsDefaultDrop is the price drop a bare "reply with a number" alert waits for.
+	smsDefaultDrop = 0.05
+)
+
+// SMSAssistant serves feature-phone users over SMS: a text is run as a
+// product search and answered with the top results, and a reply with a
+// result number creates a price alert for that product.
+type SMSAssistant struct {
+	search   *SearchProductsUseCase
+	alerts   *AlertManager
+	sender   SMSSender
+	sessions SMSSessionStore
+	links    LinkShortener
+}
+
+// NewSMSAssistant creates a new SMSAssistant. links may be nil to leave
+// links out of replies.
+func NewSMSAssistant(search *SearchProductsUseCase, alerts *AlertManager, sender SMSSender, sessions SMSSessionStore, links LinkShortener) *SMSAssistant {
+	return &SMSAssistant{search: search, alerts: alerts, sender: sender, sessions: sessions, links: links}
+}
+
+// HandleIncoming answers one incoming SMS. A reply of "N" watches result N
+// for a 5% price drop and "N PRICE" sets the target price in ETB. A message
+// the provider redelivers is answered once: every reply is a paid SMS.
+func (a *SMSAssistant) HandleIncoming(ctx context.Context, in domain.SMSIncoming) error {
+	text := strings.Join(strings.Fields(in.Text), " ")
+	if in.From == "" || text == "" {
+		return nil
+	}
+	if in.MessageID != "" {
+		first, err := a.sessions.MarkReceived(ctx, in.MessageID)
+		if err != nil {
+			return fmt.Errorf("sms %s: %w", in.MessageID, err)
+		}
+		if !first {
+			return nil
+		}
+	}
+	if pick, target, ok := parseSMSPick(text); ok {
+		return a.createAlert(ctx, in.From, pick, target)
+	}
+	return a.searchReply(ctx, in.From, text)
+}
+
+func (a *SMSAssistant) searchReply(ctx context.Context, phone, query string) error {
+	products, err := a.search.SearchProducts(ctx, query, SearchOptions{})
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return a.reply(ctx, phone, "ShopAlly: no products found for \""+truncateRunes(query, smsTitleRunes)+"\". Try other words.")
+	}
+	if len(products) > smsResultLimit {
+		products = products[:smsResultLimit]
+	}
+
+	session := &domain.SMSSession{Phone: phone, CreatedAt: time.Now().UTC()}
+	var sb strings.Builder
+	sb.WriteString("ShopAlly:")
+	for i, p := range products {
+		title := truncateRunes(p.Title, smsTitleRunes)
+		fmt.Fprintf(&sb, "\n%d) %s %.0f ETB", i+1, title, math.Round(p.Price.ETB))
+		if link := a.shortLink(ctx, p.DeeplinkURL); link != "" {
+			sb.WriteString(" " + link)
+		}
+		session.Results = append(session.Results, domain.SMSResult{ProductID: p.ID, Title: title, PriceETB: p.Price.ETB})
+	}
+	fmt.Fprintf(&sb, "\nReply 1-%d for a price drop alert, or e.g. \"1 %.0f\" to set your price.", len(products), math.Round(products[0].Price.ETB*(1-smsDefaultDrop)))
+	if err := a.sessions.SaveSession(ctx, session); err != nil {
+		return err
+	}
+	return a.reply(ctx, phone, sb.String())
+}
+
+// shortLink shortens a product link, leaving it out when it cannot be
+// shortened; an SMS without the link is still useful.
+func (a *SMSAssistant) shortLink(ctx context.Context, longURL string) string {
+	if a.links == nil || !isWebURL(longURL) {
+		return ""
+	}
+	short, err := a.links.Shorten(ctx, longURL)
+	if err != nil {
+		return ""
+	}
+	return short
+}
+
+func (a *SMSAssistant) createAlert(ctx context.Context, phone string, pick int, target float64) error {
+	session, err := a.sessions.LoadSession(ctx, phone)
+	if err != nil {
+		return err
+	}
+	if session == nil || pick < 1 || pick > len(session.Results) {
+		return a.reply(ctx, phone, "ShopAlly: send what you are looking for first, then reply with the number of a result.")
+	}
+	result := session.Results[pick-1]
+	if target == 0 {
+		target = math.Floor(result.PriceETB * (1 - smsDefaultDrop))
+	}
+	if target >= result.PriceETB {
+		return a.reply(ctx, phone, fmt.Sprintf("ShopAlly: %s is already %.0f ETB. Choose a lower price.", result.Title, math.Round(result.PriceETB)))
+	}
+
+	alert := &domain.Alert{
+		UserID:    domain.SMSUserID(phone),
+		ProductID: result.ProductID,
+		Condition: domain.AlertCondition{Kind: domain.AlertTargetPrice, TargetPrice: target},
+	}
+	err = a.alerts.CreateAlert(ctx, alert)
+	switch {
+	case errors.Is(err, domain.ErrAlertExists):
+		return a.reply(ctx, phone, fmt.Sprintf("ShopAlly: you already have an alert for %s.", result.Title))
+	case errors.Is(err, domain.ErrAlertLimit):
+		return a.reply(ctx, phone, "ShopAlly: you have reached the maximum number of alerts.")
+	case err != nil:
+		return err
+	}
+	return a.reply(ctx, phone, fmt.Sprintf("ShopAlly: we will text you when %s drops to %.0f ETB or less.", result.Title, target))
+}
+
+func (a *SMSAssistant) reply(ctx context.Context, phone, text string) error {
+	_, err := a.sender.SendSMS(ctx, phone, text)
+	return err
+}
+
+// parseSMSPick parses "N" or "N PRICE"; anything else is a search query.
+func parseSMSPick(text string) (int, float64, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, 0, false
+	}
+	pick, err := strconv.Atoi(fields[0])
+	if err != nil || pick < 1 || pick > 9 {
+		return 0, 0, false
+	}
+	if len(fields) == 1 {
+		return pick, 0, true
+	}
+	target, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToUpper(fields[1]), "ETB"), 64)
+	// ParseFloat accepts "NaN" and "Inf", which slip past comparisons
+	if err != nil || math.IsNaN(target) || math.IsInf(target, 0) || target <= 0 {
+		return 0, 0, false
+	}
+	return pick, target, true
+}
+
+// truncateRunes shortens s to n runes. The ellipsis is ASCII to keep the
+// message in the GSM alphabet, where one SMS holds 160 characters.
+func truncateRunes(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= n {
+		return string(r)
+	}
+	return strings.TrimSpace(string(r[:n-2])) + ".."
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type recordingSMS struct {
+	to   []string
+	sent []string
+}
+
+func (s *recordingSMS) SendSMS(ctx context.Context, to, text string) (string, error) {
+	s.to, s.sent = append(s.to, to), append(s.sent, text)
+	return "msg", nil
+}
+
+func (s *recordingSMS) last() string { return s.sent[len(s.sent)-1] }
+
+type memorySMSSessions struct {
+	sessions map[string]*domain.SMSSession
+	seen     map[string]bool
+}
+
+func newMemorySMSSessions() *memorySMSSessions {
+	return &memorySMSSessions{sessions: map[string]*domain.SMSSession{}, seen: map[string]bool{}}
+}
+
+func (m *memorySMSSessions) SaveSession(ctx context.Context, s *domain.SMSSession) error {
+	m.sessions[s.Phone] = s
+	return nil
+}
+
+func (m *memorySMSSessions) LoadSession(ctx context.Context, phone string) (*domain.SMSSession, error) {
+	return m.sessions[phone], nil
+}
+
+func (m *memorySMSSessions) MarkReceived(ctx context.Context, messageID string) (bool, error) {
+	if m.seen[messageID] {
+		return false, nil
+	}
+	m.seen[messageID] = true
+	return true, nil
+}
+
+type prefixShortener string
+
+func (p prefixShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	return string(p) + longURL[strings.LastIndex(longURL, "/")+1:], nil
+}
+
+func TestSMSAssistant_SearchThenAlertByNumber(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Title: "Wireless Bluetooth Earbuds with Charging Case", DeeplinkURL: "https://s.click/abc", Price: domain.Price{ETB: 1999.6}},
+		{ID: "P2", Title: "Phone case", DeeplinkURL: "#", Price: domain.Price{ETB: 300}},
+		{ID: "P3", Title: "Cable", Price: domain.Price{ETB: 120}},
+		{ID: "P4", Title: "Charger", Price: domain.Price{ETB: 500}},
+	}}
+	alerts := newMockAlertRepository()
+	sms := &recordingSMS{}
+	sessions := newMemorySMSSessions()
+	a := NewSMSAssistant(NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil), NewAlertManager(alerts, nil, nil, 0, nil), sms, sessions, prefixShortener("sa.et/s/"))
+
+	if err := a.HandleIncoming(ctx, domain.SMSIncoming{From: "+251911000000", Text: "  earbuds \n"}); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	reply := sms.last()
+	for _, want := range []string{"1) Wireless Bluetooth Earbuds.. 2000 ETB sa.et/s/abc", "2) Phone case 300 ETB\n", "3) Cable 120 ETB", "Reply 1-3"} {
+		if !strings.Contains(reply, want) {
+			t.Fatalf("reply %q is missing %q", reply, want)
+		}
+	}
+	if strings.Contains(reply, "Charger") || sms.to[0] != "+251911000000" {
+		t.Fatalf("unexpected reply %q to %v", reply, sms.to)
+	}
+
+	// A bare number watches for a 5% drop
+	if err := a.HandleIncoming(ctx, domain.SMSIncoming{From: "+251911000000", Text: "2"}); err != nil {
+		t.Fatalf("pick: %v", err)
+	}
+	active, _ := alerts.ListActiveAlerts()
+	if len(active) != 1 || active[0].ProductID != "P2" || active[0].TargetPrice != 285 || active[0].UserID != "sms:+251911000000" {
+		t.Fatalf("unexpected alerts: %+v", active)
+	}
+	if !strings.Contains(sms.last(), "drops to 285 ETB") {
+		t.Fatalf("confirmation = %q", sms.last())
+	}
+
+	// The same product again is refused politely
+	if err := a.HandleIncoming(ctx, domain.SMSIncoming{From: "+251911000000", Text: "2 250"}); err != nil {
+		t.Fatalf("duplicate pick: %v", err)
+	}
+	if !strings.Contains(sms.last(), "already have an alert for Phone case") {
+		t.Fatalf("duplicate reply = %q", sms.last())
+	}
+
+	// A target at or above the current price is refused
+	_ = a.HandleIncoming(ctx, domain.SMSIncoming{From: "+251911000000", Text: "3 150"})
+	if !strings.Contains(sms.last(), "Choose a lower price") {
+		t.Fatalf("reply = %q", sms.last())
+	}
+}
+
+func TestSMSAssistant_PickWithoutSession(t *testing.T) {
+	sms := &recordingSMS{}
+	alerts := newMockAlertRepository()
+	a := NewSMSAssistant(nil, NewAlertManager(alerts, nil, nil, 0, nil), sms, newMemorySMSSessions(), nil)
+
+	if err := a.HandleIncoming(context.Background(), domain.SMSIncoming{From: "+251922", Text: "1 900"}); err != nil {
+		t.Fatal(err)
+	}
+	if active, _ := alerts.ListActiveAlerts(); len(active) != 0 || !strings.Contains(sms.last(), "send what you are looking for first") {
+		t.Fatalf("alerts=%v reply=%q", active, sms.last())
+	}
+}
+
+func TestSMSAssistant_RedeliveryIsAnsweredOnce(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Title: "Earbuds", Price: domain.Price{ETB: 2000}}}}
+	sms := &recordingSMS{}
+	a := NewSMSAssistant(NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil), NewAlertManager(newMockAlertRepository(), nil, nil, 0, nil), sms, newMemorySMSSessions(), nil)
+
+	search := domain.SMSIncoming{MessageID: "in-1", From: "+251911000000", Text: "earbuds"}
+	for i := 0; i < 2; i++ {
+		if err := a.HandleIncoming(ctx, search); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pick := domain.SMSIncoming{MessageID: "in-2", From: "+251911000000", Text: "1"}
+	for i := 0; i < 2; i++ {
+		if err := a.HandleIncoming(ctx, pick); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sms.sent) != 2 || !strings.Contains(sms.last(), "drops to") {
+		t.Fatalf("replies = %q", sms.sent)
+	}
+}
+
+func TestParseSMSPick(t *testing.T) {
+	cases := []struct {
+		in     string
+		pick   int
+		target float64
+		ok     bool
+	}{
+		{"2", 2, 0, true},
+		{"1 1500", 1, 1500, true},
+		{"3 1200etb", 3, 1200, true},
+		{"0", 0, 0, false},
+		{"iphone 13", 0, 0, false},
+		{"2 cheap", 0, 0, false},
+		{"1 2 3", 0, 0, false},
+		{"1 nan", 0, 0, false},
+		{"1 NaNetb", 0, 0, false},
+		{"1 inf", 0, 0, false},
+		{"1 -Inf", 0, 0, false},
+	}
+	for _, tc := range cases {
+		pick, target, ok := parseSMSPick(tc.in)
+		if pick != tc.pick || target != tc.target || ok != tc.ok {
+			t.Errorf("%q: got (%d, %v, %v)", tc.in, pick, target, ok)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// linkCodeTTL is how long a one-time link code stays valid.
+	linkCodeTTL = 10 * time.Minute
+	// linkCodeBytes of randomness make an 8 character base32 code.
+	linkCodeBytes = 5
+	// maxLinkFailures wrong codes lock a chat out of linking for
+	// linkLockout, so codes cannot be guessed.
+	maxLinkFailures = 5
+	linkLockout     = time.Hour
+	// botResultLimit is how many search results the bot replies with.
+	botResultLimit = 3
+)
+
+// TelegramBot links user accounts to Telegram chats and answers free-text
+// messages with product search results.
+type TelegramBot struct {
+	links       TelegramLinkRepository
+	sender      TelegramSender
+	search      *SearchProductsUseCase
+	botUsername string
+}
+
+// NewTelegramBot creates a new TelegramBot. botUsername is used to build
+// t.me links for link codes and may be empty.
+func NewTelegramBot(links TelegramLinkRepository, sender TelegramSender, search *SearchProductsUseCase, botUsername string) *TelegramBot {
+	return &TelegramBot{links: links, sender: sender, search: search, botUsername: strings.TrimPrefix(botUsername, "@")}
+}
+
+// CreateLinkCode issues a one-time code the user sends to the bot to link
+// their Telegram chat.
+func (b *TelegramBot) CreateLinkCode(ctx context.Context, userID string) (*domain.TelegramLinkCode, error) {
+	raw := make([]byte, linkCodeBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	code := &domain.TelegramLinkCode{
+		Code:      base32.StdEncoding.EncodeToString(raw),
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC().Add(linkCodeTTL),
+	}
+	if err := b.links.CreateLinkCode(ctx, code); err != nil {
+		return nil, err
+	}
+	if b.botUsername != "" {
+		code.BotURL = fmt.Sprintf("https://t.me/%s?start=%s", b.botUsername, code.Code)
+	}
+	return code, nil
+}
+
+// HandleMessage answers one incoming message. "/start <code>" and
+// "/link <code>" link the chat, "/unlink" removes the link and any other
+// text is run as a product search.
+func (b *TelegramBot) HandleMessage(ctx context.Context, in domain.TelegramIncoming) error {
+	text := strings.TrimSpace(in.Text)
+	if text == "" {
+		return nil
+	}
+	cmd, arg := text, ""
+	if i := strings.IndexAny(text, " \n"); i >= 0 {
+		cmd, arg = text[:i], strings.TrimSpace(text[i+1:])
+	}
+	// Commands in groups arrive as /cmd@botname
+	cmd, _, _ = strings.Cut(cmd, "@")
+
+	switch cmd {
+	case "/start", "/link":
+		if arg == "" {
+			return b.reply(ctx, in.ChatID, "Hi! Send me what you are looking for, e.g. <i>wireless earbuds under 2000 birr</i>.\n\nTo get price alerts here, open ShopAlly, tap <b>Connect Telegram</b> and send me the code: /link K7QX2MPA")
+		}
+		return b.link(ctx, in, arg)
+	case "/unlink":
+		if err := b.links.DeleteLinkByChat(ctx, in.ChatID); err != nil {
+			return err
+		}
+		return b.reply(ctx, in.ChatID, "This chat is no longer linked. You will not get alerts here.")
+	case "/help":
+		return b.reply(ctx, in.ChatID, "Send me a product to search for. Use /link &lt;code&gt; to get price alerts here and /unlink to stop them.")
+	}
+	if strings.HasPrefix(cmd, "/") {
+		return b.reply(ctx, in.ChatID, "Unknown command. Try /help.")
+	}
+	return b.searchReply(ctx, in.ChatID, text)
+}
+
+func (b *TelegramBot) link(ctx context.Context, in domain.TelegramIncoming, code string) error {
+	now := time.Now().UTC()
+	failures, err := b.links.LinkFailures(ctx, in.ChatID, now)
+	if err != nil {
+		return err
+	}
+	if failures >= maxLinkFailures {
+		return b.reply(ctx, in.ChatID, "Too many wrong codes. Please try again in an hour.")
+	}
+	lc, err := b.links.ConsumeLinkCode(ctx, strings.ToUpper(code), now)
+	if errors.Is(err, domain.ErrLinkCodeInvalid) {
+		if _, err := b.links.RecordLinkFailure(ctx, in.ChatID, now, linkLockout); err != nil {
+			return err
+		}
+		return b.reply(ctx, in.ChatID, "That code is invalid or has expired. Please get a new one in the app.")
+	}
+	if err != nil {
+		return err
+	}
+	link := &domain.TelegramLink{UserID: lc.UserID, ChatID: in.ChatID, Username: in.Username, LinkedAt: time.Now().UTC()}
+	if err := b.links.SaveLink(ctx, link); err != nil {
+		return err
+	}
+	return b.reply(ctx, in.ChatID, "✅ Linked! Your price alerts will arrive in this chat.")
+}
+
+func (b *TelegramBot) searchReply(ctx context.Context, chatID int64, query string) error {
+	products, err := b.search.SearchProducts(ctx, query, SearchOptions{})
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return b.reply(ctx, chatID, "No products found for <i>"+html.EscapeString(query)+"</i>. Try different words.")
+	}
+	if len(products) > botResultLimit {
+		products = products[:botResultLimit]
+	}
+	for _, p := range products {
+		if _, err := b.sender.SendMessage(ctx, chatID, productMessage(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *TelegramBot) reply(ctx context.Context, chatID int64, text string) error {
+	_, err := b.sender.SendMessage(ctx, chatID, domain.TelegramMessage{Text: text})
+	return err
+}
+
+// productMessage renders a search result as a photo card with a buy button.
+func productMessage(p *domain.Product) domain.TelegramMessage {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<b>%s</b>\n", html.EscapeString(p.Title))
+	fmt.Fprintf(&sb, "💰 %.2f ETB (≈ $%.2f)", p.Price.ETB, p.Price.USD)
+	if p.ProductRating > 0 {
+		fmt.Fprintf(&sb, "\n⭐ %.1f", p.ProductRating)
+	}
+	msg := domain.TelegramMessage{Text: sb.String()}
+	if isWebURL(p.ImageURL) {
+		msg.PhotoURL = p.ImageURL
+	}
+	// Telegram rejects buttons that do not open a web URL
+	if isWebURL(p.DeeplinkURL) {
+		msg.Buttons = []domain.TelegramButton{{Text: "View on AliExpress", URL: p.DeeplinkURL}}
+	}
+	return msg
+}
+
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
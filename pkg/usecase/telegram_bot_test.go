@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryTelegramLinks struct {
+	codes    map[string]*domain.TelegramLinkCode
+	links    map[string]*domain.TelegramLink
+	failures map[int64]int
+}
+
+func newMemoryTelegramLinks() *memoryTelegramLinks {
+	return &memoryTelegramLinks{
+		codes:    map[string]*domain.TelegramLinkCode{},
+		links:    map[string]*domain.TelegramLink{},
+		failures: map[int64]int{},
+	}
+}
+
+func (m *memoryTelegramLinks) RecordLinkFailure(ctx context.Context, chatID int64, now time.Time, window time.Duration) (int, error) {
+	m.failures[chatID]++
+	return m.failures[chatID], nil
+}
+
+func (m *memoryTelegramLinks) LinkFailures(ctx context.Context, chatID int64, now time.Time) (int, error) {
+	return m.failures[chatID], nil
+}
+
+func (m *memoryTelegramLinks) CreateLinkCode(ctx context.Context, code *domain.TelegramLinkCode) error {
+	cp := *code
+	m.codes[code.Code] = &cp
+	return nil
+}
+
+func (m *memoryTelegramLinks) ConsumeLinkCode(ctx context.Context, code string, now time.Time) (*domain.TelegramLinkCode, error) {
+	lc, ok := m.codes[code]
+	if !ok || !lc.ExpiresAt.After(now) {
+		return nil, domain.ErrLinkCodeInvalid
+	}
+	delete(m.codes, code)
+	return lc, nil
+}
+
+func (m *memoryTelegramLinks) SaveLink(ctx context.Context, link *domain.TelegramLink) error {
+	_ = m.DeleteLinkByChat(ctx, link.ChatID)
+	m.links[link.UserID] = link
+	return nil
+}
+
+func (m *memoryTelegramLinks) GetLinkByUser(ctx context.Context, userID string) (*domain.TelegramLink, error) {
+	return m.links[userID], nil
+}
+
+func (m *memoryTelegramLinks) GetLinkByChat(ctx context.Context, chatID int64) (*domain.TelegramLink, error) {
+	for _, l := range m.links {
+		if l.ChatID == chatID {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryTelegramLinks) DeleteLinkByChat(ctx context.Context, chatID int64) error {
+	for user, l := range m.links {
+		if l.ChatID == chatID {
+			delete(m.links, user)
+		}
+	}
+	return nil
+}
+
+type recordingSender struct {
+	sent []domain.TelegramMessage
+}
+
+func (s *recordingSender) SendMessage(ctx context.Context, chatID int64, msg domain.TelegramMessage) (int64, error) {
+	s.sent = append(s.sent, msg)
+	return int64(len(s.sent)), nil
+}
+
+func TestTelegramBot_LinkWithOneTimeCode(t *testing.T) {
+	ctx := context.Background()
+	links := newMemoryTelegramLinks()
+	sender := &recordingSender{}
+	bot := NewTelegramBot(links, sender, nil, "@ShopAllyBot")
+
+	code, err := bot.CreateLinkCode(ctx, "U1")
+	if err != nil {
+		t.Fatalf("create code: %v", err)
+	}
+	if len(code.Code) != 8 || strings.Trim(code.Code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567") != "" || code.BotURL != "https://t.me/ShopAllyBot?start="+code.Code {
+		t.Fatalf("unexpected code: %+v", code)
+	}
+
+	if err := bot.HandleMessage(ctx, domain.TelegramIncoming{ChatID: 42, Username: "abebe", Text: "/start " + strings.ToLower(code.Code)}); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if l, _ := links.GetLinkByUser(ctx, "U1"); l == nil || l.ChatID != 42 || l.Username != "abebe" {
+		t.Fatalf("link not saved: %+v", l)
+	}
+
+	// The code is single use
+	_ = bot.HandleMessage(ctx, domain.TelegramIncoming{ChatID: 7, Text: "/link " + code.Code})
+	if l, _ := links.GetLinkByChat(ctx, 7); l != nil {
+		t.Fatalf("code reused: %+v", l)
+	}
+	if last := sender.sent[len(sender.sent)-1].Text; !strings.Contains(last, "invalid or has expired") {
+		t.Fatalf("reply = %q", last)
+	}
+
+	_ = bot.HandleMessage(ctx, domain.TelegramIncoming{ChatID: 42, Text: "/unlink"})
+	if l, _ := links.GetLinkByUser(ctx, "U1"); l != nil {
+		t.Fatalf("still linked: %+v", l)
+	}
+}
+
+func TestTelegramBot_LocksOutGuessing(t *testing.T) {
+	ctx := context.Background()
+	links := newMemoryTelegramLinks()
+	sender := &recordingSender{}
+	bot := NewTelegramBot(links, sender, nil, "")
+	code, err := bot.CreateLinkCode(ctx, "U1")
+	if err != nil {
+		t.Fatalf("create code: %v", err)
+	}
+
+	for i := 0; i < maxLinkFailures; i++ {
+		_ = bot.HandleMessage(ctx, domain.TelegramIncoming{ChatID: 7, Text: "/link AAAAAAAA"})
+	}
+	// Even the right code is refused once the chat is locked out
+	_ = bot.HandleMessage(ctx, domain.TelegramIncoming{ChatID: 7, Text: "/link " + code.Code})
+	if l, _ := links.GetLinkByChat(ctx, 7); l != nil {
+		t.Fatalf("locked out chat linked: %+v", l)
+	}
+	if last := sender.sent[len(sender.sent)-1].Text; !strings.Contains(last, "Too many wrong codes") {
+		t.Fatalf("reply = %q", last)
+	}
+
+	// Other chats are not affected, and the code was not used up
+	if err := bot.HandleMessage(ctx, domain.TelegramIncoming{ChatID: 42, Text: "/link " + code.Code}); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if l, _ := links.GetLinkByUser(ctx, "U1"); l == nil || l.ChatID != 42 {
+		t.Fatalf("link not saved: %+v", l)
+	}
+}
+
+func TestTelegramBot_SearchRepliesWithTopResults(t *testing.T) {
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "1", Title: "Earbuds <Pro>", ImageURL: "https://img/1.jpg", DeeplinkURL: "https://s.click/1", Price: domain.Price{ETB: 1500, USD: 12}},
+		{ID: "2", Title: "Cable", DeeplinkURL: "#"},
+		{ID: "3", Title: "Case"},
+		{ID: "4", Title: "Charger"},
+	}}
+	sender := &recordingSender{}
+	bot := NewTelegramBot(newMemoryTelegramLinks(), sender, NewSearchProductsUseCase(ag, &stubLLMGateway{}, nil, nil), "")
+
+	if err := bot.HandleMessage(context.Background(), domain.TelegramIncoming{ChatID: 1, Text: "wireless earbuds"}); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(sender.sent) != 3 {
+		t.Fatalf("sent %d messages, want 3", len(sender.sent))
+	}
+	first := sender.sent[0]
+	if first.PhotoURL != "https://img/1.jpg" || !strings.Contains(first.Text, "Earbuds &lt;Pro&gt;") || !strings.Contains(first.Text, "1500.00 ETB") {
+		t.Fatalf("first result: %+v", first)
+	}
+	if len(first.Buttons) != 1 || first.Buttons[0].URL != "https://s.click/1" {
+		t.Fatalf("first buttons: %+v", first.Buttons)
+	}
+	if len(sender.sent[1].Buttons) != 0 || sender.sent[1].PhotoURL != "" {
+		t.Fatalf("non-web links must be dropped: %+v", sender.sent[1])
+	}
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// FXTracker fetches exchange rates and stores them as FX history.
+type FXTracker struct {
+	fx      IFXClient
+	history FXHistoryRepository
+	events  EventPublisher
+}
+
+// NewFXTracker creates a new FXTracker. events receives FXRateUpdated and may
+// be nil.
+func NewFXTracker(fx IFXClient, history FXHistoryRepository, events EventPublisher) *FXTracker {
+	return &FXTracker{fx: fx, history: history, events: events}
+}
+
+// Record fetches the current from->to rate and appends it to the history.
+func (t *FXTracker) Record(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	rate, err := t.fx.GetRate(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	err = t.history.RecordRate(ctx, &domain.FXRateSnapshot{
+		ID:         uuid.New().String(),
+		From:       from,
+		To:         to,
+		Rate:       rate,
+		RecordedAt: now,
+	})
+	if err == nil && t.events != nil {
+		err = t.events.Publish(ctx, FXRateUpdated{From: from, To: to, Rate: rate, At: now})
+	}
+	return rate, err
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// fxChangeThreshold is the relative FX move below which an unchanged USD price
+// is considered unchanged in ETB as well.
+const fxChangeThreshold = 0.005
+
+// PriceTracker records price snapshots for tracked products and serves their history.
+// A product is tracked when an active alert references it or it was viewed recently.
+type PriceTracker struct {
+	alibabaGateway AlibabaGateway
+	fx             IFXClient
+	history        PriceHistoryRepository
+	alerts         AlertRepository
+	views          ViewTracker
+	viewWindow     time.Duration
+	events         EventPublisher
+}
+
+// NewPriceTracker creates a new PriceTracker. alerts and views may be nil to
+// skip that source of tracked products. events receives PriceChanged and may
+// be nil.
+func NewPriceTracker(ag AlibabaGateway, fx IFXClient, history PriceHistoryRepository, alerts AlertRepository, views ViewTracker, viewWindow time.Duration, events EventPublisher) *PriceTracker {
+	return &PriceTracker{
+		alibabaGateway: ag,
+		fx:             fx,
+		history:        history,
+		alerts:         alerts,
+		views:          views,
+		viewWindow:     viewWindow,
+		events:         events,
+	}
+}
+
+// RecordSnapshots fetches the current price of every tracked product and
+// records it. It returns the number of products whose price changed.
+// Errors for individual products are collected and do not stop the run.
+func (t *PriceTracker) RecordSnapshots(ctx context.Context) (int, error) {
+	ids, err := t.trackedProductIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, err := t.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil {
+		return 0, fmt.Errorf("fx rate: %w", err)
+	}
+
+	var errs []error
+	changed := 0
+	for _, id := range ids {
+		p, err := t.alibabaGateway.GetProduct(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("product %s: %w", id, err))
+			continue
+		}
+		recorded, err := t.RecordPrice(ctx, id, p.Price.USD, rate)
+		if recorded {
+			changed++
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("product %s: %w", id, err))
+		}
+	}
+	return changed, errors.Join(errs...)
+}
+
+// RecordPrice stores a snapshot for the product unless the price is unchanged
+// since the latest snapshot, in which case that snapshot is touched instead.
+// It reports whether a new snapshot was written, and publishes PriceChanged
+// when it replaces an earlier price.
+func (t *PriceTracker) RecordPrice(ctx context.Context, productID string, usd, rate float64) (bool, error) {
+	now := time.Now().UTC()
+
+	latest, err := t.history.LatestSnapshot(ctx, productID)
+	if err != nil {
+		return false, err
+	}
+	if latest != nil && samePrice(latest, usd, rate) {
+		return false, t.history.TouchSnapshot(ctx, latest.ID, now)
+	}
+
+	snapshot := &domain.PriceSnapshot{
+		ID:         uuid.New().String(),
+		ProductID:  productID,
+		Price:      convertPrice(usd, rate, now),
+		FXRate:     rate,
+		RecordedAt: now,
+		LastSeenAt: now,
+	}
+	if err := t.history.InsertSnapshot(ctx, snapshot); err != nil {
+		return false, err
+	}
+	if latest != nil && t.events != nil {
+		err = t.events.Publish(ctx, PriceChanged{ProductID: productID, Previous: latest.Price, Current: snapshot.Price, At: now})
+	}
+	return true, err
+}
+
+// History returns the chart-ready price history of a product since the given time.
+func (t *PriceTracker) History(ctx context.Context, productID string, since time.Time) (*domain.PriceHistory, error) {
+	snapshots, err := t.history.ListSnapshots(ctx, productID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &domain.PriceHistory{ProductID: productID, From: since, To: time.Now().UTC(), Points: []domain.PricePoint{}}
+	if len(snapshots) == 0 {
+		return h, nil
+	}
+
+	h.USD = domain.PriceStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	h.ETB = domain.PriceStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, s := range snapshots {
+		at := s.RecordedAt
+		if at.Before(since) {
+			// The snapshot started before the window but was still current in it
+			at = since
+		}
+		h.Points = append(h.Points, domain.PricePoint{Time: at, USD: s.Price.USD, ETB: s.Price.ETB})
+		h.USD.Min, h.USD.Max = math.Min(h.USD.Min, s.Price.USD), math.Max(h.USD.Max, s.Price.USD)
+		h.ETB.Min, h.ETB.Max = math.Min(h.ETB.Min, s.Price.ETB), math.Max(h.ETB.Max, s.Price.ETB)
+	}
+
+	last := snapshots[len(snapshots)-1]
+	h.USD.Current, h.ETB.Current = last.Price.USD, last.Price.ETB
+	if last.LastSeenAt.After(last.RecordedAt) {
+		// Extend the series to the last time the price was confirmed
+		h.Points = append(h.Points, domain.PricePoint{Time: last.LastSeenAt, USD: last.Price.USD, ETB: last.Price.ETB})
+	}
+	return h, nil
+}
+
+// trackedProductIDs returns the de-duplicated, sorted IDs of products
+// referenced by active alerts or viewed within the view window.
+func (t *PriceTracker) trackedProductIDs(ctx context.Context) ([]string, error) {
+	set := map[string]struct{}{}
+
+	if t.alerts != nil {
+		alerts, err := t.alerts.ListActiveAlerts()
+		if err != nil {
+			return nil, fmt.Errorf("list active alerts: %w", err)
+		}
+		for _, a := range alerts {
+			set[a.ProductID] = struct{}{}
+		}
+	}
+
+	if t.views != nil {
+		viewed, err := t.views.RecentlyViewed(ctx, time.Now().Add(-t.viewWindow))
+		if err != nil {
+			return nil, fmt.Errorf("recently viewed: %w", err)
+		}
+		for _, id := range viewed {
+			set[id] = struct{}{}
+		}
+	}
+
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func samePrice(s *domain.PriceSnapshot, usd, rate float64) bool {
+	if math.Abs(s.Price.USD-usd) >= 0.005 {
+		return false
+	}
+	if s.FXRate <= 0 {
+		return false
+	}
+	return math.Abs(rate-s.FXRate)/s.FXRate < fxChangeThreshold
+}
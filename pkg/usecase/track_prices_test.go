@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryPriceHistory struct {
+	mu        sync.Mutex
+	snapshots []*domain.PriceSnapshot
+	queries   int
+}
+
+func (m *memoryPriceHistory) LatestSnapshot(ctx context.Context, productID string) (*domain.PriceSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var latest *domain.PriceSnapshot
+	for _, s := range m.snapshots {
+		if s.ProductID == productID && (latest == nil || !s.RecordedAt.Before(latest.RecordedAt)) {
+			latest = s
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryPriceHistory) InsertSnapshot(ctx context.Context, snapshot *domain.PriceSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots = append(m.snapshots, snapshot)
+	return nil
+}
+
+func (m *memoryPriceHistory) TouchSnapshot(ctx context.Context, id string, seenAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.snapshots {
+		if s.ID == id {
+			s.LastSeenAt = seenAt
+		}
+	}
+	return nil
+}
+
+func (m *memoryPriceHistory) ListSnapshots(ctx context.Context, productID string, since time.Time) ([]*domain.PriceSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*domain.PriceSnapshot
+	for _, s := range m.snapshots {
+		if s.ProductID == productID && !s.LastSeenAt.Before(since) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RecordedAt.Before(out[j].RecordedAt) })
+	return out, nil
+}
+
+func (m *memoryPriceHistory) ListSnapshotsFor(ctx context.Context, productIDs []string, since time.Time) (map[string][]*domain.PriceSnapshot, error) {
+	out := map[string][]*domain.PriceSnapshot{}
+	for _, id := range productIDs {
+		snaps, _ := m.ListSnapshots(ctx, id, since)
+		if len(snaps) > 0 {
+			out[id] = snaps
+		}
+	}
+	m.mu.Lock()
+	m.queries++
+	m.mu.Unlock()
+	return out, nil
+}
+
+type stubViewTracker struct {
+	viewed []string
+}
+
+func (s *stubViewTracker) TrackView(ctx context.Context, productID string) error {
+	s.viewed = append(s.viewed, productID)
+	return nil
+}
+
+func (s *stubViewTracker) RecentlyViewed(ctx context.Context, since time.Time) ([]string, error) {
+	return s.viewed, nil
+}
+
+func TestPriceTracker_RecordSnapshots(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "alerted", Price: domain.Price{USD: 10}},
+		{ID: "viewed", Price: domain.Price{USD: 20}},
+	}}
+	alerts := newMockAlertRepository()
+	_ = alerts.CreateAlert(&domain.Alert{ID: "a1", ProductID: "alerted", IsActive: true})
+	_ = alerts.CreateAlert(&domain.Alert{ID: "a2", ProductID: "inactive", IsActive: false})
+	views := &stubViewTracker{viewed: []string{"viewed", "alerted"}}
+	history := &memoryPriceHistory{}
+	fx := &fixedFX{rate: 100}
+	tracker := NewPriceTracker(ag, fx, history, alerts, views, time.Hour, nil)
+
+	changed, err := tracker.RecordSnapshots(ctx)
+	if err != nil {
+		t.Fatalf("RecordSnapshots failed: %v", err)
+	}
+	if changed != 2 || len(history.snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got changed=%d stored=%d", changed, len(history.snapshots))
+	}
+
+	t.Run("UnchangedPriceIsDeduplicated", func(t *testing.T) {
+		changed, err := tracker.RecordSnapshots(ctx)
+		if err != nil {
+			t.Fatalf("RecordSnapshots failed: %v", err)
+		}
+		if changed != 0 || len(history.snapshots) != 2 {
+			t.Fatalf("expected no new snapshots, got changed=%d stored=%d", changed, len(history.snapshots))
+		}
+	})
+
+	t.Run("PriceChangeWritesSnapshot", func(t *testing.T) {
+		ag.products[0].Price.USD = 8
+		changed, err := tracker.RecordSnapshots(ctx)
+		if err != nil {
+			t.Fatalf("RecordSnapshots failed: %v", err)
+		}
+		if changed != 1 || len(history.snapshots) != 3 {
+			t.Fatalf("expected 1 new snapshot, got changed=%d stored=%d", changed, len(history.snapshots))
+		}
+	})
+
+	t.Run("FXMoveWritesSnapshot", func(t *testing.T) {
+		fx.rate = 110
+		changed, err := tracker.RecordSnapshots(ctx)
+		if err != nil {
+			t.Fatalf("RecordSnapshots failed: %v", err)
+		}
+		if changed != 2 {
+			t.Fatalf("expected both products to change in ETB, got %d", changed)
+		}
+	})
+}
+
+func TestPriceTracker_History(t *testing.T) {
+	now := time.Now().UTC()
+	history := &memoryPriceHistory{snapshots: []*domain.PriceSnapshot{
+		{ID: "old", ProductID: "p", Price: domain.Price{USD: 50, ETB: 5000}, RecordedAt: now.AddDate(0, 0, -60), LastSeenAt: now.AddDate(0, 0, -40)},
+		{ID: "s1", ProductID: "p", Price: domain.Price{USD: 12, ETB: 1200}, RecordedAt: now.AddDate(0, 0, -40), LastSeenAt: now.AddDate(0, 0, -20)},
+		{ID: "s2", ProductID: "p", Price: domain.Price{USD: 10, ETB: 1100}, RecordedAt: now.AddDate(0, 0, -20), LastSeenAt: now.AddDate(0, 0, -10)},
+		{ID: "s3", ProductID: "p", Price: domain.Price{USD: 11, ETB: 1150}, RecordedAt: now.AddDate(0, 0, -10), LastSeenAt: now},
+	}}
+	tracker := NewPriceTracker(nil, nil, history, nil, nil, 0, nil)
+
+	since := now.AddDate(0, 0, -30)
+	h, err := tracker.History(context.Background(), "p", since)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+
+	if h.USD.Min != 10 || h.USD.Max != 12 || h.USD.Current != 11 {
+		t.Errorf("unexpected USD stats: %+v", h.USD)
+	}
+	if h.ETB.Min != 1100 || h.ETB.Max != 1200 || h.ETB.Current != 1150 {
+		t.Errorf("unexpected ETB stats: %+v", h.ETB)
+	}
+	if len(h.Points) != 4 {
+		t.Fatalf("expected 3 snapshot points plus the last-seen point, got %d", len(h.Points))
+	}
+	if !h.Points[0].Time.Equal(since) {
+		t.Errorf("first point should be clamped to the window start, got %v", h.Points[0].Time)
+	}
+}
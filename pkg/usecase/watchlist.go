@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+const (
+	// watchlistFetchConcurrency bounds concurrent product fetches when a
+	// watchlist is listed with current prices.
+	watchlistFetchConcurrency = 8
+	// watchlistSaveAttempts bounds how often a change is re-applied to a
+	// watchlist that another request saved first.
+	watchlistSaveAttempts = 5
+)
+
+// WatchlistManager manages users' watchlists: products bookmarked while
+// comparing, without the commitment of a price alert.
+type WatchlistManager struct {
+	repo           WatchlistRepository
+	alibabaGateway AlibabaGateway
+	fx             IFXClient
+	alerts         *AlertManager
+}
+
+// NewWatchlistManager creates a new WatchlistManager. alerts creates the
+// alerts watchlist items are promoted to.
+func NewWatchlistManager(repo WatchlistRepository, ag AlibabaGateway, fx IFXClient, alerts *AlertManager) *WatchlistManager {
+	return &WatchlistManager{repo: repo, alibabaGateway: ag, fx: fx, alerts: alerts}
+}
+
+// Add saves the product with its current price. A product already on the
+// watchlist keeps its saved price and gets the new note and tags. It
+// reports whether the product was added.
+func (m *WatchlistManager) Add(ctx context.Context, userID string, item domain.WatchlistItem) (*domain.WatchlistItem, bool, error) {
+	productID := strings.TrimSpace(item.ProductID)
+	if productID == "" {
+		return nil, false, fmt.Errorf("%w: productId is required", domain.ErrInvalidInput)
+	}
+	note, err := domain.NormalizeWatchlistNote(item.Note)
+	if err != nil {
+		return nil, false, err
+	}
+	tags, err := domain.NormalizeWatchlistTags(item.Tags)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result, priced *domain.WatchlistItem
+	var added bool
+	err = m.update(ctx, userID, func(w *domain.Watchlist) error {
+		if existing := w.Item(productID); existing != nil {
+			existing.Note, existing.Tags = note, tags
+			result, added = existing, false
+			return nil
+		}
+		// The product is priced once, however often the change is re-applied
+		if priced == nil {
+			item, err := m.priceItem(ctx, productID)
+			if err != nil {
+				return err
+			}
+			priced = item
+		}
+		item := *priced
+		item.Note, item.Tags = note, tags
+		if err := w.Add(item); err != nil {
+			return err
+		}
+		result, added = &item, true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return result, added, nil
+}
+
+// priceItem returns a new item for the product at its current price.
+func (m *WatchlistManager) priceItem(ctx context.Context, productID string) (*domain.WatchlistItem, error) {
+	p, err := m.alibabaGateway.GetProduct(ctx, productID)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return nil, fmt.Errorf("%w: unknown product %q", domain.ErrInvalidInput, productID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("product %s: %w", productID, err)
+	}
+	rate, err := m.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil {
+		return nil, fmt.Errorf("fx rate: %w", err)
+	}
+	now := time.Now().UTC()
+	return &domain.WatchlistItem{
+		ProductID:  productID,
+		Title:      p.Title,
+		ImageURL:   p.ImageURL,
+		SavedPrice: convertPrice(p.Price.USD, rate, now),
+		AddedAt:    now,
+	}, nil
+}
+
+// Remove takes the product off the user's watchlist. An alert the item was
+// promoted to stays.
+func (m *WatchlistManager) Remove(ctx context.Context, userID, productID string) error {
+	return m.update(ctx, userID, func(w *domain.Watchlist) error {
+		return w.Remove(productID)
+	})
+}
+
+// List returns the user's watchlist, newest first, with each product's
+// current price and its change since the product was saved. A non-empty tag
+// lists only items with that tag. Products that cannot be fetched are listed
+// without a current price.
+func (m *WatchlistManager) List(ctx context.Context, userID, tag string) ([]domain.WatchlistEntry, error) {
+	w, err := m.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	entries := []domain.WatchlistEntry{}
+	for i := len(w.Items) - 1; i >= 0; i-- {
+		if tag == "" || w.Items[i].HasTag(tag) {
+			entries = append(entries, domain.WatchlistEntry{WatchlistItem: w.Items[i]})
+		}
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	rate, err := m.fx.GetRate(ctx, "USD", "ETB")
+	if err != nil {
+		return nil, fmt.Errorf("fx rate: %w", err)
+	}
+	now := time.Now().UTC()
+	sem := make(chan struct{}, watchlistFetchConcurrency)
+	var wg sync.WaitGroup
+	for i := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e *domain.WatchlistEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p, err := m.alibabaGateway.GetProduct(ctx, e.ProductID)
+			if err != nil || p == nil {
+				return
+			}
+			current := convertPrice(p.Price.USD, rate, now)
+			change := domain.PriceDeltaBetween(e.SavedPrice, current)
+			e.CurrentPrice, e.Change = &current, &change
+		}(&entries[i])
+	}
+	wg.Wait()
+	return entries, nil
+}
+
+// Promote creates a price alert for a watchlisted product from the alert's
+// condition, currency and lifecycle settings. Without a condition or target
+// price the alert waits for any drop from the current price.
+func (m *WatchlistManager) Promote(ctx context.Context, userID, productID string, alert *domain.Alert) (*domain.Alert, error) {
+	w, err := m.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w.Item(productID) == nil {
+		return nil, domain.ErrWatchlistItemNotFound
+	}
+
+	alert.UserID, alert.ProductID = userID, productID
+	if alert.Condition.Kind == "" && alert.TargetPrice == 0 {
+		alert.Condition = domain.AlertCondition{Kind: domain.AlertAnyDrop}
+	}
+	if err := m.alerts.CreateAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+	err = m.update(ctx, userID, func(w *domain.Watchlist) error {
+		item := w.Item(productID)
+		if item == nil {
+			return domain.ErrWatchlistItemNotFound
+		}
+		item.AlertID = alert.ID
+		return nil
+	})
+	// An item removed meanwhile stays removed; its alert stays as it would
+	// had the removal come after the promotion
+	if err != nil && !errors.Is(err, domain.ErrWatchlistItemNotFound) {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// update applies change to the user's watchlist and saves it. When another
+// request saved the watchlist first, change is re-applied to a fresh copy.
+func (m *WatchlistManager) update(ctx context.Context, userID string, change func(*domain.Watchlist) error) error {
+	for attempt := 1; ; attempt++ {
+		w, err := m.load(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := change(w); err != nil {
+			return err
+		}
+		w.UpdatedAt = time.Now().UTC()
+		err = m.repo.SaveWatchlist(ctx, w)
+		if !errors.Is(err, domain.ErrWatchlistConflict) || attempt == watchlistSaveAttempts {
+			return err
+		}
+	}
+}
+
+// load returns the user's watchlist, or an empty one.
+func (m *WatchlistManager) load(ctx context.Context, userID string) (*domain.Watchlist, error) {
+	w, err := m.repo.GetWatchlist(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		w = &domain.Watchlist{UserID: userID}
+	}
+	return w, nil
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// memoryWatchlists stores copies and checks versions like the Mongo
+// repository. beforeSave, if set, runs once before the next save.
+type memoryWatchlists struct {
+	lists      map[string]*domain.Watchlist
+	beforeSave func()
+}
+
+func copyWatchlist(w *domain.Watchlist) *domain.Watchlist {
+	cp := *w
+	cp.Items = append([]domain.WatchlistItem(nil), w.Items...)
+	return &cp
+}
+
+func (m *memoryWatchlists) GetWatchlist(ctx context.Context, userID string) (*domain.Watchlist, error) {
+	if w := m.lists[userID]; w != nil {
+		return copyWatchlist(w), nil
+	}
+	return nil, nil
+}
+
+func (m *memoryWatchlists) SaveWatchlist(ctx context.Context, w *domain.Watchlist) error {
+	if hook := m.beforeSave; hook != nil {
+		m.beforeSave = nil
+		hook()
+	}
+	if m.lists == nil {
+		m.lists = map[string]*domain.Watchlist{}
+	}
+	var stored int64
+	if cur := m.lists[w.UserID]; cur != nil {
+		stored = cur.Version
+	}
+	if stored != w.Version {
+		return domain.ErrWatchlistConflict
+	}
+	w.Version++
+	m.lists[w.UserID] = copyWatchlist(w)
+	return nil
+}
+
+func TestWatchlistManager_AddListRemove(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Title: "Phone", Price: domain.Price{USD: 10}},
+		{ID: "P2", Title: "Case", Price: domain.Price{USD: 2}},
+	}}
+	fx := &fixedFX{rate: 100}
+	repo := &memoryWatchlists{}
+	m := NewWatchlistManager(repo, ag, fx, nil)
+
+	item, added, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P1", Note: " gift ", Tags: []string{"Phones", "phones", " gifts "}})
+	if err != nil || !added {
+		t.Fatalf("Add failed: %v (added %v)", err, added)
+	}
+	if item.Title != "Phone" || item.SavedPrice.ETB != 1000 || item.Note != "gift" || len(item.Tags) != 2 || item.Tags[0] != "phones" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P2"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// The price moves and re-adding only updates the note and tags
+	ag.products[0].Price.USD = 9
+	item, added, err = m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P1", Note: "for mum", Tags: []string{"phones"}})
+	if err != nil || added || item.Note != "for mum" || item.SavedPrice.ETB != 1000 {
+		t.Fatalf("re-add: %+v added=%v err=%v", item, added, err)
+	}
+
+	entries, err := m.List(ctx, "U1", "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ProductID != "P2" {
+		t.Fatalf("expected the newest item first, got %+v", entries)
+	}
+	phone := entries[1]
+	if phone.CurrentPrice == nil || phone.CurrentPrice.ETB != 900 || phone.Change.ETB != -100 || phone.Change.Percent != -10 {
+		t.Errorf("unexpected price change %+v %+v", phone.CurrentPrice, phone.Change)
+	}
+
+	tagged, err := m.List(ctx, "U1", "Phones")
+	if err != nil || len(tagged) != 1 || tagged[0].ProductID != "P1" {
+		t.Errorf("tag filter: %+v %v", tagged, err)
+	}
+
+	if err := m.Remove(ctx, "U1", "P2"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := m.Remove(ctx, "U1", "P2"); !errors.Is(err, domain.ErrWatchlistItemNotFound) {
+		t.Errorf("expected ErrWatchlistItemNotFound, got %v", err)
+	}
+	if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "nope"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput for an unknown product, got %v", err)
+	}
+}
+
+func TestWatchlistManager_Promote(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Title: "Phone", Price: domain.Price{USD: 10}}}}
+	fx := &fixedFX{rate: 100}
+	repo := &memoryWatchlists{}
+	alerts := newMockAlertRepository()
+	m := NewWatchlistManager(repo, ag, fx, NewAlertManager(alerts, ag, fx, 0, nil))
+
+	if _, err := m.Promote(ctx, "U1", "P1", &domain.Alert{}); !errors.Is(err, domain.ErrWatchlistItemNotFound) {
+		t.Fatalf("expected ErrWatchlistItemNotFound, got %v", err)
+	}
+	if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	alert, err := m.Promote(ctx, "U1", "P1", &domain.Alert{})
+	if err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if alert.UserID != "U1" || alert.Condition.Kind != domain.AlertAnyDrop || alert.Baseline == nil || alert.Baseline.Price.ETB != 1000 {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+	if got := repo.lists["U1"].Item("P1").AlertID; got != alert.ID {
+		t.Errorf("item not linked to the alert: %q", got)
+	}
+	if _, err := m.Promote(ctx, "U1", "P1", &domain.Alert{TargetPrice: 900}); !errors.Is(err, domain.ErrAlertExists) {
+		t.Errorf("expected ErrAlertExists, got %v", err)
+	}
+}
+
+func TestWatchlistManager_ConcurrentChangesAreNotLost(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Title: "Phone", Price: domain.Price{USD: 10}},
+		{ID: "P2", Title: "Case", Price: domain.Price{USD: 2}},
+		{ID: "P3", Title: "Cable", Price: domain.Price{USD: 1}},
+	}}
+	repo := &memoryWatchlists{}
+	m := NewWatchlistManager(repo, ag, &fixedFX{rate: 100}, nil)
+
+	// Another request adds P2 between this add's read and its save
+	repo.beforeSave = func() {
+		if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P2"}); err != nil {
+			t.Errorf("concurrent Add failed: %v", err)
+		}
+	}
+	if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	w := repo.lists["U1"]
+	if len(w.Items) != 2 || w.Item("P1") == nil || w.Item("P2") == nil {
+		t.Fatalf("an add was lost: %+v", w.Items)
+	}
+
+	// A removal racing with another add keeps both changes
+	repo.beforeSave = func() {
+		if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P3"}); err != nil {
+			t.Errorf("concurrent Add failed: %v", err)
+		}
+	}
+	if err := m.Remove(ctx, "U1", "P1"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	w = repo.lists["U1"]
+	if len(w.Items) != 2 || w.Item("P1") != nil || w.Item("P3") == nil {
+		t.Fatalf("unexpected items %+v", w.Items)
+	}
+}
+
+func TestWatchlistManager_PromoteDoesNotRestoreRemovedItems(t *testing.T) {
+	ctx := context.Background()
+	ag := &stubAlibabaGateway{products: []*domain.Product{{ID: "P1", Title: "Phone", Price: domain.Price{USD: 10}}}}
+	fx := &fixedFX{rate: 100}
+	repo := &memoryWatchlists{}
+	m := NewWatchlistManager(repo, ag, fx, NewAlertManager(newMockAlertRepository(), ag, fx, 0, nil))
+	if _, _, err := m.Add(ctx, "U1", domain.WatchlistItem{ProductID: "P1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	repo.beforeSave = func() {
+		if err := m.Remove(ctx, "U1", "P1"); err != nil {
+			t.Errorf("concurrent Remove failed: %v", err)
+		}
+	}
+	alert, err := m.Promote(ctx, "U1", "P1", &domain.Alert{})
+	if err != nil || alert.ID == "" {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if w := repo.lists["U1"]; w.Item("P1") != nil {
+		t.Fatalf("the removed item came back: %+v", w.Items)
+	}
+}
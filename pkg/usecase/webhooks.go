@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// WebhookDeliveryTopic is the job queue topic of webhook deliveries. A job's
+// payload is the delivery ID.
+const WebhookDeliveryTopic = "webhooks.deliver"
+
+const (
+	// defaultWebhookAttempts matches the job queue's default attempt limit.
+	defaultWebhookAttempts = 5
+	// defaultDeliveryListLimit bounds a delivery log page.
+	defaultDeliveryListLimit = 50
+)
+
+// webhookEvents are the events webhooks can subscribe to.
+var webhookEvents = []string{EventAlertTriggered}
+
+// WebhookManager manages API clients' webhooks and their delivery logs.
+type WebhookManager struct {
+	webhooks   WebhookRepository
+	deliveries WebhookDeliveryRepository
+	queue      JobQueue
+}
+
+// NewWebhookManager creates a new WebhookManager. queue re-queues replayed
+// deliveries.
+func NewWebhookManager(webhooks WebhookRepository, deliveries WebhookDeliveryRepository, queue JobQueue) *WebhookManager {
+	return &WebhookManager{webhooks: webhooks, deliveries: deliveries, queue: queue}
+}
+
+// publicWebhookHost rejects hosts that name this machine or a non-public
+// address outright. Names that resolve to one are refused when delivering.
+func publicWebhookHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return domain.IsPublicAddress(ip)
+	}
+	return true
+}
+
+// Create validates the URL and events, generates the signing secret and
+// stores the webhook. Without events the webhook receives every event. The
+// returned webhook is the only place its secret is shown.
+func (m *WebhookManager) Create(ctx context.Context, clientID string, w *domain.Webhook) (*domain.Webhook, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("%w: client is required", domain.ErrInvalidInput)
+	}
+	u, err := url.Parse(strings.TrimSpace(w.URL))
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute https URL", domain.ErrInvalidInput)
+	}
+	if !publicWebhookHost(u.Hostname()) {
+		return nil, fmt.Errorf("%w: url must point at a public host", domain.ErrInvalidInput)
+	}
+	events := w.Events
+	if len(events) == 0 {
+		events = webhookEvents
+	}
+	for _, e := range events {
+		if !containsString(webhookEvents, e) {
+			return nil, fmt.Errorf("%w: unsupported event %q", domain.ErrInvalidInput, e)
+		}
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	created := &domain.Webhook{
+		ID:        uuid.New().String(),
+		ClientID:  clientID,
+		URL:       u.String(),
+		Events:    append([]string(nil), events...),
+		Secret:    "whsec_" + hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := m.webhooks.CreateWebhook(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// List returns the client's webhooks.
+func (m *WebhookManager) List(ctx context.Context, clientID string) ([]*domain.Webhook, error) {
+	return m.webhooks.ListWebhooks(ctx, clientID)
+}
+
+// Delete removes one of the client's webhooks. Its pending deliveries fail.
+func (m *WebhookManager) Delete(ctx context.Context, clientID, id string) error {
+	return m.webhooks.DeleteWebhook(ctx, clientID, id)
+}
+
+// Deliveries returns the client's delivery log, newest first, optionally
+// only deliveries in the status.
+func (m *WebhookManager) Deliveries(ctx context.Context, clientID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidInput, status)
+	}
+	if limit <= 0 || limit > defaultDeliveryListLimit {
+		limit = defaultDeliveryListLimit
+	}
+	return m.deliveries.ListDeliveries(ctx, clientID, status, limit)
+}
+
+// Replay queues a failed delivery again with the same payload and a fresh
+// set of attempts. Its log keeps the earlier attempts.
+func (m *WebhookManager) Replay(ctx context.Context, clientID, id string) (*domain.WebhookDelivery, error) {
+	d, err := m.deliveries.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.ClientID != clientID {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	if d.Status != domain.WebhookDeliveryFailed {
+		return nil, fmt.Errorf("%w: only failed deliveries can be replayed", domain.ErrInvalidInput)
+	}
+	now := time.Now().UTC()
+	d.Status, d.Attempts, d.ReplayedAt = domain.WebhookDeliveryPending, 0, &now
+	if err := m.deliveries.SaveDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	if _, err := m.queue.Enqueue(ctx, WebhookDeliveryTopic, []byte(d.ID)); err != nil {
+		return nil, queueFailed(ctx, m.deliveries, d, err)
+	}
+	return d, nil
+}
+
+// WebhookDispatcher turns events into webhook deliveries and sends them.
+// Each delivery is a job on the queue, which retries failed attempts with
+// exponential backoff.
+type WebhookDispatcher struct {
+	webhooks    WebhookRepository
+	deliveries  WebhookDeliveryRepository
+	sender      WebhookSender
+	queue       JobQueue
+	maxAttempts int
+}
+
+// NewWebhookDispatcher creates a new WebhookDispatcher. maxAttempts is how
+// many attempts a delivery gets before it fails; it should not exceed the
+// queue's own limit. 0 uses a default.
+func NewWebhookDispatcher(webhooks WebhookRepository, deliveries WebhookDeliveryRepository, sender WebhookSender, queue JobQueue, maxAttempts int) *WebhookDispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookAttempts
+	}
+	return &WebhookDispatcher{webhooks: webhooks, deliveries: deliveries, sender: sender, queue: queue, maxAttempts: maxAttempts}
+}
+
+// webhookPayload is the JSON body of a delivery.
+type webhookPayload struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// webhookAlertData describes a triggered alert to its API client.
+type webhookAlertData struct {
+	AlertID      string                `json:"alertId"`
+	UserID       string                `json:"userId"`
+	ProductID    string                `json:"productId"`
+	ProductTitle string                `json:"productTitle,omitempty"`
+	Condition    domain.AlertCondition `json:"condition"`
+	Currency     string                `json:"currency"`
+	Price        domain.Price          `json:"price"`
+	FXRate       float64               `json:"fxRate"`
+	Cause        domain.DropCause      `json:"dropCause,omitempty"`
+	TriggerCount int                   `json:"triggerCount"`
+}
+
+// HandleEvent is an EventHandler that queues a delivery to every webhook of
+// the triggering alert's API client. Deliveries are keyed by webhook and
+// trigger, so an event handled twice is delivered once.
+func (d *WebhookDispatcher) HandleEvent(ctx context.Context, e Event) error {
+	ev, ok := e.(AlertTriggered)
+	if !ok || ev.Trigger == nil || ev.Trigger.Alert == nil || ev.Trigger.Alert.ClientID == "" {
+		return nil
+	}
+	t := ev.Trigger
+	webhooks, err := d.webhooks.ListWebhooks(ctx, t.Alert.ClientID)
+	if err != nil {
+		return fmt.Errorf("list webhooks: %w", err)
+	}
+
+	data := webhookAlertData{
+		AlertID:      t.Alert.ID,
+		UserID:       t.Alert.UserID,
+		ProductID:    t.Alert.ProductID,
+		Condition:    t.Alert.EffectiveCondition(),
+		Currency:     t.Alert.TargetCurrency(),
+		Price:        t.Price,
+		FXRate:       t.FXRate,
+		Cause:        t.Cause,
+		TriggerCount: t.Alert.TriggerCount,
+	}
+	if t.Product != nil {
+		data.ProductTitle = t.Product.Title
+	}
+
+	var errs []error
+	for _, w := range webhooks {
+		if !w.Wants(e.EventName()) {
+			continue
+		}
+		id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(w.ID+"|"+alertIdempotencyKey(t.Alert))).String()
+		body, err := json.Marshal(webhookPayload{ID: id, Event: e.EventName(), OccurredAt: e.OccurredAt(), Data: data})
+		if err != nil {
+			return err
+		}
+		delivery := &domain.WebhookDelivery{
+			ID:        id,
+			WebhookID: w.ID,
+			ClientID:  w.ClientID,
+			Event:     e.EventName(),
+			Payload:   string(body),
+			Status:    domain.WebhookDeliveryPending,
+			CreatedAt: time.Now().UTC(),
+		}
+		created, err := d.deliveries.CreateDelivery(ctx, delivery)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.ID, err))
+			continue
+		}
+		if !created {
+			continue
+		}
+		if _, err := d.queue.Enqueue(ctx, WebhookDeliveryTopic, []byte(id)); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.ID, queueFailed(ctx, d.deliveries, delivery, err)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deliver makes one attempt at a delivery and is the handler of
+// WebhookDeliveryTopic jobs. It returns an error while attempts remain, so
+// the queue retries the job; the last failed attempt marks the delivery
+// failed, from where it can be replayed.
+func (d *WebhookDispatcher) Deliver(ctx context.Context, id string) error {
+	delivery, err := d.deliveries.GetDelivery(ctx, id)
+	if errors.Is(err, domain.ErrWebhookDeliveryNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != domain.WebhookDeliveryPending {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	w, err := d.webhooks.GetWebhook(ctx, delivery.WebhookID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.Log = append(delivery.Log, domain.WebhookAttempt{At: now, Error: "webhook deleted"})
+		return d.deliveries.SaveDelivery(ctx, delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	body := []byte(delivery.Payload)
+	headers := map[string]string{
+		"Content-Type":                "application/json",
+		domain.WebhookEventHeader:     delivery.Event,
+		domain.WebhookDeliveryHeader:  delivery.ID,
+		domain.WebhookTimestampHeader: strconv.FormatInt(now.Unix(), 10),
+		domain.WebhookSignatureHeader: domain.SignWebhook(w.Secret, now, body),
+	}
+	status, sendErr := d.sender.Post(ctx, w.URL, headers, body)
+	if sendErr == nil && (status < 200 || status > 299) {
+		sendErr = fmt.Errorf("status %d", status)
+	}
+	attempt := domain.WebhookAttempt{At: now, StatusCode: status, DurationMS: time.Since(now).Milliseconds()}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	delivery.Attempts++
+	delivery.Log = append(delivery.Log, attempt)
+	switch {
+	case sendErr == nil:
+		delivery.Status, delivery.DeliveredAt = domain.WebhookDeliverySucceeded, &now
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+	}
+	if err := d.deliveries.SaveDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		return fmt.Errorf("webhook delivery %s: attempt %d: %w", delivery.ID, delivery.Attempts, sendErr)
+	}
+	return nil
+}
+
+// queueFailed marks a delivery that could not be queued as failed, so it
+// shows up for replay instead of staying pending forever.
+func queueFailed(ctx context.Context, deliveries WebhookDeliveryRepository, d *domain.WebhookDelivery, cause error) error {
+	d.Status = domain.WebhookDeliveryFailed
+	d.Log = append(d.Log, domain.WebhookAttempt{At: time.Now().UTC(), Error: "queue: " + cause.Error()})
+	if err := deliveries.SaveDelivery(ctx, d); err != nil {
+		return errors.Join(cause, err)
+	}
+	return fmt.Errorf("queue delivery %s: %w", d.ID, cause)
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+type memoryWebhooks struct {
+	webhooks   map[string]*domain.Webhook
+	deliveries map[string]*domain.WebhookDelivery
+}
+
+func newMemoryWebhooks() *memoryWebhooks {
+	return &memoryWebhooks{webhooks: map[string]*domain.Webhook{}, deliveries: map[string]*domain.WebhookDelivery{}}
+}
+
+func (m *memoryWebhooks) CreateWebhook(ctx context.Context, w *domain.Webhook) error {
+	m.webhooks[w.ID] = w
+	return nil
+}
+
+func (m *memoryWebhooks) ListWebhooks(ctx context.Context, clientID string) ([]*domain.Webhook, error) {
+	var out []*domain.Webhook
+	for _, w := range m.webhooks {
+		if w.ClientID == clientID {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryWebhooks) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	if w, ok := m.webhooks[id]; ok {
+		return w, nil
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (m *memoryWebhooks) DeleteWebhook(ctx context.Context, clientID, id string) error {
+	if w, ok := m.webhooks[id]; !ok || w.ClientID != clientID {
+		return domain.ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+	return nil
+}
+
+func (m *memoryWebhooks) CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) (bool, error) {
+	if _, ok := m.deliveries[d.ID]; ok {
+		return false, nil
+	}
+	m.deliveries[d.ID] = d
+	return true, nil
+}
+
+func (m *memoryWebhooks) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	if d, ok := m.deliveries[id]; ok {
+		return d, nil
+	}
+	return nil, domain.ErrWebhookDeliveryNotFound
+}
+
+func (m *memoryWebhooks) SaveDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	m.deliveries[d.ID] = d
+	return nil
+}
+
+func (m *memoryWebhooks) ListDeliveries(ctx context.Context, clientID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	var out []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.ClientID == clientID && (status == "" || d.Status == status) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+type recordingWebhookSender struct {
+	status  int
+	err     error
+	headers []map[string]string
+	bodies  [][]byte
+}
+
+func (s *recordingWebhookSender) Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	s.headers = append(s.headers, headers)
+	s.bodies = append(s.bodies, body)
+	return s.status, s.err
+}
+
+func TestWebhookManager_Create(t *testing.T) {
+	repo := newMemoryWebhooks()
+	m := NewWebhookManager(repo, repo, &memoryJobQueue{})
+	ctx := context.Background()
+
+	w, err := m.Create(ctx, "C1", &domain.Webhook{URL: " https://partner.example/hooks "})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if w.URL != "https://partner.example/hooks" || len(w.Events) != 1 || w.Events[0] != EventAlertTriggered || len(w.Secret) < 32 {
+		t.Errorf("unexpected webhook %+v", w)
+	}
+
+	for _, bad := range []*domain.Webhook{
+		{URL: "http://partner.example/hooks"},
+		{URL: "partner.example"},
+		{URL: "https://127.0.0.1/hooks"},
+		{URL: "https://169.254.169.254/latest/meta-data"},
+		{URL: "https://10.0.0.8:8443/hooks"},
+		{URL: "https://[::1]/hooks"},
+		{URL: "https://localhost/hooks"},
+		{URL: "https://partner.example", Events: []string{"price.changed"}},
+	} {
+		if _, err := m.Create(ctx, "C1", bad); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", bad, err)
+		}
+	}
+	if _, err := m.Create(ctx, "", &domain.Webhook{URL: "https://partner.example"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput without a client, got %v", err)
+	}
+}
+
+func TestWebhookDispatcher_DeliversSignedRetriesAndReplays(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryWebhooks()
+	repo.webhooks["W1"] = &domain.Webhook{ID: "W1", ClientID: "C1", URL: "https://partner.example/hooks", Events: []string{EventAlertTriggered}, Secret: "s3cret"}
+	repo.webhooks["W2"] = &domain.Webhook{ID: "W2", ClientID: "C2", URL: "https://other.example/hooks", Events: []string{EventAlertTriggered}, Secret: "other"}
+	queue := &memoryJobQueue{}
+	sender := &recordingWebhookSender{status: 503}
+	d := NewWebhookDispatcher(repo, repo, sender, queue, 2)
+
+	trigger := &domain.AlertTrigger{
+		Alert:       &domain.Alert{ID: "A1", UserID: "U1", ProductID: "P1", ClientID: "C1", TargetPrice: 1000, TriggerCount: 1},
+		Product:     &domain.Product{ID: "P1", Title: "Phone"},
+		Price:       domain.Price{USD: 6, ETB: 900},
+		FXRate:      150,
+		TriggeredAt: time.Now().UTC(),
+	}
+	for i := 0; i < 2; i++ {
+		if err := d.HandleEvent(ctx, AlertTriggered{Trigger: trigger}); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+	if err := d.HandleEvent(ctx, AlertTriggered{Trigger: &domain.AlertTrigger{Alert: &domain.Alert{ID: "A2"}}}); err != nil {
+		t.Fatalf("HandleEvent without a client failed: %v", err)
+	}
+	if len(queue.jobs) != 1 || queue.topics[0] != WebhookDeliveryTopic {
+		t.Fatalf("expected one queued delivery, got %v", queue.topics)
+	}
+	id := string(queue.jobs[0])
+
+	if err := d.Deliver(ctx, id); err == nil {
+		t.Fatal("expected a failed attempt to return an error so the queue retries")
+	}
+	h := sender.headers[0]
+	ts, _ := strconv.ParseInt(h[domain.WebhookTimestampHeader], 10, 64)
+	if !domain.VerifyWebhook("s3cret", h[domain.WebhookSignatureHeader], time.Unix(ts, 0), time.Now(), time.Minute, sender.bodies[0]) {
+		t.Errorf("signature does not verify: %v", h)
+	}
+	if h[domain.WebhookEventHeader] != EventAlertTriggered || h[domain.WebhookDeliveryHeader] != id {
+		t.Errorf("unexpected headers %v", h)
+	}
+	var body struct {
+		ID   string           `json:"id"`
+		Data webhookAlertData `json:"data"`
+	}
+	if err := json.Unmarshal(sender.bodies[0], &body); err != nil || body.ID != id || body.Data.AlertID != "A1" || body.Data.ProductTitle != "Phone" || body.Data.Currency != "ETB" {
+		t.Errorf("unexpected body %s (%v)", sender.bodies[0], err)
+	}
+
+	// The last attempt fails the delivery without asking for another retry
+	if err := d.Deliver(ctx, id); err != nil {
+		t.Fatalf("last attempt: %v", err)
+	}
+	delivery := repo.deliveries[id]
+	if delivery.Status != domain.WebhookDeliveryFailed || len(delivery.Log) != 2 || delivery.Log[1].StatusCode != 503 {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+
+	m := NewWebhookManager(repo, repo, queue)
+	if _, err := m.Replay(ctx, "C2", id); !errors.Is(err, domain.ErrWebhookDeliveryNotFound) {
+		t.Errorf("another client replayed the delivery: %v", err)
+	}
+	if _, err := m.Replay(ctx, "C1", id); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(queue.jobs) != 2 || delivery.Status != domain.WebhookDeliveryPending || delivery.Attempts != 0 {
+		t.Fatalf("replay did not re-queue the delivery: %+v", delivery)
+	}
+
+	sender.status = 204
+	if err := d.Deliver(ctx, id); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+	if delivery.Status != domain.WebhookDeliverySucceeded || len(delivery.Log) != 3 || delivery.DeliveredAt == nil {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+	if string(sender.bodies[2]) != string(sender.bodies[0]) {
+		t.Error("replay changed the payload")
+	}
+	if _, err := m.Replay(ctx, "C1", id); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("expected a delivered delivery not to be replayable, got %v", err)
+	}
+}
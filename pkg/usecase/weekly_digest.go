@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+// digestPeriod is the time span a weekly digest covers.
+const digestPeriod = 7 * 24 * time.Hour
+
+// WeeklyDigest builds a summary per user of how their watched products and
+// the USD/ETB rate moved over the past week, and queues it as a digest
+// notification. The dispatcher holds digests until each user's digest time.
+type WeeklyDigest struct {
+	alerts    AlertRepository
+	history   PriceHistoryRepository
+	fxHistory FXHistoryRepository
+	products  AlibabaGateway
+	notifier  *NotificationService
+}
+
+// NewWeeklyDigest creates a new WeeklyDigest. products is used for titles and
+// links and may be nil.
+func NewWeeklyDigest(alerts AlertRepository, history PriceHistoryRepository, fxHistory FXHistoryRepository, products AlibabaGateway, notifier *NotificationService) *WeeklyDigest {
+	return &WeeklyDigest{alerts: alerts, history: history, fxHistory: fxHistory, products: products, notifier: notifier}
+}
+
+// productMove is a product's price change over the digest period.
+type productMove struct {
+	item  domain.NotificationItem
+	start float64
+	found bool
+}
+
+// Send queues the digest of the week ending at now for every user with an
+// active alert and returns how many were queued. Digests are keyed by ISO
+// week, so running it again in the same week queues nothing new.
+func (d *WeeklyDigest) Send(ctx context.Context, now time.Time) (int, error) {
+	active, err := d.alerts.ListActiveAlerts()
+	if err != nil {
+		return 0, fmt.Errorf("list alerts: %w", err)
+	}
+	since := now.Add(-digestPeriod)
+
+	watched := map[string][]string{}
+	moves := map[string]*productMove{}
+	var errs []error
+	for _, a := range active {
+		if !containsString(watched[a.UserID], a.ProductID) {
+			watched[a.UserID] = append(watched[a.UserID], a.ProductID)
+		}
+		if _, ok := moves[a.ProductID]; ok {
+			continue
+		}
+		m, err := d.move(ctx, a.ProductID, since)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("product %s: %w", a.ProductID, err))
+		}
+		moves[a.ProductID] = m
+	}
+	fx := d.fxSummary(ctx, since)
+
+	year, week := now.ISOWeek()
+	queued := 0
+	for userID, productIDs := range watched {
+		var items []domain.NotificationItem
+		for _, id := range productIDs {
+			if m := moves[id]; m != nil && m.found {
+				items = append(items, m.item)
+			}
+		}
+		if len(items) == 0 && fx == nil {
+			continue
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].Title < items[j].Title })
+
+		n := &domain.Notification{
+			IdempotencyKey: fmt.Sprintf("digest:%s:%d-W%02d", userID, year, week),
+			UserID:         userID,
+			Kind:           domain.NotificationDigest,
+			Title:          "Your weekly price digest",
+			Body:           digestBody(items, fx),
+			Data:           map[string]string{"periodStart": since.UTC().Format(time.DateOnly), "periodEnd": now.UTC().Format(time.DateOnly)},
+			Items:          items,
+		}
+		if fx != nil {
+			n.Data["fxStart"] = fmt.Sprintf("%.2f", fx.start)
+			n.Data["fxEnd"] = fmt.Sprintf("%.2f", fx.end)
+			n.Data["fxChangePct"] = fmt.Sprintf("%+.1f", fx.changePct())
+		}
+		created, err := d.notifier.Notify(ctx, n)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", userID, err))
+			continue
+		}
+		if created {
+			queued++
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// move compares the ETB price current at since with the latest one.
+func (d *WeeklyDigest) move(ctx context.Context, productID string, since time.Time) (*productMove, error) {
+	snaps, err := d.history.ListSnapshots(ctx, productID, since)
+	if err != nil {
+		return nil, err
+	}
+	m := &productMove{item: domain.NotificationItem{ProductID: productID, Title: productID}}
+	if len(snaps) == 0 {
+		return m, nil
+	}
+	first, last := snaps[0], snaps[len(snaps)-1]
+	m.start, m.found = snapshotETB(first), true
+	m.item.PriceETB = snapshotETB(last)
+	if m.start != m.item.PriceETB {
+		m.item.PreviousETB = m.start
+	}
+
+	if d.products != nil {
+		// Titles are cosmetic: a missing product still gets its price line
+		if p, err := d.products.GetProduct(ctx, productID); err == nil && p != nil {
+			m.item.Title, m.item.ImageURL = p.Title, p.ImageURL
+			if isWebURL(p.DeeplinkURL) {
+				m.item.URL = p.DeeplinkURL
+			}
+		}
+	}
+	return m, nil
+}
+
+func snapshotETB(s *domain.PriceSnapshot) float64 {
+	if s.Price.ETB > 0 {
+		return s.Price.ETB
+	}
+	return s.Price.USD * s.FXRate
+}
+
+type fxMove struct {
+	start, end float64
+}
+
+func (f *fxMove) changePct() float64 {
+	return (f.end - f.start) / f.start * 100
+}
+
+// fxSummary returns the USD/ETB move over the period, or nil without data.
+func (d *WeeklyDigest) fxSummary(ctx context.Context, since time.Time) *fxMove {
+	if d.fxHistory == nil {
+		return nil
+	}
+	rates, err := d.fxHistory.ListRates(ctx, "USD", "ETB", since)
+	if err != nil || len(rates) == 0 || rates[0].Rate <= 0 {
+		return nil
+	}
+	return &fxMove{start: rates[0].Rate, end: rates[len(rates)-1].Rate}
+}
+
+// digestBody is the short text used by channels without templates.
+func digestBody(items []domain.NotificationItem, fx *fxMove) string {
+	cheaper, pricier := 0, 0
+	for _, it := range items {
+		switch {
+		case it.PreviousETB == 0:
+		case it.PriceETB < it.PreviousETB:
+			cheaper++
+		case it.PriceETB > it.PreviousETB:
+			pricier++
+		}
+	}
+	var parts []string
+	if len(items) > 0 {
+		parts = append(parts, fmt.Sprintf("%d watched products: %d cheaper, %d pricier, %d unchanged.", len(items), cheaper, pricier, len(items)-cheaper-pricier))
+	}
+	if fx != nil {
+		parts = append(parts, fmt.Sprintf("USD/ETB %.2f -> %.2f (%+.1f%%).", fx.start, fx.end, fx.changePct()))
+	}
+	return strings.Join(parts, " ")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopally-ai/pkg/domain"
+)
+
+func TestWeeklyDigest_Send(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return now.AddDate(0, 0, d) }
+
+	alerts := newMockAlertRepository()
+	for _, a := range []*domain.Alert{
+		{ID: "a1", UserID: "U1", ProductID: "P1", TargetPrice: 1500, IsActive: true},
+		{ID: "a2", UserID: "U1", ProductID: "P1", TargetPrice: 1000, IsActive: true},
+		{ID: "a3", UserID: "U1", ProductID: "P2", TargetPrice: 100, IsActive: true},
+		{ID: "a4", UserID: "U2", ProductID: "P3", TargetPrice: 100, IsActive: true},
+		{ID: "a5", UserID: "U3", ProductID: "P1", TargetPrice: 100, IsActive: false},
+	} {
+		_ = alerts.CreateAlert(a)
+	}
+	history := &memoryPriceHistory{snapshots: []*domain.PriceSnapshot{
+		{ID: "s1", ProductID: "P1", Price: domain.Price{ETB: 2000}, RecordedAt: day(-20), LastSeenAt: day(-5)},
+		{ID: "s2", ProductID: "P1", Price: domain.Price{ETB: 1800}, RecordedAt: day(-5), LastSeenAt: day(0)},
+		{ID: "s3", ProductID: "P2", Price: domain.Price{USD: 4, ETB: 0}, FXRate: 125, RecordedAt: day(-30), LastSeenAt: day(0)},
+		{ID: "old", ProductID: "P3", Price: domain.Price{ETB: 10}, RecordedAt: day(-30), LastSeenAt: day(-8)},
+	}}
+	fx := &memoryFXHistory{rates: []*domain.FXRateSnapshot{
+		{From: "USD", To: "ETB", Rate: 100, RecordedAt: day(-10)},
+		{From: "USD", To: "ETB", Rate: 120, RecordedAt: day(-6)},
+		{From: "USD", To: "ETB", Rate: 126, RecordedAt: day(-1)},
+	}}
+	ag := &stubAlibabaGateway{products: []*domain.Product{
+		{ID: "P1", Title: "Earbuds", DeeplinkURL: "https://s.click/p1"},
+		{ID: "P2", Title: "Cable", DeeplinkURL: "#"},
+	}}
+	outbox := newMemoryOutbox()
+	digest := NewWeeklyDigest(alerts, history, fx, ag, NewNotificationService(outbox, domain.ChannelEmail))
+
+	queued, err := digest.Send(ctx, now)
+	if err != nil || queued != 2 {
+		t.Fatalf("queued=%d err=%v", queued, err)
+	}
+
+	byUser := map[string]*domain.Notification{}
+	for _, n := range outbox.items {
+		byUser[n.UserID] = n
+	}
+	u1 := byUser["U1"]
+	if u1 == nil || u1.Kind != domain.NotificationDigest || u1.IdempotencyKey != "digest:U1:2026-W11" {
+		t.Fatalf("U1 digest: %+v", u1)
+	}
+	want := []domain.NotificationItem{
+		{ProductID: "P2", Title: "Cable", PriceETB: 500},
+		{ProductID: "P1", Title: "Earbuds", URL: "https://s.click/p1", PriceETB: 1800, PreviousETB: 2000},
+	}
+	if len(u1.Items) != 2 || u1.Items[0] != want[0] || u1.Items[1] != want[1] {
+		t.Fatalf("U1 items: %+v", u1.Items)
+	}
+	if u1.Data["fxStart"] != "120.00" || u1.Data["fxEnd"] != "126.00" || u1.Data["fxChangePct"] != "+5.0" {
+		t.Fatalf("U1 fx: %v", u1.Data)
+	}
+	if !strings.Contains(u1.Body, "2 watched products: 1 cheaper, 0 pricier, 1 unchanged.") {
+		t.Fatalf("U1 body: %q", u1.Body)
+	}
+	// Without price data for the week a user still gets the FX summary
+	if u2 := byUser["U2"]; u2 == nil || len(u2.Items) != 0 || u2.Data["fxEnd"] != "126.00" {
+		t.Fatalf("U2 digest: %+v", u2)
+	}
+	if _, ok := byUser["U3"]; ok {
+		t.Fatal("inactive alerts must not produce a digest")
+	}
+
+	// Running again in the same week queues nothing
+	if queued, _ := digest.Send(ctx, now.Add(48*time.Hour)); queued != 0 {
+		t.Fatalf("second run queued %d", queued)
+	}
+}
+
+func TestNotificationDispatcher_DigestWaitsForDigestTime(t *testing.T) {
+	ctx := context.Background()
+	p := domain.DefaultPreferences("U1")
+	p.TimeZone, p.QuietHours.Enabled, p.MaxPerDay = "UTC", false, 1
+	p.DigestTime = clockAround(3 * time.Hour)
+	prefs := &memoryPreferences{}
+	_ = prefs.SavePreferences(ctx, p)
+
+	outbox := newMemoryOutbox()
+	svc := NewNotificationService(outbox, domain.ChannelPush)
+	_, _ = svc.Notify(ctx, &domain.Notification{IdempotencyKey: "digest", UserID: "U1", Kind: domain.NotificationDigest})
+	push := &stubChannel{kind: domain.ChannelPush}
+	d := NewNotificationDispatcher(outbox, prefs, []NotificationChannel{push}, 3, time.Minute)
+
+	// Held until the digest time even though the user is in instant mode
+	if _, _ = d.DispatchDue(ctx); push.calls != 0 {
+		t.Fatal("digest sent before the digest time")
+	}
+	if del := deliveryOf(outbox.only(t), domain.ChannelPush); time.Until(del.NextAttemptAt) < 2*time.Hour {
+		t.Fatalf("not deferred to digest time: %+v", del)
+	}
+
+	// In the digest window it goes out even when the daily cap is used up
+	p.DigestTime = clockAround(-10 * time.Minute)
+	_ = prefs.SavePreferences(ctx, p)
+	sentAt := time.Now().UTC()
+	outbox.items["earlier"] = &domain.Notification{ID: "earlier", UserID: "U1", Status: domain.NotificationDone,
+		Deliveries: []domain.ChannelDelivery{{Channel: domain.ChannelPush, State: domain.DeliverySent, SentAt: &sentAt}}}
+	outbox.forceDue()
+	if _, _ = d.DispatchDue(ctx); push.calls != 1 {
+		t.Fatalf("digest not sent in its window: calls=%d", push.calls)
+	}
+}